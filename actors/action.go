@@ -16,6 +16,18 @@ const (
 	MoveAndShoot
 )
 
+var actionTypeNames = map[ActionType]string{
+	Dodge:        "dodge",
+	FindCover:    "find cover",
+	Shoot:        "shoot",
+	Move:         "move",
+	MoveAndShoot: "move and shoot",
+}
+
+func (t ActionType) String() string {
+	return actionTypeNames[t]
+}
+
 type Action struct {
 	Duration int
 	MoveDir  Direction
@@ -47,9 +59,7 @@ func (a Action) PerformAction(player *Player, frameCount int) {
 						moveDir = Right
 					}
 					a.Actor.Move(moveDir)
-					if frameCount%a.Actor.AnimationSpeed == 0 {
-						a.Actor.Animate()
-					}
+					a.Actor.Animate()
 					actionPerformed = true
 					break
 				}
@@ -57,7 +67,6 @@ func (a Action) PerformAction(player *Player, frameCount int) {
 			a.Actor.Speed -= a.Actor.DodgeSpeed
 		} else {
 			oldSpeed := a.Actor.Speed
-			fasterAnimationSpeed := a.Actor.AnimationSpeed * 2 / 3
 			a.Actor.Speed = a.Actor.DodgeSpeed
 			for dir, moving := range a.Actor.MoveDirs {
 				if !moving {
@@ -67,18 +76,14 @@ func (a Action) PerformAction(player *Player, frameCount int) {
 				a.Actor.Move(dir)
 			}
 			a.Actor.UpdateHitbox()
-			if frameCount%fasterAnimationSpeed == 0 {
-				a.Actor.Animate()
-			}
+			a.Actor.Animate()
 			actionPerformed = true
 			a.Actor.Speed = oldSpeed
 		}
 	case Move:
 		a.Actor.Look(a.LookDir)
 		a.Actor.Move(a.MoveDir)
-		if frameCount%a.Actor.AnimationSpeed == 0 {
-			a.Actor.Animate()
-		}
+		a.Actor.Animate()
 		actionPerformed = true
 	case MoveAndShoot:
 		angle := utils.AngleBetweenPoints(player.X, player.Y, a.Actor.X, a.Actor.Y)
@@ -135,9 +140,7 @@ func (a Action) PerformAction(player *Player, frameCount int) {
 				}
 			}
 		}
-		if frameCount%a.Actor.AnimationSpeed == 0 {
-			a.Actor.Animate()
-		}
+		a.Actor.Animate()
 
 		if frameCount%a.Actor.FireRate == 0 {
 			// the addition and substraction here
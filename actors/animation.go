@@ -0,0 +1,279 @@
+package actors
+
+import (
+	"fmt"
+)
+
+type AnimationMode int
+
+const (
+	// Loop starts over after the last frame
+	Loop AnimationMode = iota
+	// Once stops on the last frame
+	Once
+	// PingPong plays the frames forwards and then backwards
+	PingPong
+)
+
+var animationModeNames = map[AnimationMode]string{
+	Loop:     "loop",
+	Once:     "once",
+	PingPong: "ping-pong",
+}
+
+func (m AnimationMode) String() string {
+	return animationModeNames[m]
+}
+
+func (m *AnimationMode) UnmarshalText(data []byte) error {
+	for mode, name := range animationModeNames {
+		if name == string(data) {
+			*m = mode
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown animation mode %q", data)
+}
+
+// Animation events fired by the player animations.
+const (
+	FootstepEvent = "footstep"
+)
+
+type Frame struct {
+	// Sprite is the index in the sprites of the actor
+	Sprite int
+	// Duration in game frames
+	Duration int
+	// Event is fired every time the frame is shown, empty for no event
+	Event string
+}
+
+type Animation struct {
+	Name   string
+	Frames []Frame
+	Mode   AnimationMode
+}
+
+// NewAnimation creates an animation where every frame lasts equally long.
+func NewAnimation(name string, mode AnimationMode, duration int, sprites ...int) *Animation {
+	animation := &Animation{
+		Name: name,
+		Mode: mode,
+	}
+	for _, sprite := range sprites {
+		animation.Frames = append(animation.Frames, Frame{
+			Sprite:   sprite,
+			Duration: duration,
+		})
+	}
+
+	return animation
+}
+
+// Animator is the animation state machine of a single actor,
+// the animations themselves can be shared between actors.
+type Animator struct {
+	Animations map[string]*Animation
+	Current    *Animation
+	// Speed multiplies how fast the frames advance, 1 is normal speed
+	Speed    float64
+	Finished bool
+
+	frame     int
+	elapsed   float64
+	backwards bool
+	events    []string
+}
+
+func NewAnimator(animations map[string]*Animation) *Animator {
+	return &Animator{
+		Animations: animations,
+		Speed:      1,
+	}
+}
+
+// Play switches to the named animation and starts it from the first frame,
+// playing the animation that is already running does nothing.
+func (a *Animator) Play(name string) bool {
+	animation, ok := a.Animations[name]
+	if !ok || len(animation.Frames) == 0 {
+		return false
+	}
+	if animation == a.Current {
+		return true
+	}
+	a.Current = animation
+	a.frame = 0
+	a.elapsed = 0
+	a.backwards = false
+	a.Finished = false
+	a.enterFrame()
+
+	return true
+}
+
+// Continue switches to the named animation but keeps the current frame and timing,
+// so turning around mid stride does not restart the stride.
+func (a *Animator) Continue(name string) bool {
+	animation, ok := a.Animations[name]
+	if !ok || a.Current == nil || len(animation.Frames) != len(a.Current.Frames) {
+		return a.Play(name)
+	}
+	if animation != a.Current {
+		a.Current = animation
+		a.enterFrame()
+	}
+
+	return true
+}
+
+func (a *Animator) enterFrame() {
+	if event := a.Current.Frames[a.frame].Event; event != "" {
+		a.events = append(a.events, event)
+	}
+}
+
+// Sprite is the sprite index of the frame that is shown right now.
+func (a *Animator) Sprite() int {
+	if a.Current == nil {
+		return 0
+	}
+
+	return a.Current.Frames[a.frame].Sprite
+}
+
+func (a *Animator) Update() {
+	if a.Current == nil || a.Finished {
+		return
+	}
+	a.elapsed += a.Speed
+	for !a.Finished && a.elapsed >= float64(a.Current.Frames[a.frame].Duration) {
+		a.elapsed -= float64(a.Current.Frames[a.frame].Duration)
+		a.advance()
+	}
+}
+
+func (a *Animator) advance() {
+	last := len(a.Current.Frames) - 1
+	switch a.Current.Mode {
+	case Loop:
+		a.frame = (a.frame + 1) % (last + 1)
+	case Once:
+		if a.frame == last {
+			a.Finished = true
+			return
+		}
+		a.frame += 1
+	case PingPong:
+		if last == 0 {
+			return
+		}
+		if a.frame == last {
+			a.backwards = true
+		}
+		if a.frame == 0 {
+			a.backwards = false
+		}
+		if a.backwards {
+			a.frame -= 1
+		} else {
+			a.frame += 1
+		}
+	}
+	a.enterFrame()
+}
+
+// Events returns the events of the frames shown since the last call,
+// the returned slice is only valid until the next update.
+func (a *Animator) Events() []string {
+	events := a.events
+	a.events = a.events[:0]
+
+	return events
+}
+
+var directionNames = map[Direction]string{
+	Right:     "right",
+	RightUp:   "right-up",
+	RightDown: "right-down",
+	Left:      "left",
+	LeftUp:    "left-up",
+	LeftDown:  "left-down",
+	Up:        "up",
+	Down:      "down",
+}
+
+func (d Direction) String() string {
+	return directionNames[d]
+}
+
+// HumanoidAnimations lists the animations every player, companion and enemy sprite sheet needs,
+// one idle and one run animation for each weapon and direction the actor can face.
+func HumanoidAnimations() []string {
+	names := []string{"dead"}
+	for _, dir := range []Direction{Right, Left} {
+		names = append(names, "no-gun-"+dir.String()+"-idle", "no-gun-"+dir.String()+"-run", "captured-"+dir.String())
+	}
+	for _, dir := range []Direction{Right, RightUp, RightDown, Left, LeftUp, LeftDown} {
+		names = append(names, "revolver-"+dir.String()+"-idle", "revolver-"+dir.String()+"-run")
+	}
+
+	return names
+}
+
+// animationName picks the animation for the current weapon, direction and movement.
+func (p *Player) animationName() string {
+	if p.Dead {
+		return "dead"
+	}
+	facingLeft := p.VisualDir == Left || p.VisualDir == LeftUp || p.VisualDir == LeftDown
+	if p.Bound {
+		if facingLeft {
+			return "captured-left"
+		}
+		return "captured-right"
+	}
+	name := "no-gun-right"
+	switch p.CurrentWeapon {
+	case Revolver:
+		name = "revolver-" + p.VisualDir.String()
+	case Fists, Lasso:
+		// the lasso is coiled up in the free hand
+		if facingLeft {
+			name = "no-gun-left"
+		}
+	}
+	if p.Running {
+		return name + "-run"
+	}
+
+	return name + "-idle"
+}
+
+// playAnimation moves the animation state machine to the state of the player.
+func (p *Player) playAnimation() {
+	if p.Animator == nil {
+		return
+	}
+	if p.Animator.Continue(p.animationName()) {
+		p.CurrentState = PlayerState(p.Animator.Sprite())
+	}
+}
+
+// UpdateAnimation advances the animation by one game frame,
+// it is called once per frame for every actor.
+func (p *Player) UpdateAnimation() {
+	if p.Animator == nil {
+		return
+	}
+	p.playAnimation()
+	p.Animator.Speed = 1
+	// the player runs faster while dodging, npcs dodge at their normal pace
+	if !p.IsNpc && p.CurrentAction.Duration > 0 && p.CurrentAction.Type == Dodge {
+		p.Animator.Speed = 1.5
+	}
+	p.Animator.Update()
+	p.CurrentState = PlayerState(p.Animator.Sprite())
+}
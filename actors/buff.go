@@ -0,0 +1,136 @@
+package actors
+
+import (
+	"math"
+
+	"github.com/bramca/Far-West/items"
+)
+
+type BuffKind int
+
+const (
+	SpeedBuff BuffKind = iota
+	RegenBuff
+)
+
+var buffNames = map[string]BuffKind{
+	"speed": SpeedBuff,
+	"regen": RegenBuff,
+}
+
+type Buff struct {
+	Kind     BuffKind
+	Amount   float64
+	Duration int
+}
+
+func (p *Player) AddBuff(b Buff) {
+	p.Buffs = append(p.Buffs, b)
+	p.UpdateSpeed()
+}
+
+func (p *Player) removeBuff(index int) {
+	p.Buffs = append(p.Buffs[:index], p.Buffs[index+1:]...)
+	p.UpdateSpeed()
+}
+
+// UpdateSpeed derives the speed from the base speed, the speed buffs and the horse under the player.
+// A horse that is not exhausted lends its own speed when it is faster than the rider.
+func (p *Player) UpdateSpeed() {
+	speed := p.BaseSpeed
+	for _, b := range p.Buffs {
+		if b.Kind == SpeedBuff {
+			speed += b.Amount
+		}
+	}
+	if p.Horse != nil && !p.Horse.exhausted {
+		speed = math.Max(speed, p.Horse.Speed)
+	}
+	p.Speed = speed
+}
+
+// UpdateStatus ticks stamina regeneration and active buffs, it runs once per frame.
+func (p *Player) UpdateStatus(frameCount int, framesPerSecond int) {
+	if p.Stats != nil {
+		p.Stats.Update()
+		p.ApplyStats()
+		p.Healthbar.Update(p.Healthbar.X, p.Healthbar.Y, p.Health, p.MaxHealth)
+	}
+	if p.ReloadTimer > 0 {
+		p.ReloadTimer -= 1
+		if p.ReloadTimer == 0 {
+			p.finishReload()
+		}
+	}
+	if p.Stamina < p.MaxStamina {
+		p.Stamina = min(p.MaxStamina, p.Stamina+p.StaminaRegen)
+	}
+	if p.Staminabar != nil {
+		p.Staminabar.Update(p.Staminabar.X, p.Staminabar.Y, int(p.Stamina), int(p.MaxStamina))
+	}
+
+	for i := len(p.Buffs) - 1; i >= 0; i-- {
+		p.Buffs[i].Duration -= 1
+		if p.Buffs[i].Kind == RegenBuff && frameCount%framesPerSecond == 0 {
+			p.Heal(int(p.Buffs[i].Amount))
+		}
+		if p.Buffs[i].Duration <= 0 {
+			p.removeBuff(i)
+		}
+	}
+}
+
+func (p *Player) Heal(amount int) {
+	p.Health = min(p.MaxHealth, p.Health+amount)
+	p.Healthbar.Update(p.Healthbar.X, p.Healthbar.Y, p.Health, p.MaxHealth)
+}
+
+func (p *Player) Consume(item *items.Item) error {
+	if item.Kind != items.Consumable || item.Effect == nil {
+		return ErrNotConsumable
+	}
+	if err := p.Inventory.Remove(item.ID, 1); err != nil {
+		return err
+	}
+
+	effect := item.Effect
+	if effect.Heal > 0 {
+		p.Heal(effect.Heal)
+	}
+	if effect.Stamina > 0 {
+		p.Stamina = min(p.MaxStamina, p.Stamina+effect.Stamina)
+	}
+	if kind, ok := buffNames[effect.Buff]; ok && effect.BuffDuration > 0 {
+		p.AddBuff(Buff{
+			Kind:     kind,
+			Amount:   effect.BuffAmount,
+			Duration: effect.BuffDuration,
+		})
+	}
+	if stat, ok := StatByName(effect.Buff); ok && effect.BuffDuration > 0 && p.Stats != nil {
+		p.Stats.AddModifier(StatModifier{
+			Stat:     stat,
+			Amount:   effect.BuffAmount,
+			Source:   item.ID,
+			Duration: effect.BuffDuration,
+		})
+	}
+
+	return nil
+}
+
+func (p *Player) UseQuickItem() error {
+	slot := p.Inventory.QuickItem()
+	if slot == nil {
+		return ErrNothingSelected
+	}
+	item := slot.Item
+	if err := p.Consume(item); err != nil {
+		return err
+	}
+	if p.Inventory.QuickItem() == nil {
+		p.Inventory.CycleQuickSlot(1)
+	}
+
+	return nil
+}
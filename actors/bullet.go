@@ -18,6 +18,8 @@ type Bullet struct {
 	Sprite       *ebiten.Image
 	Hitbox       *HitBox
 	HitboxOffset float64
+	// Flashed is set once the muzzle flash of the shot has been shown
+	Flashed bool
 }
 
 func (b *Bullet) Draw(screen *ebiten.Image, camX float64, camY float64) {
@@ -46,3 +48,8 @@ func (b *Bullet) UpdateHitbox() {
 	b.Hitbox.X = float32(b.X + b.HitboxOffset*b.Scale)
 	b.Hitbox.Y = float32(b.Y + b.HitboxOffset*b.Scale)
 }
+
+// Center is the middle of the hitbox, where the bullet actually hits.
+func (b *Bullet) Center() (float64, float64) {
+	return float64(b.Hitbox.X + b.Hitbox.W/2), float64(b.Hitbox.Y + b.Hitbox.H/2)
+}
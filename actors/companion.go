@@ -0,0 +1,233 @@
+package actors
+
+import (
+	"math"
+
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type Command int
+
+const (
+	FollowCommand Command = iota
+	HoldCommand
+	AttackCommand
+)
+
+var commandNames = map[Command]string{
+	FollowCommand: "follow",
+	HoldCommand:   "hold",
+	AttackCommand: "attack",
+}
+
+func (c Command) String() string {
+	return commandNames[c]
+}
+
+type Companion struct {
+	*Player
+
+	Leader     *Player
+	Command    Command
+	Target     *Enemy
+	HoldX      float64
+	HoldY      float64
+	FollowDist float64
+	VisualDist int
+	// Waypoints lead around obstacles, they are planned by the game
+	Waypoints []utils.Point
+}
+
+func (c *Companion) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	c.DrawOptions.GeoM.Reset()
+	c.DrawOptions.GeoM.Scale(c.Scale, c.Scale)
+	c.DrawOptions.GeoM.Translate(float64(c.X-camX), float64(c.Y-camY))
+	screen.DrawImage(c.Sprites[c.CurrentState], c.DrawOptions)
+	c.drawEquipment(screen)
+	if !c.Dead {
+		c.Healthbar.Draw(screen, camX-16, camY-16)
+	}
+	for i := len(c.Hits) - 1; i >= 0; i-- {
+		if c.Hits[i].Duration > 0 {
+			c.Hits[i].Update()
+			c.Hits[i].Draw(screen, camX, camY)
+		} else {
+			c.Hits[i] = c.Hits[len(c.Hits)-1]
+			c.Hits = c.Hits[:len(c.Hits)-1]
+		}
+	}
+}
+
+func (c *Companion) UpdateHitboxOffset(offset int) {
+	c.Hitbox.X = float32(c.X) + float32(offset)
+	c.Hitbox.Y = float32(c.Y) + float32(offset)
+}
+
+func (c *Companion) Move(d Direction) {
+	c.MoveDirs[d] = true
+	switch d {
+	case Right:
+		c.X += c.Speed
+	case Left:
+		c.X -= c.Speed
+	case Up:
+		c.Y -= c.Speed
+	case Down:
+		c.Y += c.Speed
+	}
+	c.UpdateHitboxOffset(16)
+	c.Healthbar.Update(c.X-c.W/2, c.Y-(c.H-c.H/3), c.Health, c.MaxHealth)
+}
+
+// Order gives the companion a new command, attack needs a target
+// and holding keeps the current position.
+func (c *Companion) Order(command Command, target *Enemy) {
+	c.Command = command
+	c.Target = target
+	if command == HoldCommand {
+		c.HoldX, c.HoldY = c.X, c.Y
+	}
+	if command == AttackCommand && target == nil {
+		c.Command = FollowCommand
+	}
+}
+
+// Destination is where the current command wants the companion to go.
+func (c *Companion) Destination() (float64, float64, bool) {
+	switch c.Command {
+	case FollowCommand:
+		return c.Leader.X - 16, c.Leader.Y - 16, true
+	case HoldCommand:
+		return c.HoldX, c.HoldY, true
+	case AttackCommand:
+		if c.Target != nil {
+			return c.Target.X, c.Target.Y, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// moveTowards walks along both axes until the companion is within dist of x, y,
+// following the waypoints first when there are any.
+func (c *Companion) moveTowards(x, y, dist float64) bool {
+	if utils.DistanceBetweenPoints(c.X, c.Y, x, y) <= dist {
+		c.Waypoints = nil
+		return false
+	}
+	if len(c.Waypoints) > 0 {
+		// waypoints are the centers of the hitbox, the companion position is the top left
+		next := c.Waypoints[0]
+		x, y = next.X-16, next.Y-16
+		if utils.DistanceBetweenPoints(c.X, c.Y, x, y) <= c.Speed {
+			c.Waypoints = c.Waypoints[1:]
+		}
+	}
+	if math.Abs(c.X-x) > c.Speed {
+		if x > c.X {
+			c.Move(Right)
+		} else {
+			c.Move(Left)
+		}
+	}
+	if math.Abs(c.Y-y) > c.Speed {
+		if y > c.Y {
+			c.Move(Down)
+		} else {
+			c.Move(Up)
+		}
+	}
+
+	return true
+}
+
+// aimAt turns towards the target, a companion can only shoot
+// straight sideways or straight up and down.
+func (c *Companion) aimAt(x, y float64) {
+	dx, dy := x-c.X, y-c.Y
+	if dx < 0 {
+		c.Look(Left)
+	} else {
+		c.Look(Right)
+	}
+	if math.Abs(dy) > math.Abs(dx) {
+		if dy < 0 {
+			c.Look(Up)
+		} else {
+			c.Look(Down)
+		}
+	}
+}
+
+// clearShot checks that the leader is not standing in the line of fire.
+func (c *Companion) clearShot(x, y float64) bool {
+	leaderX, leaderY := c.Leader.X-16, c.Leader.Y-16
+	targetDist := utils.DistanceBetweenPoints(c.X, c.Y, x, y)
+	if utils.DistanceBetweenPoints(c.X, c.Y, leaderX, leaderY) > targetDist {
+		return true
+	}
+
+	return utils.DistanceToSegment(leaderX, leaderY, c.X, c.Y, x, y) > 40
+}
+
+func (c *Companion) pickTarget(enemies []*Enemy) *Enemy {
+	if c.Command == AttackCommand && c.Target != nil && !c.Target.Dead && c.Target.Capture == Free {
+		return c.Target
+	}
+	if c.Command == AttackCommand {
+		c.Command = FollowCommand
+		c.Target = nil
+	}
+
+	var nearest *Enemy
+	nearestDist := float64(c.VisualDist)
+	for _, enemy := range enemies {
+		// the gang leaves captives for the sheriff
+		if enemy.Dead || enemy.Capture != Free {
+			continue
+		}
+		dist := utils.DistanceBetweenPoints(c.X, c.Y, enemy.X, enemy.Y)
+		if dist <= nearestDist {
+			nearest = enemy
+			nearestDist = dist
+		}
+	}
+
+	return nearest
+}
+
+func (c *Companion) ThinkAndAct(enemies []*Enemy, frameCount int) {
+	moved := false
+	switch c.Command {
+	case FollowCommand:
+		moved = c.moveTowards(c.Leader.X-16, c.Leader.Y-16, c.FollowDist)
+	case HoldCommand:
+		moved = c.moveTowards(c.HoldX, c.HoldY, c.Speed)
+	case AttackCommand:
+		if c.Target != nil {
+			moved = c.moveTowards(c.Target.X, c.Target.Y, float64(c.VisualDist)/2)
+		}
+	}
+
+	target := c.pickTarget(enemies)
+	if target != nil {
+		c.aimAt(target.X, target.Y)
+		if frameCount%c.FireRate == 0 && c.clearShot(target.X, target.Y) {
+			// same bullet offset as the enemies, npc sprites are not centered
+			c.X += 16
+			c.Y += 16
+			c.Shoot()
+			c.X -= 16
+			c.Y -= 16
+		}
+	}
+
+	if moved {
+		c.Animate()
+	} else {
+		c.StopAnimation()
+	}
+	c.UpdateAnimation()
+	c.UpdateHitboxOffset(16)
+}
@@ -7,10 +7,41 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// WeakenedHealth is the part of its health an enemy has to be down to before a lasso holds it.
+const WeakenedHealth = 0.4
+
+// CaptureState is how far an enemy is from being brought in alive.
+type CaptureState int
+
+const (
+	Free CaptureState = iota
+	// Roped enemies are caught in a lasso, they work themselves out of it unless they are tied up
+	Roped
+	// Tied enemies are bound and follow the one leading them
+	Tied
+)
+
+var captureStateNames = map[CaptureState]string{
+	Free:  "free",
+	Roped: "roped",
+	Tied:  "tied",
+}
+
+func (c CaptureState) String() string {
+	return captureStateNames[c]
+}
+
 type Enemy struct {
 	*Player
 
+	// ID stays the same for as long as the enemy is around, the network knows it by its id
+	ID         uint16
 	VisualDist int
+	Capture    CaptureState
+	// Captor leads a tied enemy, nil when it was left alone
+	Captor *Player
+	// Struggle counts down the frames until a roped or abandoned enemy gets free
+	Struggle int
 }
 
 func (e *Enemy) Draw(screen *ebiten.Image, camX float64, camY float64) {
@@ -18,6 +49,7 @@ func (e *Enemy) Draw(screen *ebiten.Image, camX float64, camY float64) {
 	e.DrawOptions.GeoM.Scale(e.Scale, e.Scale)
 	e.DrawOptions.GeoM.Translate(float64(e.X-camX), float64(e.Y-camY))
 	screen.DrawImage(e.Sprites[e.CurrentState], e.DrawOptions)
+	e.drawEquipment(screen)
 	if !e.Dead {
 		e.Healthbar.Draw(screen, camX-16, camY-16)
 	}
@@ -90,5 +122,82 @@ func (e *Enemy) ThinkAndAct(player *Player, playerBullets []*Bullet, frameCount
 	}
 
 	e.CurrentAction.PerformAction(player, frameCount)
+	e.UpdateAnimation()
 	e.UpdateHitboxOffset(16)
 }
+
+// Weakened enemies are hurt enough to be roped.
+func (e *Enemy) Weakened() bool {
+	return float64(e.Health) <= float64(e.MaxHealth)*WeakenedHealth
+}
+
+// Rope catches the enemy in a lasso, it gets free after struggle frames unless it is tied up before that.
+func (e *Enemy) Rope(struggle int) {
+	e.Capture = Roped
+	e.Struggle = struggle
+	e.Bound = true
+	e.CurrentAction = Action{}
+	e.StopAnimation()
+}
+
+// TieUp binds a roped enemy, from then on it follows the captor.
+func (e *Enemy) TieUp(captor *Player) {
+	e.Capture = Tied
+	e.Captor = captor
+}
+
+// LetGo leaves a tied enemy alone, it gets free after struggle frames unless someone leads it again.
+func (e *Enemy) LetGo(struggle int) {
+	e.Captor = nil
+	e.Struggle = struggle
+}
+
+func (e *Enemy) Escape() {
+	e.Capture = Free
+	e.Captor = nil
+	e.Struggle = 0
+	e.Bound = false
+}
+
+// ActCaptive moves a roped or tied enemy instead of ThinkAndAct. A tied enemy is pulled along
+// by the rope, or carried across the saddle of a mounted captor. It returns true on the frame
+// the enemy gets free.
+func (e *Enemy) ActCaptive(ropeLength float64) bool {
+	e.Running = false
+	free := false
+	switch {
+	case e.Capture == Tied && e.Captor != nil:
+		e.follow(e.Captor, ropeLength)
+	default:
+		e.Struggle -= 1
+		if e.Struggle <= 0 {
+			e.Escape()
+			free = true
+		}
+	}
+	e.UpdateAnimation()
+	e.UpdateHitboxOffset(16)
+	e.Healthbar.Update(e.X, e.Y-(e.H-e.H/3), e.Health, e.MaxHealth)
+
+	return free
+}
+
+func (e *Enemy) follow(captor *Player, ropeLength float64) {
+	centerX, centerY := e.Center()
+	if captor.X < centerX {
+		e.VisualDir = Left
+	} else {
+		e.VisualDir = Right
+	}
+	if captor.Horse != nil {
+		e.X, e.Y = captor.X-16, captor.Y-24
+		return
+	}
+	dist := utils.DistanceBetweenPoints(centerX, centerY, captor.X, captor.Y)
+	if dist <= ropeLength {
+		return
+	}
+	e.X += (captor.X - centerX) / dist * (dist - ropeLength)
+	e.Y += (captor.Y - centerY) / dist * (dist - ropeLength)
+	e.Running = true
+}
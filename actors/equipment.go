@@ -0,0 +1,120 @@
+package actors
+
+import (
+	"fmt"
+
+	"github.com/bramca/Far-West/items"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type EquipSlot int
+
+const (
+	BootsSlot EquipSlot = iota
+	CoatSlot
+	BandanaSlot
+	HatSlot
+)
+
+// EquipSlots is also the order the clothing layers are drawn in.
+var EquipSlots = []EquipSlot{BootsSlot, CoatSlot, BandanaSlot, HatSlot}
+
+var slotNames = map[EquipSlot]string{
+	BootsSlot:   "boots",
+	CoatSlot:    "coat",
+	BandanaSlot: "bandana",
+	HatSlot:     "hat",
+}
+
+func (s EquipSlot) String() string {
+	return slotNames[s]
+}
+
+type Equipment struct {
+	Item *items.Item
+	Slot EquipSlot
+	// Sprites are layered on top of the sprite for the same PlayerState
+	Sprites   []*ebiten.Image
+	Modifiers []StatModifier
+}
+
+// NewEquipment checks the clothing data of an item, sprites must cover every PlayerState.
+func NewEquipment(item *items.Item, sprites []*ebiten.Image) (*Equipment, error) {
+	equipment := &Equipment{
+		Item:    item,
+		Sprites: sprites,
+	}
+	found := false
+	for slot, name := range slotNames {
+		if name == item.Slot {
+			equipment.Slot = slot
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("clothing %q has unknown slot %q", item.ID, item.Slot)
+	}
+	if len(sprites) <= int(PlayerDead) {
+		return nil, fmt.Errorf("clothing %q has %d sprites, needs %d", item.ID, len(sprites), PlayerDead+1)
+	}
+	for name, amount := range item.Modifiers {
+		stat, ok := StatByName(name)
+		if !ok {
+			return nil, fmt.Errorf("clothing %q modifies unknown stat %q", item.ID, name)
+		}
+		equipment.Modifiers = append(equipment.Modifiers, StatModifier{
+			Stat:   stat,
+			Amount: amount,
+			Source: "equipment:" + item.ID,
+		})
+	}
+
+	return equipment, nil
+}
+
+// Equip puts on a piece of clothing and returns what was worn in that slot before.
+func (p *Player) Equip(equipment *Equipment) *Equipment {
+	old := p.Unequip(equipment.Slot)
+	if p.Equipped == nil {
+		p.Equipped = map[EquipSlot]*Equipment{}
+	}
+	p.Equipped[equipment.Slot] = equipment
+	if p.Stats != nil {
+		for _, modifier := range equipment.Modifiers {
+			p.Stats.AddModifier(modifier)
+		}
+	}
+
+	return old
+}
+
+func (p *Player) Unequip(slot EquipSlot) *Equipment {
+	equipment, ok := p.Equipped[slot]
+	if !ok {
+		return nil
+	}
+	delete(p.Equipped, slot)
+	if p.Stats != nil {
+		p.Stats.RemoveSource("equipment:" + equipment.Item.ID)
+	}
+
+	return equipment
+}
+
+// drawEquipment layers the worn clothing with the draw options of the body sprite.
+func (p *Player) drawEquipment(screen *ebiten.Image) {
+	for _, slot := range EquipSlots {
+		if equipment, ok := p.Equipped[slot]; ok {
+			screen.DrawImage(equipment.Sprites[p.CurrentState], p.DrawOptions)
+		}
+	}
+}
+
+// TakeDamage lowers the damage by the armor of the actor and returns what is left.
+func (p *Player) TakeDamage(damage int) int {
+	armor := min(80, p.Stat(ArmorStat))
+	taken := int(float64(damage)*(100-armor)/100 + 0.5)
+	p.Health -= taken
+
+	return taken
+}
@@ -2,7 +2,9 @@ package actors
 
 import (
 	"image/color"
+	"math"
 
+	"github.com/bramca/Far-West/hitmask"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
@@ -10,6 +12,9 @@ import (
 type HitBox struct {
 	X, Y float32
 	W, H float32
+	// Mask is optional and stretched over the whole hitbox,
+	// hitboxes with a mask can use CheckPixelCollision
+	Mask *hitmask.Mask
 }
 
 func (h *HitBox) Draw(screen *ebiten.Image, camX float64, camY float64) {
@@ -22,3 +27,48 @@ func (h *HitBox) CheckCollision(hitbox *HitBox) bool {
 		h.Y+h.H >= hitbox.Y && // h top edge past hitbox bottom
 		h.Y <= hitbox.Y+hitbox.H
 }
+
+// CheckPixelCollision first checks the bounding boxes and then looks for a solid pixel
+// that both hitboxes share, a hitbox without a mask is solid everywhere.
+func (h *HitBox) CheckPixelCollision(hitbox *HitBox) bool {
+	if !h.CheckCollision(hitbox) {
+		return false
+	}
+	if h.Mask == nil && hitbox.Mask == nil {
+		return true
+	}
+
+	left := math.Max(float64(h.X), float64(hitbox.X))
+	right := math.Min(float64(h.X+h.W), float64(hitbox.X+hitbox.W))
+	top := math.Max(float64(h.Y), float64(hitbox.Y))
+	bottom := math.Min(float64(h.Y+h.H), float64(hitbox.Y+hitbox.H))
+	// sample at the size of the smallest mask pixel, so no pixel is skipped
+	step := math.Min(h.pixelSize(), hitbox.pixelSize())
+	for y := top + step/2; y < bottom; y += step {
+		for x := left + step/2; x < right; x += step {
+			if h.solidAt(x, y) && hitbox.solidAt(x, y) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (h *HitBox) pixelSize() float64 {
+	if h.Mask == nil || h.Mask.W == 0 || h.Mask.H == 0 {
+		return math.Inf(1)
+	}
+
+	return math.Min(float64(h.W)/float64(h.Mask.W), float64(h.H)/float64(h.Mask.H))
+}
+
+func (h *HitBox) solidAt(x, y float64) bool {
+	if h.Mask == nil {
+		return true
+	}
+	maskX := int((x - float64(h.X)) / float64(h.W) * float64(h.Mask.W))
+	maskY := int((y - float64(h.Y)) / float64(h.H) * float64(h.Mask.H))
+
+	return h.Mask.At(maskX, maskY)
+}
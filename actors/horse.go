@@ -0,0 +1,215 @@
+package actors
+
+import (
+	"math"
+
+	"github.com/bramca/Far-West/hud"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type HorseState int
+
+const (
+	HorseRight HorseState = iota
+	HorseLeft
+	HorseRunRight
+	HorseRunLeft
+)
+
+const (
+	// riderOffset is how far below the rider the horse is drawn
+	riderOffset = 18.0
+	// mountedHitboxGrowth is added to the rider hitbox while mounted
+	mountedHitboxGrowth = 16.0
+)
+
+type Horse struct {
+	X, Y           float64
+	W, H           float64
+	Sprites        []*ebiten.Image
+	CurrentState   HorseState
+	Scale          float64
+	Speed          float64
+	Stamina        float64
+	MaxStamina     float64
+	StaminaRegen   float64
+	StaminaDrain   float64
+	Health         int
+	MaxHealth      int
+	AnimationSpeed int
+	DrawOptions    *ebiten.DrawImageOptions
+	Hitbox         *HitBox
+	Healthbar      *hud.ResourceBar
+	Hits           []Hit
+	Rider          *Player
+	Owner          *Player
+	FleeDuration   int
+	FleeAngle      float64
+	Dead           bool
+
+	exhausted bool
+}
+
+func (h *Horse) Draw(screen *ebiten.Image, camX, camY float64) {
+	h.DrawOptions.GeoM.Reset()
+	h.DrawOptions.GeoM.Scale(h.Scale, h.Scale)
+	h.DrawOptions.GeoM.Translate(h.X-camX, h.Y-camY)
+	screen.DrawImage(h.Sprites[h.CurrentState], h.DrawOptions)
+	if h.Rider == nil && h.Health < h.MaxHealth {
+		h.Healthbar.Draw(screen, camX, camY)
+	}
+	for i := len(h.Hits) - 1; i >= 0; i-- {
+		if h.Hits[i].Duration > 0 {
+			h.Hits[i].Update()
+			h.Hits[i].Draw(screen, camX, camY)
+		} else {
+			h.Hits[i] = h.Hits[len(h.Hits)-1]
+			h.Hits = h.Hits[:len(h.Hits)-1]
+		}
+	}
+}
+
+func (h *Horse) DrawHitbox(screen *ebiten.Image, camX, camY float64) {
+	h.Hitbox.Draw(screen, camX, camY)
+}
+
+func (h *Horse) UpdateHitbox() {
+	h.Hitbox.X = float32(h.X + h.W*h.Scale/6)
+	h.Hitbox.Y = float32(h.Y + h.H*h.Scale/3)
+	h.Healthbar.Update(h.X+h.W/2, h.Y+h.H/3, h.Health, h.MaxHealth)
+}
+
+func (h *Horse) faceLeft() bool {
+	return h.CurrentState == HorseLeft || h.CurrentState == HorseRunLeft
+}
+
+func (h *Horse) setFacing(left bool, running bool) {
+	switch {
+	case left && running:
+		h.CurrentState = HorseRunLeft
+	case left:
+		h.CurrentState = HorseLeft
+	case running:
+		h.CurrentState = HorseRunRight
+	default:
+		h.CurrentState = HorseRight
+	}
+}
+
+// Mount puts the player in the saddle,
+// the rider gets the horse speed and a larger hitbox.
+func (p *Player) Mount(h *Horse) bool {
+	if p.Horse != nil || h.Rider != nil || h.Dead || h.FleeDuration > 0 {
+		return false
+	}
+	if !p.CurrentWeapon.OneHanded() {
+		p.DrawWeapon(Revolver)
+	}
+	p.Horse = h
+	h.Rider = p
+	p.UpdateSpeed()
+	p.Hitbox.W += mountedHitboxGrowth
+	p.Hitbox.H += mountedHitboxGrowth
+	// a mounted rider can only aim sideways
+	switch p.VisualDir {
+	case LeftUp, LeftDown:
+		p.ChangeVisualDirection(Left)
+	case RightUp, RightDown:
+		p.ChangeVisualDirection(Right)
+	}
+	h.FollowRider(false, 1)
+
+	return true
+}
+
+func (p *Player) Dismount() {
+	h := p.Horse
+	if h == nil {
+		return
+	}
+	p.Hitbox.W -= mountedHitboxGrowth
+	p.Hitbox.H -= mountedHitboxGrowth
+	p.Horse = nil
+	h.Rider = nil
+	p.UpdateSpeed()
+	h.setFacing(h.faceLeft(), false)
+}
+
+// FollowRider keeps the horse under its rider and drains stamina while galloping.
+// An exhausted horse walks at the pace of its rider until half its stamina is back.
+func (h *Horse) FollowRider(moving bool, frameCount int) {
+	p := h.Rider
+	h.X = p.X - p.W/2
+	h.Y = p.Y - p.H/2 + riderOffset
+
+	if moving {
+		h.Stamina = math.Max(0, h.Stamina-h.StaminaDrain)
+	} else {
+		h.Stamina = math.Min(h.MaxStamina, h.Stamina+h.StaminaRegen)
+	}
+	if !h.exhausted && h.Stamina == 0 {
+		h.exhausted = true
+		p.UpdateSpeed()
+	}
+	if h.exhausted && h.Stamina >= h.MaxStamina/2 {
+		h.exhausted = false
+		p.UpdateSpeed()
+	}
+
+	left := p.VisualDir == Left || p.VisualDir == LeftUp || p.VisualDir == LeftDown
+	running := h.CurrentState == HorseRunLeft || h.CurrentState == HorseRunRight
+	if moving && frameCount%h.AnimationSpeed == 0 {
+		running = !running
+	}
+	if !moving {
+		running = false
+	}
+	h.setFacing(left, running)
+	h.UpdateHitbox()
+}
+
+// Shot is called when a bullet hits the horse, it throws off its rider and bolts
+// away from where the shot came from.
+func (h *Horse) Shot(damage int, fromX, fromY float64, fleeDuration int) {
+	h.Health -= damage
+	if h.Rider != nil {
+		h.Rider.Dismount()
+	}
+	if h.Health <= 0 {
+		h.Health = 0
+		h.Dead = true
+		return
+	}
+	h.FleeAngle = math.Atan2(h.Y-fromY, h.X-fromX)
+	h.FleeDuration = fleeDuration
+}
+
+// Update moves a riderless horse, only fleeing horses move on their own.
+func (h *Horse) Update(frameCount int) {
+	if h.Rider != nil || h.Dead {
+		return
+	}
+	h.Stamina = math.Min(h.MaxStamina, h.Stamina+h.StaminaRegen)
+	if h.FleeDuration <= 0 {
+		h.setFacing(h.faceLeft(), false)
+		return
+	}
+
+	h.FleeDuration -= 1
+	h.X += h.Speed * math.Cos(h.FleeAngle)
+	h.Y += h.Speed * math.Sin(h.FleeAngle)
+	running := h.CurrentState == HorseRunLeft || h.CurrentState == HorseRunRight
+	if frameCount%h.AnimationSpeed == 0 {
+		running = !running
+	}
+	h.setFacing(math.Cos(h.FleeAngle) < 0, running)
+	h.UpdateHitbox()
+}
+
+// TurnAround sends a fleeing horse back the way it came, used when it runs into something.
+func (h *Horse) TurnAround() {
+	h.X -= h.Speed * math.Cos(h.FleeAngle)
+	h.Y -= h.Speed * math.Sin(h.FleeAngle)
+	h.FleeAngle += math.Pi
+	h.UpdateHitbox()
+}
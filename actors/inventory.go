@@ -0,0 +1,156 @@
+package actors
+
+import (
+	"errors"
+
+	"github.com/bramca/Far-West/items"
+)
+
+var (
+	ErrInventoryFull   = errors.New("inventory is full")
+	ErrTooHeavy        = errors.New("too heavy to carry")
+	ErrNotInInventory  = errors.New("item is not in the inventory")
+	ErrNotConsumable   = errors.New("item can not be used")
+	ErrNothingSelected = errors.New("no item selected")
+)
+
+type InventorySlot struct {
+	Item  *items.Item
+	Count int
+}
+
+type Inventory struct {
+	// Slots has a fixed length, empty slots are nil
+	Slots     []*InventorySlot
+	MaxWeight float64
+	QuickSlot int
+}
+
+func NewInventory(slots int, maxWeight float64) *Inventory {
+	return &Inventory{
+		Slots:     make([]*InventorySlot, slots),
+		MaxWeight: maxWeight,
+	}
+}
+
+func (inv *Inventory) Weight() float64 {
+	weight := 0.0
+	for _, slot := range inv.Slots {
+		if slot != nil {
+			weight += slot.Item.Weight * float64(slot.Count)
+		}
+	}
+
+	return weight
+}
+
+func (inv *Inventory) Count(itemID string) int {
+	count := 0
+	for _, slot := range inv.Slots {
+		if slot != nil && slot.Item.ID == itemID {
+			count += slot.Count
+		}
+	}
+
+	return count
+}
+
+// CanAdd reports whether amount of item fits, without changing the inventory.
+func (inv *Inventory) CanAdd(item *items.Item, amount int) error {
+	if inv.Weight()+item.Weight*float64(amount) > inv.MaxWeight {
+		return ErrTooHeavy
+	}
+
+	room := 0
+	for _, slot := range inv.Slots {
+		switch {
+		case slot == nil:
+			room += item.MaxStack
+		case slot.Item.ID == item.ID:
+			room += item.MaxStack - slot.Count
+		}
+	}
+	if room < amount {
+		return ErrInventoryFull
+	}
+
+	return nil
+}
+
+// Add stacks onto existing slots first and fills empty slots after that.
+// Nothing is added when the full amount does not fit.
+func (inv *Inventory) Add(item *items.Item, amount int) error {
+	if err := inv.CanAdd(item, amount); err != nil {
+		return err
+	}
+
+	for _, slot := range inv.Slots {
+		if amount == 0 {
+			return nil
+		}
+		if slot != nil && slot.Item.ID == item.ID && slot.Count < item.MaxStack {
+			added := min(amount, item.MaxStack-slot.Count)
+			slot.Count += added
+			amount -= added
+		}
+	}
+	for i, slot := range inv.Slots {
+		if amount == 0 {
+			return nil
+		}
+		if slot == nil {
+			added := min(amount, item.MaxStack)
+			inv.Slots[i] = &InventorySlot{Item: item, Count: added}
+			amount -= added
+		}
+	}
+
+	return nil
+}
+
+// Remove takes from the last matching slots first, so the first stacks stay full.
+func (inv *Inventory) Remove(itemID string, amount int) error {
+	if inv.Count(itemID) < amount {
+		return ErrNotInInventory
+	}
+
+	for i := len(inv.Slots) - 1; i >= 0 && amount > 0; i-- {
+		slot := inv.Slots[i]
+		if slot == nil || slot.Item.ID != itemID {
+			continue
+		}
+		removed := min(amount, slot.Count)
+		slot.Count -= removed
+		amount -= removed
+		if slot.Count == 0 {
+			inv.Slots[i] = nil
+		}
+	}
+
+	return nil
+}
+
+// CycleQuickSlot moves the quick-use selection to the next slot holding a consumable.
+func (inv *Inventory) CycleQuickSlot(step int) {
+	n := len(inv.Slots)
+	for i := 1; i <= n; i++ {
+		index := ((inv.QuickSlot+i*step)%n + n) % n
+		slot := inv.Slots[index]
+		if slot != nil && slot.Item.Kind.Usable() {
+			inv.QuickSlot = index
+			return
+		}
+	}
+}
+
+func (inv *Inventory) QuickItem() *InventorySlot {
+	if inv.QuickSlot < 0 || inv.QuickSlot >= len(inv.Slots) {
+		return nil
+	}
+	slot := inv.Slots[inv.QuickSlot]
+	if slot == nil || !slot.Item.Kind.Usable() {
+		return nil
+	}
+
+	return slot
+}
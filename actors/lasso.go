@@ -0,0 +1,92 @@
+package actors
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	lassoSpeed = 6.0
+	// lassoRange is how far the loop flies before it is pulled back in
+	lassoRange  = 220.0
+	lassoRadius = 14.0
+)
+
+var lassoColor = color.RGBA{196, 156, 88, 255}
+
+// LassoLoop is a thrown lasso, it flies out in a straight line and is reeled back in to the thrower.
+type LassoLoop struct {
+	X, Y   float64
+	Radius float64
+	// Returning is set once the loop flew its range or caught something
+	Returning bool
+
+	dirX, dirY float64
+	travelled  float64
+}
+
+func NewLassoLoop(x, y, angle float64) *LassoLoop {
+	return &LassoLoop{
+		X:      x,
+		Y:      y,
+		Radius: lassoRadius,
+		dirX:   math.Cos(angle),
+		dirY:   math.Sin(angle),
+	}
+}
+
+// Update moves the loop one frame, back towards the thrower at x, y when it is returning.
+// It returns true once the loop is back in the hand.
+func (l *LassoLoop) Update(x, y float64) bool {
+	if !l.Returning {
+		l.X += l.dirX * lassoSpeed
+		l.Y += l.dirY * lassoSpeed
+		l.travelled += lassoSpeed
+		l.Returning = l.travelled >= lassoRange
+		return false
+	}
+	// reeling in is quicker than throwing
+	dist := utils.DistanceBetweenPoints(l.X, l.Y, x, y)
+	if dist <= 2*lassoSpeed {
+		return true
+	}
+	l.X += (x - l.X) / dist * 2 * lassoSpeed
+	l.Y += (y - l.Y) / dist * 2 * lassoSpeed
+
+	return false
+}
+
+// Catches reports whether the loop touches the hitbox.
+func (l *LassoLoop) Catches(h *HitBox) bool {
+	nearestX := math.Max(float64(h.X), math.Min(l.X, float64(h.X+h.W)))
+	nearestY := math.Max(float64(h.Y), math.Min(l.Y, float64(h.Y+h.H)))
+
+	return utils.DistanceBetweenPoints(l.X, l.Y, nearestX, nearestY) <= l.Radius
+}
+
+// Draw draws the rope from the thrower at x, y to the loop.
+func (l *LassoLoop) Draw(screen *ebiten.Image, x, y, camX, camY float64) {
+	loopX, loopY := float32(l.X-camX), float32(l.Y-camY)
+	vector.StrokeLine(screen, float32(x-camX), float32(y-camY), loopX, loopY, 2, lassoColor, true)
+	vector.StrokeCircle(screen, loopX, loopY, float32(l.Radius), 2, lassoColor, true)
+}
+
+// DrawRope draws the rope a tied enemy is led on, there is none while it is free or across a saddle.
+func (e *Enemy) DrawRope(screen *ebiten.Image, camX, camY float64) {
+	if e.Capture != Tied || e.Captor == nil || e.Captor.Horse != nil {
+		return
+	}
+	x, y := e.Center()
+	vector.StrokeLine(screen, float32(e.Captor.X-camX), float32(e.Captor.Y-camY), float32(x-camX), float32(y-camY), 1, lassoColor, true)
+}
+
+// UpdateLasso moves the thrown lasso of the player, if there is one.
+func (p *Player) UpdateLasso() {
+	if p.Loop != nil && p.Loop.Update(p.X, p.Y) {
+		p.Loop = nil
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"math/rand"
 
+	"github.com/bramca/Far-West/hud"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -27,8 +28,19 @@ const (
 const (
 	Fists Weapon = iota
 	Revolver
+	// Lasso ropes a weakened enemy instead of hurting it
+	Lasso
 )
 
+// RevolverRounds is the item id of the boxes of ammunition loaded into the revolver.
+const RevolverRounds = "revolver-rounds"
+
+// RoundsPerBox is how many rounds there are in one box of RevolverRounds.
+const RoundsPerBox = 6
+
+// npcOffset is how far the middle of an npc is from the top left corner it is drawn from.
+const npcOffset = 16
+
 const (
 	PlayerNoGunRight PlayerState = iota
 	PlayerNoGunLeft
@@ -47,8 +59,23 @@ const (
 	PlayerRevolverRunLeftUp
 	PlayerRevolverRunLeftDown
 	PlayerDead
+	// captured npcs are tied up and wriggle to get loose
+	PlayerCapturedRight
+	PlayerCapturedRightStruggle
+	PlayerCapturedLeft
+	PlayerCapturedLeftStruggle
 )
 
+// aimAngles are the directions shots and throws leave in for every way an actor can face.
+var aimAngles = map[Direction]float64{
+	Right:     0,
+	LeftUp:    3 * math.Pi / 2,
+	RightUp:   3 * math.Pi / 2,
+	Left:      math.Pi,
+	LeftDown:  math.Pi / 2,
+	RightDown: math.Pi / 2,
+}
+
 type Player struct {
 	X, Y           float64
 	W, H           float64
@@ -56,6 +83,7 @@ type Player struct {
 	CurrentState   PlayerState
 	Scale          float64
 	Speed          float64
+	BaseSpeed      float64
 	DodgeDuration  int
 	DodgeSpeed     float64
 	AnimationSpeed int
@@ -68,13 +96,35 @@ type Player struct {
 	Bullets        []*Bullet
 	BulletSprite   *ebiten.Image
 	FireRate       int
-	Healthbar      *HealthBar
+	Healthbar      *hud.ResourceBar
 	Health         int
 	MaxHealth      int
 	IsNpc          bool
 	Running        bool
 	Hits           []Hit
 	Dead           bool
+	Money          int
+	Reputation     int
+	Inventory      *Inventory
+	Stamina        float64
+	MaxStamina     float64
+	StaminaRegen   float64
+	DodgeCost      float64
+	Staminabar     *hud.ResourceBar
+	Buffs          []Buff
+	Horse          *Horse
+	Stats          *Stats
+	Ammo           int
+	CylinderSize   int
+	ReloadTimer    int
+	Equipped       map[EquipSlot]*Equipment
+	Animator       *Animator
+	// Loop is the thrown lasso, nil while it is coiled up
+	Loop *LassoLoop
+	// Bound actors are roped or tied up and can not use their hands
+	Bound bool
+	// LooseRounds are left over from the last box opened for a reload
+	LooseRounds int
 }
 
 func (p *Player) Draw(screen *ebiten.Image, camX, camY float64) {
@@ -84,7 +134,10 @@ func (p *Player) Draw(screen *ebiten.Image, camX, camY float64) {
 	p.DrawOptions.GeoM.Translate(-float64(p.W/2), -float64(p.H/2))
 	p.DrawOptions.GeoM.Translate(p.X-camX, p.Y-camY)
 	screen.DrawImage(p.Sprites[p.CurrentState], p.DrawOptions)
-	p.Healthbar.Draw(screen, camX, camY)
+	p.drawEquipment(screen)
+	if p.Horse != nil {
+		p.Horse.Draw(screen, camX, camY)
+	}
 	for i := len(p.Hits) - 1; i >= 0; i-- {
 		if p.Hits[i].Duration > 0 {
 			p.Hits[i].Update()
@@ -100,30 +153,89 @@ func (p *Player) DrawHitbox(screen *ebiten.Image, camX, camY float64) {
 	p.Hitbox.Draw(screen, camX, camY)
 }
 
+// Center is the middle of the actor in the world. The player is drawn around its position
+// and npcs are drawn from their top left corner.
+func (p *Player) Center() (float64, float64) {
+	if p.IsNpc {
+		return p.X + npcOffset, p.Y + npcOffset
+	}
+
+	return p.X, p.Y
+}
+
 func (p *Player) UpdateHitbox() {
 	p.Hitbox.X = float32(p.X)
 	p.Hitbox.Y = float32(p.Y)
 }
 
+// OneHanded weapons are the only ones that can be used from the saddle.
+func (w Weapon) OneHanded() bool {
+	switch w {
+	case Revolver, Lasso:
+		return true
+	}
+
+	return false
+}
+
 func (p *Player) Shoot() {
 	if p.CurrentWeapon == Fists {
 		return
 	}
+	if p.Horse != nil && !p.CurrentWeapon.OneHanded() {
+		return
+	}
+	if p.CurrentWeapon == Lasso {
+		if p.Loop == nil {
+			p.Loop = NewLassoLoop(p.X, p.Y, aimAngles[p.VisualDir])
+		}
+		return
+	}
+	if p.ReloadTimer > 0 {
+		return
+	}
+	// npcs carry all the ammunition they need
+	if !p.IsNpc {
+		if p.Ammo <= 0 {
+			p.Reload()
+			return
+		}
+		p.Ammo -= 1
+	}
 	switch p.CurrentWeapon {
 	case Revolver:
 		bulletSpeed := 4.0
 		bulletDuration := 500
 		bulletDamage := 3
-		bulletDirection := map[Direction]float64{
-			Right:     0,
-			LeftUp:    3 * math.Pi / 2,
-			RightUp:   3 * math.Pi / 2,
-			Left:      math.Pi,
-			LeftDown:  math.Pi / 2,
-			RightDown: math.Pi / 2,
-		}
-		p.addBullet(p.BulletSprite, bulletSpeed, bulletDirection[p.VisualDir], bulletDuration, bulletDamage)
+		spread := (rand.Float64()*2 - 1) * p.Spread()
+		p.addBullet(p.BulletSprite, bulletSpeed, aimAngles[p.VisualDir]+spread, bulletDuration, bulletDamage)
+	}
+}
+
+// Reload starts filling the cylinder with rounds from the inventory.
+func (p *Player) Reload() bool {
+	if p.IsNpc || p.ReloadTimer > 0 || p.Ammo >= p.CylinderSize || p.Rounds() == 0 {
+		return false
+	}
+	p.ReloadTimer = p.ReloadDuration()
+
+	return true
+}
+
+// finishReload opens as many boxes as the cylinder needs, what is left of a box stays loose.
+func (p *Player) finishReload() {
+	missing := p.CylinderSize - p.Ammo
+	for p.LooseRounds < missing && p.Inventory.Remove(RevolverRounds, 1) == nil {
+		p.LooseRounds += RoundsPerBox
 	}
+	rounds := min(missing, p.LooseRounds)
+	p.LooseRounds -= rounds
+	p.Ammo += rounds
+}
+
+// Rounds counts the rounds left to reload with, loose and in boxes.
+func (p *Player) Rounds() int {
+	return p.LooseRounds + p.Inventory.Count(RevolverRounds)*RoundsPerBox
 }
 
 func (p *Player) Move(d Direction) {
@@ -155,6 +267,10 @@ func (p *Player) Act(frameCount int) {
 }
 
 func (p *Player) Look(d Direction) {
+	// a mounted rider can only aim sideways
+	if p.Horse != nil && (d == Up || d == Down) {
+		return
+	}
 	switch d {
 	case Up:
 		switch p.VisualDir {
@@ -212,182 +328,66 @@ func (p *Player) UpdateCurrentState(newState PlayerState) {
 
 func (p *Player) ChangeVisualDirection(newDir Direction) {
 	p.VisualDir = newDir
-	switch p.CurrentWeapon {
-	case Revolver:
-		switch p.VisualDir {
-		case Left:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunLeft)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverLeft)
-			}
-		case LeftUp:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunLeftUp)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverLeftUp)
-			}
-		case LeftDown:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunLeftDown)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverLeftDown)
-			}
-		case Right:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunRight)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverRight)
-			}
-		case RightUp:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunRightUp)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverRightUp)
-			}
-		case RightDown:
-			if p.Running {
-				p.UpdateCurrentState(PlayerRevolverRunRightDown)
-			} else {
-				p.UpdateCurrentState(PlayerRevolverRightDown)
-			}
-		}
-	case Fists:
-		switch p.VisualDir {
-		case Left:
-			p.UpdateCurrentState(PlayerNoGunLeft)
-		case Right:
-			p.UpdateCurrentState(PlayerNoGunRight)
-		}
-	}
+	p.playAnimation()
 }
 
 func (p *Player) DrawWeapon(weapon Weapon) {
-	switch weapon {
-	case Revolver:
-		p.CurrentWeapon = Revolver
-		switch p.VisualDir {
-		case Left:
-			p.UpdateCurrentState(PlayerRevolverLeft)
-		case LeftUp:
-			p.UpdateCurrentState(PlayerRevolverLeftUp)
-		case LeftDown:
-			p.UpdateCurrentState(PlayerRevolverLeftDown)
-		case Right:
-			p.UpdateCurrentState(PlayerRevolverRight)
-		case RightUp:
-			p.UpdateCurrentState(PlayerRevolverRightUp)
-		case RightDown:
-			p.UpdateCurrentState(PlayerRevolverRightDown)
-		}
-	case Fists:
-		p.CurrentWeapon = Fists
-		switch p.VisualDir {
-		case Left:
-			p.UpdateCurrentState(PlayerNoGunLeft)
-		case LeftUp:
-			p.UpdateCurrentState(PlayerNoGunLeft)
-		case LeftDown:
-			p.UpdateCurrentState(PlayerNoGunLeft)
-		case Right:
-			p.UpdateCurrentState(PlayerNoGunRight)
-		case RightUp:
-			p.UpdateCurrentState(PlayerNoGunRight)
-		case RightDown:
-			p.UpdateCurrentState(PlayerNoGunRight)
-		}
-	}
+	p.CurrentWeapon = weapon
+	p.playAnimation()
 }
 
+// Animate marks the player as moving, the animator plays the run cycle
+// until StopAnimation is called.
 func (p *Player) Animate() {
-	switch p.CurrentState {
-	case PlayerNoGunRight:
-		p.UpdateCurrentState(PlayerNoGunRunRight)
-		p.Running = true
-	case PlayerNoGunLeft:
-		p.UpdateCurrentState(PlayerNoGunRunLeft)
-		p.Running = true
-	case PlayerNoGunRunRight:
-		p.UpdateCurrentState(PlayerNoGunRight)
-		p.Running = false
-	case PlayerNoGunRunLeft:
-		p.UpdateCurrentState(PlayerNoGunLeft)
-		p.Running = false
-	case PlayerRevolverRight:
-		p.UpdateCurrentState(PlayerRevolverRunRight)
-		p.Running = true
-	case PlayerRevolverRightUp:
-		p.UpdateCurrentState(PlayerRevolverRunRightUp)
-		p.Running = true
-	case PlayerRevolverRightDown:
-		p.UpdateCurrentState(PlayerRevolverRunRightDown)
-		p.Running = true
-	case PlayerRevolverLeft:
-		p.UpdateCurrentState(PlayerRevolverRunLeft)
-		p.Running = true
-	case PlayerRevolverLeftUp:
-		p.UpdateCurrentState(PlayerRevolverRunLeftUp)
-		p.Running = true
-	case PlayerRevolverLeftDown:
-		p.UpdateCurrentState(PlayerRevolverRunLeftDown)
-		p.Running = true
-	case PlayerRevolverRunRight:
-		p.UpdateCurrentState(PlayerRevolverRight)
-		p.Running = false
-	case PlayerRevolverRunRightUp:
-		p.UpdateCurrentState(PlayerRevolverRightUp)
-		p.Running = false
-	case PlayerRevolverRunRightDown:
-		p.UpdateCurrentState(PlayerRevolverRightDown)
-		p.Running = false
-	case PlayerRevolverRunLeft:
-		p.UpdateCurrentState(PlayerRevolverLeft)
-		p.Running = false
-	case PlayerRevolverRunLeftUp:
-		p.UpdateCurrentState(PlayerRevolverLeftUp)
-		p.Running = false
-	case PlayerRevolverRunLeftDown:
-		p.UpdateCurrentState(PlayerRevolverLeftDown)
-		p.Running = false
-	}
+	p.Running = true
+	p.playAnimation()
 }
 
 func (p *Player) StopAnimation() {
-	switch p.CurrentState {
-	case PlayerRevolverRunRight:
-		p.UpdateCurrentState(PlayerRevolverRight)
-	case PlayerRevolverRunLeft:
-		p.UpdateCurrentState(PlayerRevolverLeft)
-	case PlayerNoGunRunRight:
-		p.UpdateCurrentState(PlayerNoGunRight)
-	case PlayerNoGunRunLeft:
-		p.UpdateCurrentState(PlayerNoGunLeft)
-	}
+	p.Running = false
+	p.playAnimation()
 }
 
 func removeFromBullets(bullets []*Bullet, index int) []*Bullet {
 	return append(bullets[:index], bullets[index+1:]...)
 }
 
+// rollDamage gives lucky shooters a second roll, keeping the best one.
+func (p *Player) rollDamage(maxDamage int) int {
+	damage := rand.Intn(maxDamage + 1)
+	if rand.Float64()*100 < p.Stat(LuckStat) {
+		damage = max(damage, rand.Intn(maxDamage+1))
+	}
+
+	return damage
+}
+
 func (p *Player) addBullet(bulletSprite *ebiten.Image, bulletSpeed float64, bulletRotation float64, duration int, damage int) {
 	scale := float64(4)
 	// The middle of the bullet starts at the middle of the player
 	x := p.X - float64(bulletSprite.Bounds().Dx())*scale/2 + p.W/2
 	y := p.Y - float64(bulletSprite.Bounds().Dy())*scale/2 + p.H/2
+	p.Bullets = append(p.Bullets, NewBullet(bulletSprite, x, y, bulletRotation, bulletSpeed, duration, p.rollDamage(damage)))
+}
+
+// NewBullet is a revolver bullet with its top left corner at x, y flying in the direction r.
+func NewBullet(sprite *ebiten.Image, x, y, r, speed float64, duration int, damage int) *Bullet {
+	scale := float64(4)
 	bulletPixelWidth := 3.0
 	bulletPixelHeight := 3.0
 	offset := float64(14)
-	bullet := &Bullet{
+
+	return &Bullet{
 		X:           x,
 		Y:           y,
-		W:           float64(bulletSprite.Bounds().Dx()),
-		H:           float64(bulletSprite.Bounds().Dy()),
-		R:           bulletRotation,
+		W:           float64(sprite.Bounds().Dx()),
+		H:           float64(sprite.Bounds().Dy()),
+		R:           r,
 		DrawOptions: &ebiten.DrawImageOptions{},
 		Scale:       scale,
-		Speed:       bulletSpeed,
-		Sprite:      bulletSprite,
-		Damage:      rand.Intn(damage + 1),
+		Speed:       speed,
+		Sprite:      sprite,
+		Damage:      damage,
 		Duration:    duration,
 		Hitbox: &HitBox{
 			X: float32(x + offset*scale),
@@ -397,6 +397,4 @@ func (p *Player) addBullet(bulletSprite *ebiten.Image, bulletSpeed float64, bull
 		},
 		HitboxOffset: offset,
 	}
-
-	p.Bullets = append(p.Bullets, bullet)
 }
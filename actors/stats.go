@@ -0,0 +1,159 @@
+package actors
+
+import (
+	"math"
+)
+
+type Stat int
+
+const (
+	HealthStat Stat = iota
+	StaminaStat
+	AccuracyStat
+	ReloadSpeedStat
+	CharismaStat
+	LuckStat
+	ArmorStat
+)
+
+// AllStats lists the stats in the order they are shown to the player.
+var AllStats = []Stat{HealthStat, StaminaStat, AccuracyStat, ReloadSpeedStat, CharismaStat, LuckStat, ArmorStat}
+
+var statNames = map[Stat]string{
+	HealthStat:      "health",
+	StaminaStat:     "stamina",
+	AccuracyStat:    "accuracy",
+	ReloadSpeedStat: "reload speed",
+	CharismaStat:    "charisma",
+	LuckStat:        "luck",
+	ArmorStat:       "armor",
+}
+
+// defaultStats are used for actors without a stat block, like most npcs.
+var defaultStats = map[Stat]float64{
+	HealthStat:      10,
+	StaminaStat:     100,
+	AccuracyStat:    60,
+	ReloadSpeedStat: 50,
+	CharismaStat:    0,
+	LuckStat:        0,
+	ArmorStat:       0,
+}
+
+func (s Stat) String() string {
+	return statNames[s]
+}
+
+func StatByName(name string) (Stat, bool) {
+	for stat, statName := range statNames {
+		if statName == name {
+			return stat, true
+		}
+	}
+
+	return 0, false
+}
+
+type StatModifier struct {
+	Stat   Stat
+	Amount float64
+	Source string
+	// Duration in frames, modifiers with 0 duration stay until removed
+	Duration int
+}
+
+type Stats struct {
+	Base      map[Stat]float64
+	Modifiers []StatModifier
+}
+
+func NewStats(base map[Stat]float64) *Stats {
+	stats := &Stats{
+		Base: map[Stat]float64{},
+	}
+	for stat, value := range defaultStats {
+		stats.Base[stat] = value
+	}
+	for stat, value := range base {
+		stats.Base[stat] = value
+	}
+
+	return stats
+}
+
+func (s *Stats) Value(stat Stat) float64 {
+	value := s.Base[stat]
+	for _, modifier := range s.Modifiers {
+		if modifier.Stat == stat {
+			value += modifier.Amount
+		}
+	}
+
+	return math.Max(0, value)
+}
+
+// Bonus is the sum of all modifiers on a stat.
+func (s *Stats) Bonus(stat Stat) float64 {
+	return s.Value(stat) - s.Base[stat]
+}
+
+func (s *Stats) AddModifier(modifier StatModifier) {
+	s.Modifiers = append(s.Modifiers, modifier)
+}
+
+func (s *Stats) RemoveSource(source string) {
+	for i := len(s.Modifiers) - 1; i >= 0; i-- {
+		if s.Modifiers[i].Source == source {
+			s.Modifiers = append(s.Modifiers[:i], s.Modifiers[i+1:]...)
+		}
+	}
+}
+
+// Update ticks down temporary modifiers and drops the ones that ran out.
+func (s *Stats) Update() {
+	for i := len(s.Modifiers) - 1; i >= 0; i-- {
+		if s.Modifiers[i].Duration == 0 {
+			continue
+		}
+		s.Modifiers[i].Duration -= 1
+		if s.Modifiers[i].Duration <= 0 {
+			s.Modifiers = append(s.Modifiers[:i], s.Modifiers[i+1:]...)
+		}
+	}
+}
+
+func (p *Player) Stat(stat Stat) float64 {
+	if p.Stats == nil {
+		return defaultStats[stat]
+	}
+
+	return p.Stats.Value(stat)
+}
+
+// ApplyStats keeps the values derived from stats in sync,
+// current health and stamina never go over their new maximum.
+func (p *Player) ApplyStats() {
+	if p.Stats == nil {
+		return
+	}
+	p.MaxHealth = int(p.Stats.Value(HealthStat))
+	p.Health = min(p.Health, p.MaxHealth)
+	p.MaxStamina = p.Stats.Value(StaminaStat)
+	p.Stamina = math.Min(p.Stamina, p.MaxStamina)
+}
+
+// Spread is the maximum angle in radians a shot can stray,
+// perfect accuracy (100) shoots dead straight and so do npcs without a stat block.
+func (p *Player) Spread() float64 {
+	if p.Stats == nil {
+		return 0
+	}
+	maxSpread := 0.35
+	return maxSpread * math.Max(0, 100-p.Stat(AccuracyStat)) / 100
+}
+
+// ReloadDuration is the number of frames a full reload takes.
+func (p *Player) ReloadDuration() int {
+	baseDuration := 90.0
+	return int(baseDuration * 50 / math.Max(10, p.Stat(ReloadSpeedStat)))
+}
@@ -0,0 +1,221 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/bramca/Far-West/bounty"
+	"github.com/bramca/Far-West/menu"
+	"github.com/bramca/Far-West/world"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// boardSize is the number of contracts on the bounty board
+	boardSize = 3
+	// targets hide out between these distances from the board
+	bountyNear = 600.0
+	bountyFar  = 2400.0
+	// the gang of a target stands around it this far out
+	gangSpread = 80.0
+)
+
+var targetNameColor = color.RGBA{255, 90, 60, 240}
+
+// newBountyBoard puts the bounty board left of the shops, with the contracts of a random region.
+func (g *Game) newBountyBoard(x, y float64) {
+	region, err := g.bounties.Region(g.rand, "")
+	if err != nil {
+		panic(err)
+	}
+	g.bountyBoard = world.NewBountyBoard(x, y, text.NewGoXFace(g.hitTextFont))
+	g.board = bounty.NewBoard(g.rand, g.bounties, region, boardSize, x, y, bountyNear, bountyFar)
+	g.bountyBoard.Posters = len(g.board.Contracts)
+	g.target = nil
+}
+
+func (g *Game) openBounties() {
+	g.scenes.Push(&bountyScene{g: g})
+}
+
+// acceptContract takes the contract and sends the target and its gang out to where it was last seen.
+func (g *Game) acceptContract(c *bounty.Contract) error {
+	if err := g.board.Accept(c); err != nil {
+		return err
+	}
+	g.bountyBoard.Posters = len(g.board.Contracts)
+
+	sheet := g.sprites.Enemies[g.rand.Intn(len(g.sprites.Enemies))]
+	g.target = g.newEnemy(c.X, c.Y, sheet)
+	g.target.Health, g.target.MaxHealth = c.Health, c.Health
+	g.target.Healthbar.Update(g.target.Healthbar.X, g.target.Healthbar.Y, g.target.Health, g.target.MaxHealth)
+	g.enemies = append(g.enemies, g.target)
+	for i := range c.Gang {
+		angle := 2 * math.Pi * float64(i) / float64(c.Gang)
+		x, y := c.X+math.Cos(angle)*gangSpread, c.Y+math.Sin(angle)*gangSpread
+		g.enemies = append(g.enemies, g.newEnemy(x, y, g.sprites.Enemies[g.rand.Intn(len(g.sprites.Enemies))]))
+	}
+
+	g.objective = &objective{Name: c.Name, X: c.X, Y: c.Y}
+	g.notify("hunt down %s", c.Name)
+
+	return nil
+}
+
+// abandonContract gives up the active contract, the target stays out there as any other outlaw.
+func (g *Game) abandonContract() error {
+	if err := g.board.Abandon(); err != nil {
+		return err
+	}
+	g.target = nil
+	g.objective = g.townObjective()
+	g.bountyBoard.Posters = len(g.board.Contracts)
+
+	return nil
+}
+
+// updateBounty keeps the compass on the target and pays out once it is dead.
+// A target brought in alive is paid out by jail, when the sheriff takes it.
+func (g *Game) updateBounty() {
+	if g.target == nil {
+		return
+	}
+	g.objective.X, g.objective.Y = g.target.Center()
+	if g.target.Dead {
+		g.collectBounty(false)
+	}
+}
+
+// collectBounty ends the active contract and gives the reward to the gang purse of player one.
+func (g *Game) collectBounty(alive bool) {
+	name, difficulty := g.board.Active.Name, g.board.Active.Difficulty
+	payout, err := g.board.Complete(alive)
+	if err != nil {
+		g.notify("%v", err)
+		return
+	}
+	g.player.Money += payout
+	g.target = nil
+	g.objective = g.townObjective()
+	g.bountyBoard.Posters = len(g.board.Contracts)
+	if alive {
+		g.notify("brought in %s, collected $%d", name, payout)
+		// the town thinks better of hunters who bring them in for a trial
+		g.changeReputation(difficulty + 1)
+		return
+	}
+	g.notify("%s is dead, collected $%d", name, payout)
+	g.changeReputation(difficulty)
+}
+
+// drawBountyTarget writes the name of the target over its head.
+func (g *Game) drawBountyTarget(screen *ebiten.Image) {
+	if g.target == nil || g.target.Dead {
+		return
+	}
+	drawOptions := &text.DrawOptions{}
+	drawOptions.ColorScale.ScaleWithColor(targetNameColor)
+	drawOptions.GeoM.Translate(g.target.X-g.camX-16, g.target.Y-g.camY-g.target.H-8)
+	text.Draw(screen, g.board.Active.Name, text.NewGoXFace(g.hitTextFont), drawOptions)
+}
+
+// bountyScene shows the contracts on the bounty board over the frozen world.
+type bountyScene struct {
+	g     *Game
+	menus *menu.Navigator
+}
+
+func (s *bountyScene) Enter() {
+	g := s.g
+	s.menus = &menu.Navigator{
+		TitleFace:  text.NewGoXFace(g.titleArcadeFont),
+		Face:       text.NewGoXFace(g.arcadeFont),
+		InfoFace:   text.NewGoXFace(g.shopFont),
+		Center:     0.5,
+		Top:        100,
+		LineHeight: 2 * float64(g.fontSize),
+	}
+	s.menus.Open(s.boardMenu())
+}
+
+func (s *bountyScene) boardMenu() *menu.Menu {
+	g := s.g
+	m := &menu.Menu{
+		Title:  "Bounties of " + g.board.Region.Name,
+		OnBack: g.scenes.Pop,
+	}
+	if active := g.board.Active; active != nil {
+		m.Items = append(m.Items,
+			&menu.Item{Label: "hunting " + active.Name},
+			&menu.Item{Label: "abandon", Action: func() {
+				if err := g.abandonContract(); err != nil {
+					g.notify("%v", err)
+				}
+				g.scenes.Pop()
+			}},
+		)
+	}
+	for _, c := range g.board.Contracts {
+		m.Items = append(m.Items, &menu.Item{
+			Label:   c.Name,
+			Value:   func() string { return fmt.Sprintf("%s $%d", c.Stars(), c.Reward) },
+			Submenu: s.contractMenu(c),
+		})
+	}
+	m.Items = append(m.Items, &menu.Item{Label: "back", Action: g.scenes.Pop})
+
+	return m
+}
+
+// contractMenu is the wanted poster of a contract.
+func (s *bountyScene) contractMenu(c *bounty.Contract) *menu.Menu {
+	g := s.g
+	m := &menu.Menu{Title: "Wanted " + c.Terms.String()}
+	m.Items = append(m.Items, &menu.Item{Label: c.Name})
+	for _, sentence := range strings.SplitAfter(c.Description, ". ") {
+		m.Items = append(m.Items, &menu.Item{Label: strings.TrimSpace(sentence)})
+	}
+	m.Items = append(m.Items,
+		&menu.Item{Label: "difficulty " + c.Stars()},
+		&menu.Item{Label: fmt.Sprintf("reward $%d", c.Reward)},
+	)
+	if alive := c.Payout(true); alive != c.Reward {
+		m.Items = append(m.Items, &menu.Item{Label: fmt.Sprintf("$%d when brought in alive", alive)})
+	}
+	m.Items = append(m.Items,
+		&menu.Item{
+			Label: "accept",
+			Action: func() {
+				if err := g.acceptContract(c); err != nil {
+					g.notify("%v", err)
+				}
+				g.scenes.Pop()
+			},
+			Disabled: func() bool { return g.board.Active != nil },
+		},
+		&menu.Item{Label: "back", Back: true},
+	)
+
+	return m
+}
+
+func (s *bountyScene) Exit() {}
+
+func (s *bountyScene) Overlay() bool {
+	return true
+}
+
+func (s *bountyScene) Update() error {
+	s.menus.Update(s.g.menuInput(s.g.buttonsJustPressed))
+
+	return nil
+}
+
+func (s *bountyScene) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.NRGBA{0, 0, 0, 160}, false)
+	s.menus.Draw(screen)
+}
@@ -0,0 +1,264 @@
+// Package bounty generates the contracts on the bounty board of a region and works out what they pay.
+package bounty
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const (
+	// AliveBonus is what a target brought in alive pays on top, as a part of the reward
+	AliveBonus = 0.5
+	// MaxDifficulty is the number of stars of the hardest contracts
+	MaxDifficulty = 3
+)
+
+var (
+	ErrContractActive = errors.New("finish the contract you have first")
+	ErrNoContract     = errors.New("no contract taken")
+	ErrUnknownRegion  = errors.New("unknown region")
+)
+
+// Terms say in what state the target has to be brought in.
+type Terms int
+
+const (
+	DeadOrAlive Terms = iota
+	// Dead targets are too dangerous to be brought in alive
+	Dead
+)
+
+func (t Terms) String() string {
+	if t == Dead {
+		return "dead"
+	}
+
+	return "dead or alive"
+}
+
+// Region is an area around a town with its own outlaws.
+type Region struct {
+	Name string `json:"name"`
+	// Danger from 1 to MaxDifficulty makes the targets tougher and the rewards bigger
+	Danger int `json:"danger"`
+}
+
+// Contract is a target on the bounty board.
+type Contract struct {
+	Name        string
+	Description string
+	// Difficulty goes from 1 to MaxDifficulty stars
+	Difficulty int
+	Reward     int
+	Terms      Terms
+	// X, Y is where the target was last seen
+	X, Y float64
+	// Health of the target and the number of outlaws riding with it
+	Health int
+	Gang   int
+}
+
+// Stars shows the difficulty as stars out of MaxDifficulty.
+func (c *Contract) Stars() string {
+	return strings.Repeat("*", c.Difficulty) + strings.Repeat("-", MaxDifficulty-c.Difficulty)
+}
+
+// Payout is what the contract pays for the target, alive pays more when the terms allow it.
+func (c *Contract) Payout(alive bool) int {
+	if alive && c.Terms == DeadOrAlive {
+		return c.Reward + int(math.Round(float64(c.Reward)*AliveBonus))
+	}
+
+	return c.Reward
+}
+
+// Data are the regions and the pieces outlaws are made of.
+type Data struct {
+	Regions    []Region `json:"regions"`
+	FirstNames []string `json:"firstNames"`
+	Nicknames  []string `json:"nicknames"`
+	LastNames  []string `json:"lastNames"`
+	Crimes     []string `json:"crimes"`
+}
+
+func Load(fsys fs.FS, path string) (*Data, error) {
+	raw, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bounties: %w", err)
+	}
+
+	data := &Data{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to parse bounties %s: %w", path, err)
+	}
+	for name, list := range map[string]int{
+		"regions":    len(data.Regions),
+		"firstNames": len(data.FirstNames),
+		"lastNames":  len(data.LastNames),
+		"crimes":     len(data.Crimes),
+	} {
+		if list == 0 {
+			return nil, fmt.Errorf("bounties %s needs at least one of %s", path, name)
+		}
+	}
+	for _, region := range data.Regions {
+		if region.Danger < 1 || region.Danger > MaxDifficulty {
+			return nil, fmt.Errorf("region %q in %s needs a danger from 1 to %d", region.Name, path, MaxDifficulty)
+		}
+	}
+
+	return data, nil
+}
+
+// Region picks a region by name, or a random one from r without a name.
+func (d *Data) Region(r *rand.Rand, name string) (Region, error) {
+	if name == "" {
+		return d.Regions[r.Intn(len(d.Regions))], nil
+	}
+	for _, region := range d.Regions {
+		if strings.EqualFold(region.Name, name) {
+			return region, nil
+		}
+	}
+
+	return Region{}, ErrUnknownRegion
+}
+
+func pick(r *rand.Rand, list []string) string {
+	return list[r.Intn(len(list))]
+}
+
+// outlawName is a first and last name, with a nickname between them now and then.
+func (d *Data) outlawName(r *rand.Rand) string {
+	if len(d.Nicknames) > 0 && r.Float64() < 0.4 {
+		return fmt.Sprintf("%s '%s' %s", pick(r, d.FirstNames), pick(r, d.Nicknames), pick(r, d.LastNames))
+	}
+
+	return pick(r, d.FirstNames) + " " + pick(r, d.LastNames)
+}
+
+// heading is the direction from the town to x, y in words.
+func heading(dx, dy float64) string {
+	headings := []string{"east", "south east", "south", "south west", "west", "north west", "north", "north east"}
+	octant := int(math.Round(math.Atan2(dy, dx)/(math.Pi/4))+8) % 8
+
+	return headings[octant]
+}
+
+// Generate makes a contract for the region, hiding out somewhere between near and far from the town at x, y.
+// Harder targets hide further out and a dangerous region makes them harder, the dice are rolled with r.
+func (d *Data) Generate(r *rand.Rand, region Region, x, y, near, far float64) *Contract {
+	difficulty := min(MaxDifficulty, 1+r.Intn(MaxDifficulty-1)+r.Intn(region.Danger))
+	terms := DeadOrAlive
+	if r.Float64() < 0.25 {
+		terms = Dead
+	}
+	angle := r.Float64() * 2 * math.Pi
+	distance := near + (far-near)*(float64(difficulty)-r.Float64())/MaxDifficulty
+	dx, dy := math.Cos(angle)*distance, math.Sin(angle)*distance
+
+	c := &Contract{
+		Name:       d.outlawName(r),
+		Difficulty: difficulty,
+		Terms:      terms,
+		X:          x + dx,
+		Y:          y + dy,
+		Health:     10 + 10*difficulty,
+		Gang:       difficulty - 1 + r.Intn(region.Danger),
+	}
+	// rewards are rounded to five dollars, like on a real poster
+	reward := float64(50*difficulty+r.Intn(30)) * (1 + 0.25*float64(region.Danger-1))
+	c.Reward = 5 * int(math.Round(reward/5))
+	c.Description = fmt.Sprintf("Wanted for %s. Last seen %s of town", pick(r, d.Crimes), heading(dx, dy))
+	switch {
+	case c.Gang == 1:
+		c.Description += ", riding with another outlaw"
+	case c.Gang > 1:
+		c.Description += fmt.Sprintf(", riding with %d outlaws", c.Gang)
+	}
+	c.Description += "."
+
+	return c
+}
+
+// Board is the bounty board of a region, it offers a few contracts and one can be taken at a time.
+type Board struct {
+	Region    Region
+	Contracts []*Contract
+	// Active is the contract being worked on, it is no longer offered
+	Active *Contract
+
+	// rand rolls the contracts that go up on the board
+	rand *rand.Rand
+	data *Data
+	// the town the targets hide around
+	x, y, near, far float64
+	size            int
+}
+
+// NewBoard offers size contracts of the region, hiding around the town at x, y.
+func NewBoard(r *rand.Rand, data *Data, region Region, size int, x, y, near, far float64) *Board {
+	b := &Board{
+		Region: region,
+		rand:   r,
+		data:   data,
+		x:      x,
+		y:      y,
+		near:   near,
+		far:    far,
+		size:   size,
+	}
+	b.fill()
+
+	return b
+}
+
+func (b *Board) fill() {
+	for len(b.Contracts) < b.size {
+		b.Contracts = append(b.Contracts, b.data.Generate(b.rand, b.Region, b.x, b.y, b.near, b.far))
+	}
+}
+
+// Accept takes the contract off the board and makes it the active one.
+func (b *Board) Accept(c *Contract) error {
+	if b.Active != nil {
+		return ErrContractActive
+	}
+	for i, offered := range b.Contracts {
+		if offered == c {
+			b.Contracts = append(b.Contracts[:i], b.Contracts[i+1:]...)
+			b.Active = c
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not on the board", c.Name)
+}
+
+// Complete ends the active contract and returns what it pays, a new contract goes up on the board.
+func (b *Board) Complete(alive bool) (int, error) {
+	if b.Active == nil {
+		return 0, ErrNoContract
+	}
+	payout := b.Active.Payout(alive)
+	b.Active = nil
+	b.fill()
+
+	return payout, nil
+}
+
+// Abandon gives up the active contract, it is gone for good.
+func (b *Board) Abandon() error {
+	if b.Active == nil {
+		return ErrNoContract
+	}
+	b.Active = nil
+	b.fill()
+
+	return nil
+}
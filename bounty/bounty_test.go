@@ -0,0 +1,206 @@
+package bounty
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var testData = &Data{
+	Regions:    []Region{{Name: "Dry Gulch", Danger: 1}, {Name: "Coyote Basin", Danger: 2}, {Name: "Devil's Canyon", Danger: 3}},
+	FirstNames: []string{"Jesse", "Belle"},
+	Nicknames:  []string{"the Kid"},
+	LastNames:  []string{"Malone", "Starr"},
+	Crimes:     []string{"cattle rustling"},
+}
+
+func TestGenerate(t *testing.T) {
+	const x, y, near, far = 100.0, -50.0, 500.0, 2000.0
+	for _, region := range testData.Regions {
+		t.Run(region.Name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for range 200 {
+				c := testData.Generate(r, region, x, y, near, far)
+				if c.Difficulty < 1 || c.Difficulty > MaxDifficulty {
+					t.Fatalf("difficulty %d out of 1 to %d", c.Difficulty, MaxDifficulty)
+				}
+				if c.Health != 10+10*c.Difficulty {
+					t.Fatalf("health %d for difficulty %d", c.Health, c.Difficulty)
+				}
+				if c.Gang < c.Difficulty-1 || c.Gang > c.Difficulty-1+region.Danger-1 {
+					t.Fatalf("gang of %d for difficulty %d in danger %d", c.Gang, c.Difficulty, region.Danger)
+				}
+				if c.Reward <= 0 || c.Reward%5 != 0 {
+					t.Fatalf("reward $%d is not a positive multiple of five", c.Reward)
+				}
+				if distance := math.Hypot(c.X-x, c.Y-y); distance < near || distance > far {
+					t.Fatalf("hides %.0f from town, not between %.0f and %.0f", distance, near, far)
+				}
+				if c.Name == "" || !strings.HasSuffix(c.Description, ".") {
+					t.Fatalf("name %q, description %q", c.Name, c.Description)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSameSeed(t *testing.T) {
+	a := testData.Generate(rand.New(rand.NewSource(7)), testData.Regions[1], 0, 0, 100, 200)
+	b := testData.Generate(rand.New(rand.NewSource(7)), testData.Regions[1], 0, 0, 100, 200)
+	if *a != *b {
+		t.Fatalf("same seed made %+v and %+v", a, b)
+	}
+}
+
+func TestPayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		reward int
+		terms  Terms
+		alive  bool
+		want   int
+	}{
+		{"dead or alive, dead", 100, DeadOrAlive, false, 100},
+		{"dead or alive, alive", 100, DeadOrAlive, true, 150},
+		{"dead, dead", 100, Dead, false, 100},
+		{"dead, alive", 100, Dead, true, 100},
+		{"bonus rounds", 55, DeadOrAlive, true, 83},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Contract{Reward: tt.reward, Terms: tt.terms}
+			if got := c.Payout(tt.alive); got != tt.want {
+				t.Errorf("Payout(%t) = %d, want %d", tt.alive, got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestBoard() *Board {
+	return NewBoard(rand.New(rand.NewSource(1)), testData, testData.Regions[0], 3, 0, 0, 100, 200)
+}
+
+func TestBoardAccept(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(b *Board) *Contract
+		wantErr error
+	}{
+		{"offered", func(b *Board) *Contract { return b.Contracts[1] }, nil},
+		{"another is active", func(b *Board) *Contract {
+			if err := b.Accept(b.Contracts[0]); err != nil {
+				t.Fatal(err)
+			}
+			return b.Contracts[0]
+		}, ErrContractActive},
+		{"not on the board", func(b *Board) *Contract { return &Contract{Name: "Nobody"} }, errors.New("Nobody is not on the board")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBoard()
+			c := tt.prepare(b)
+			offered := len(b.Contracts)
+			err := b.Accept(c)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Accept: %v", err)
+			case tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()):
+				t.Fatalf("Accept: %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(b.Contracts) != offered {
+					t.Errorf("a refused contract changed the board to %d contracts", len(b.Contracts))
+				}
+				return
+			}
+			if b.Active != c {
+				t.Errorf("active is %v, want the accepted contract", b.Active)
+			}
+			for _, offered := range b.Contracts {
+				if offered == c {
+					t.Errorf("the accepted contract is still offered")
+				}
+			}
+		})
+	}
+}
+
+func TestBoardComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  bool
+		alive   bool
+		wantErr error
+	}{
+		{"dead", true, false, nil},
+		{"alive", true, true, nil},
+		{"without a contract", false, false, ErrNoContract},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBoard()
+			var c *Contract
+			if tt.accept {
+				c = b.Contracts[0]
+				if err := b.Accept(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			payout, err := b.Complete(tt.alive)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Complete: %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := c.Payout(tt.alive); payout != want {
+				t.Errorf("paid $%d, want $%d", payout, want)
+			}
+			if b.Active != nil {
+				t.Errorf("the contract is still active")
+			}
+			if len(b.Contracts) != 3 {
+				t.Errorf("the board has %d contracts, want it filled up to 3", len(b.Contracts))
+			}
+		})
+	}
+}
+
+func TestBoardAbandon(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  bool
+		wantErr error
+	}{
+		{"active", true, nil},
+		{"without a contract", false, ErrNoContract},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBoard()
+			var c *Contract
+			if tt.accept {
+				c = b.Contracts[0]
+				if err := b.Accept(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := b.Abandon(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Abandon: %v, want %v", err, tt.wantErr)
+			}
+			if b.Active != nil {
+				t.Errorf("the contract is still active")
+			}
+			if len(b.Contracts) != 3 {
+				t.Errorf("the board has %d contracts, want 3", len(b.Contracts))
+			}
+			for _, offered := range b.Contracts {
+				if c != nil && offered == c {
+					t.Errorf("the abandoned contract went back on the board")
+				}
+			}
+		})
+	}
+}
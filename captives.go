@@ -0,0 +1,155 @@
+package farwest
+
+import (
+	"slices"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/bounty"
+	"github.com/bramca/Far-West/utils"
+	"github.com/bramca/Far-West/world"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	// ropeLength is how far behind the captor a tied outlaw walks
+	ropeLength = 44.0
+	// tieReach is how close a player has to be to tie up an outlaw or take its rope
+	tieReach = 60.0
+	// a roped outlaw wriggles out of the lasso after this many seconds unless it is tied up
+	ropeStruggleSeconds = 5
+	// a tied outlaw left alone gets its hands free after this many seconds
+	captiveStruggleSeconds = 15
+	// captiveReward is what the sheriff pays for an outlaw without a contract on them
+	captiveReward = 20
+)
+
+// newSheriff puts the sheriff next to the bounty board, outlaws brought to the sheriff alive go to jail.
+func (g *Game) newSheriff(x, y float64) {
+	sprites := g.sprites.Sheet("player").Images()
+	g.sheriff = world.NewNPC("Sheriff", x, y, sprites[actors.PlayerRevolverRight], text.NewGoXFace(g.hitTextFont))
+	g.sheriff.Dialogue = "sheriff"
+	// the sheriff wears a golden tint, like the star
+	g.sheriff.DrawOptions.ColorScale.Scale(1.2, 1.05, 0.6, 1)
+}
+
+// outlawName is the name of the target of the contract, or a plain outlaw.
+func (g *Game) outlawName(enemy *actors.Enemy) string {
+	if enemy == g.target {
+		return g.board.Active.Name
+	}
+
+	return "an outlaw"
+}
+
+// updateLassos flies the thrown lassos, a loop that touches a weakened outlaw ropes it.
+func (g *Game) updateLassos() {
+	for _, p := range g.playerActors() {
+		p.UpdateLasso()
+		if p.Loop == nil || p.Loop.Returning {
+			continue
+		}
+		for _, cactus := range g.cacti {
+			if p.Loop.Catches(cactus.Hitbox) {
+				p.Loop.Returning = true
+			}
+		}
+		for _, enemy := range g.enemies {
+			if p.Loop.Returning || enemy.Dead || enemy.Capture != actors.Free || !p.Loop.Catches(enemy.Hitbox) {
+				continue
+			}
+			p.Loop.Returning = true
+			if enemy == g.target && g.board.Active.Terms == bounty.Dead {
+				g.notify("%s is wanted dead, the sheriff won't jail them", g.board.Active.Name)
+				continue
+			}
+			if !enemy.Weakened() {
+				g.notify("too lively to rope, wear them down first")
+				continue
+			}
+			enemy.Rope(ropeStruggleSeconds * g.framesPerSecond)
+			g.notify("roped %s, tie them up", g.outlawName(enemy))
+		}
+	}
+}
+
+// updateCaptives moves the roped and tied outlaws and jails the ones led to the sheriff.
+func (g *Game) updateCaptives() {
+	for i := len(g.enemies) - 1; i >= 0; i-- {
+		enemy := g.enemies[i]
+		if enemy.Capture == actors.Free {
+			continue
+		}
+		if enemy.Dead {
+			enemy.Escape()
+			continue
+		}
+		// a captor who went down or left drops the rope
+		if enemy.Captor != nil && (enemy.Captor.Dead || !slices.Contains(g.playerActors(), enemy.Captor)) {
+			enemy.LetGo(captiveStruggleSeconds * g.framesPerSecond)
+		}
+		if enemy.Capture == actors.Tied && enemy.Captor != nil && g.sheriff.InRange(enemy.Captor) {
+			g.jail(i)
+			continue
+		}
+		if enemy.ActCaptive(ropeLength) {
+			g.notify("%s got loose", g.outlawName(enemy))
+		}
+	}
+}
+
+// jail hands the outlaw over to the sheriff, who pays the contract or a small reward.
+func (g *Game) jail(index int) {
+	enemy := g.enemies[index]
+	g.enemies = append(g.enemies[:index], g.enemies[index+1:]...)
+	if enemy == g.target {
+		g.collectBounty(true)
+		return
+	}
+	g.player.Money += captiveReward
+	g.notify("the sheriff paid $%d for an outlaw", captiveReward)
+	g.changeReputation(1)
+}
+
+// interactCaptive ties up a roped outlaw within reach, or takes or drops the rope of a tied one.
+// It returns false when there is no captive within reach.
+func (g *Game) interactCaptive(cp *coopPlayer) bool {
+	for _, enemy := range g.enemies {
+		if enemy.Dead || enemy.Capture == actors.Free {
+			continue
+		}
+		x, y := enemy.Center()
+		if utils.DistanceBetweenPoints(cp.X, cp.Y, x, y) > tieReach {
+			continue
+		}
+		switch {
+		case enemy.Capture == actors.Roped:
+			enemy.TieUp(cp.Player)
+			g.notify("tied up %s, bring them to the sheriff", g.outlawName(enemy))
+		case enemy.Captor == cp.Player:
+			enemy.LetGo(captiveStruggleSeconds * g.framesPerSecond)
+			g.notify("let go of the rope")
+		case enemy.Captor == nil:
+			enemy.TieUp(cp.Player)
+			g.notify("took the rope of %s", g.outlawName(enemy))
+		default:
+			// a partner is leading this one
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// drawLassos draws the thrown lassos and the ropes the captives are led on.
+func (g *Game) drawLassos(screen *ebiten.Image) {
+	for _, enemy := range g.enemies {
+		enemy.DrawRope(screen, g.camX, g.camY)
+	}
+	for _, p := range g.playerActors() {
+		if p.Loop != nil {
+			p.Loop.Draw(screen, p.X, p.Y, g.camX, g.camY)
+		}
+	}
+}
@@ -1,20 +1,41 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	farwest "github.com/bramca/Far-West"
+	"github.com/bramca/Far-West/netplay"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	host := flag.String("host", "", "host a network game on this address, like :7777")
+	connect := flag.String("connect", "", "join the network game hosted at this address")
+	latency := flag.Duration("latency", 0, "delay every packet this long, to try network play on one machine")
+	jitter := flag.Duration("jitter", 0, "delay every packet up to this much longer at random")
+	loss := flag.Float64("loss", 0, "drop this fraction of the packets, from 0 to 1")
+	flag.Parse()
+
+	// the window size comes from the settings of the player
 	game := farwest.NewGame()
-	// Sepcify the window size as you like. Here, a doulbed size is specified.
-	ebiten.SetWindowSize(farwest.ScreenWidth, farwest.ScreenHeight)
 	ebiten.SetWindowTitle("Far West")
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	// ebiten.SetCursorMode(ebiten.CursorModeHidden)
 
+	game.SimulateNetwork(netplay.Link{Latency: *latency, Jitter: *jitter, Loss: *loss})
+	switch {
+	case *host != "":
+		if err := game.Host(*host); err != nil {
+			log.Fatal(err)
+		}
+	case *connect != "":
+		if err := game.Connect(*connect); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Call ebiten.RunGame to start your game loop.
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
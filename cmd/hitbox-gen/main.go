@@ -0,0 +1,93 @@
+// Command hitbox-gen prints the tight hitbox of every frame of a sprite,
+// in the format of the frames of a sheet in the sprite manifest.
+//
+//	go run ./cmd/hitbox-gen piskel/cactus.piskel
+//	go run ./cmd/hitbox-gen -frame-width 32 -frame-height 32 assets/cactus.png
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bramca/Far-West/hitmask"
+	"github.com/bramca/Far-West/piskel"
+)
+
+type hitbox struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type frame struct {
+	Hitbox hitbox `json:"hitbox"`
+}
+
+func main() {
+	frameWidth := flag.Int("frame-width", 32, "frame width of png sprite sheets")
+	frameHeight := flag.Int("frame-height", 32, "frame height of png sprite sheets")
+	threshold := flag.Int("threshold", hitmask.DefaultThreshold, "alpha from which a pixel is solid")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: hitbox-gen [flags] sprite.piskel|sprite.png ...")
+	}
+
+	for _, path := range flag.Args() {
+		frames, err := loadFrames(path, *frameWidth, *frameHeight)
+		if err != nil {
+			log.Fatal(err)
+		}
+		result := map[int]frame{}
+		for i, img := range frames {
+			bounds := hitmask.Bounds(img, uint8(*threshold))
+			if bounds.Empty() {
+				continue
+			}
+			result[i] = frame{Hitbox: hitbox{X: bounds.Min.X, Y: bounds.Min.Y, W: bounds.Dx(), H: bounds.Dy()}}
+		}
+		out, err := json.MarshalIndent(map[string]any{"frames": result}, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s:\n%s\n", path, out)
+	}
+}
+
+func loadFrames(path string, frameWidth, frameHeight int) ([]image.Image, error) {
+	if filepath.Ext(path) == ".piskel" {
+		file, err := piskel.Load(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		return file.Frames, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	sheet := image.NewNRGBA(img.Bounds())
+	draw.Draw(sheet, sheet.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	frames := []image.Image{}
+	for y := 0; y+frameHeight <= sheet.Bounds().Dy(); y += frameHeight {
+		for x := 0; x+frameWidth <= sheet.Bounds().Dx(); x += frameWidth {
+			frames = append(frames, sheet.SubImage(image.Rect(x, y, x+frameWidth, y+frameHeight)))
+		}
+	}
+
+	return frames, nil
+}
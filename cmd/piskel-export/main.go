@@ -0,0 +1,57 @@
+// Command piskel-export writes the frames of piskel files to png sprite sheets.
+//
+// Without arguments every piskel in the piskel directory is exported to assets.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bramca/Far-West/piskel"
+)
+
+func main() {
+	outDir := flag.String("out", "assets", "directory the sprite sheets are written to")
+	columns := flag.Int("columns", 0, "frames per row, 0 puts all frames on one row")
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		var err error
+		files, err = filepath.Glob("piskel/*.piskel")
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, path := range files {
+		out := filepath.Join(*outDir, strings.TrimSuffix(filepath.Base(path), ".piskel")+".png")
+		if err := export(path, out, *columns); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func export(path string, out string, columns int) error {
+	file, err := piskel.Load(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create sprite sheet: %w", err)
+	}
+	if err := png.Encode(f, file.SpriteSheet(columns)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write sprite sheet %s: %w", out, err)
+	}
+	fmt.Printf("%s: %d frames at %d fps -> %s\n", path, len(file.Frames), file.FPS, out)
+
+	return f.Close()
+}
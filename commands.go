@@ -0,0 +1,515 @@
+package farwest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bramca/Far-West/console"
+	"github.com/bramca/Far-West/helpers"
+	"github.com/bramca/Far-West/netplay"
+	"github.com/bramca/Far-West/settings"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// startupScript is run from the config directory when the game starts, if it is there
+	startupScript = "startup.cfg"
+	maxTimeScale  = 8
+	maxSpawn      = 50
+)
+
+// consoleScene shows the developer console over the frozen game.
+type consoleScene struct {
+	g *Game
+}
+
+func (s *consoleScene) Enter() {
+	s.g.console.Open()
+}
+
+func (s *consoleScene) Exit() {
+	s.g.console.Close()
+}
+
+func (s *consoleScene) Overlay() bool {
+	return true
+}
+
+// Update rolls the console up before the scene leaves.
+func (s *consoleScene) Update() error {
+	s.g.console.Update()
+	if !s.g.console.Visible() {
+		s.g.scenes.Pop()
+	}
+
+	return nil
+}
+
+func (s *consoleScene) Draw(screen *ebiten.Image) {
+	s.g.console.Draw(screen)
+}
+
+// updateConsole drops the console down over any scene.
+func (g *Game) updateConsole() {
+	if _, open := g.scenes.Top().(*consoleScene); !open && !g.scenes.Transitioning() && console.Toggled() {
+		g.scenes.Push(&consoleScene{g: g})
+	}
+}
+
+func (g *Game) runStartupScript() {
+	dir, err := settings.Dir()
+	if err != nil {
+		return
+	}
+	err = g.console.RunFile(filepath.Join(dir, startupScript))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("console: %v", err)
+	}
+}
+
+// cursorInWorld is where the mouse points in the world.
+func (g *Game) cursorInWorld() (float64, float64) {
+	x, y := g.viewport.Cursor()
+
+	return x + g.camX, y + g.camY
+}
+
+func parseAmount(args []string, index int, fallback int) (int, error) {
+	if len(args) <= index {
+		return fallback, nil
+	}
+	amount, err := strconv.Atoi(args[index])
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", args[index])
+	}
+
+	return amount, nil
+}
+
+// spawnTypes are the enemy sheets, the gang and horses.
+func (g *Game) spawnTypes() []string {
+	types := []string{"gang", "horse"}
+	for _, sheet := range g.sprites.Enemies {
+		types = append(types, sheet.Name)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func (g *Game) itemIDs() []string {
+	ids := []string{"money"}
+	for id := range g.catalog {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (g *Game) dialogueIDs() []string {
+	ids := []string{}
+	for id := range g.dialogues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (g *Game) registerCommands() {
+	c := g.console
+	c.Register(&console.Command{
+		Name:  "spawn",
+		Usage: "spawn <type> [count]",
+		Help:  "spawns enemies of a sheet, gang members or horses at the cursor",
+		Args: func(index int) []string {
+			if index == 0 {
+				return g.spawnTypes()
+			}
+			return nil
+		},
+		Run: g.spawnCommand,
+	})
+	c.Register(&console.Command{
+		Name:  "god",
+		Usage: "god",
+		Help:  "toggles god mode, the players can not lose health",
+		Run: func(args []string) (string, error) {
+			g.god = !g.god
+			if g.god {
+				return "god mode on", nil
+			}
+			return "god mode off", nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "give",
+		Usage: "give <item|money> [amount]",
+		Help:  "gives the player items, ammo or money",
+		Args: func(index int) []string {
+			if index == 0 {
+				return g.itemIDs()
+			}
+			return nil
+		},
+		Run: g.giveCommand,
+	})
+	c.Register(&console.Command{
+		Name:  "teleport",
+		Usage: "teleport <x> <y|cursor|objective>",
+		Help:  "moves the players to a position, the cursor or the objective",
+		Args: func(index int) []string {
+			if index == 0 {
+				return []string{"cursor", "objective"}
+			}
+			return nil
+		},
+		Run: g.teleportCommand,
+	})
+	c.Register(&console.Command{
+		Name:  "bounty",
+		Usage: "bounty [number|abandon]",
+		Help:  "lists the contracts on the bounty board, takes one by its number or abandons the active one",
+		Args: func(index int) []string {
+			if index == 0 {
+				return []string{"abandon"}
+			}
+			return nil
+		},
+		Run: g.bountyCommand,
+	})
+	c.Register(&console.Command{
+		Name:  "talk",
+		Usage: "talk <dialogue>",
+		Help:  "starts a dialogue as if player one talked to its npc",
+		Args: func(index int) []string {
+			if index == 0 {
+				return g.dialogueIDs()
+			}
+			return nil
+		},
+		Run: func(args []string) (string, error) {
+			if g.isClient() {
+				return "", errNotHost
+			}
+			if len(args) == 0 {
+				return "", fmt.Errorf("which dialogue? %s", strings.Join(g.dialogueIDs(), ", "))
+			}
+			if err := g.talk(args[0], args[0], nil); err != nil {
+				return "", err
+			}
+			return "talking to " + args[0], nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "reputation",
+		Usage: "reputation [amount]",
+		Help:  "shows the reputation of player one in town or changes it by the amount",
+		Run: func(args []string) (string, error) {
+			if len(args) > 0 {
+				amount, err := strconv.Atoi(args[0])
+				if err != nil {
+					return "", fmt.Errorf("%q is not a number", args[0])
+				}
+				g.changeReputation(amount)
+			}
+			return fmt.Sprintf("reputation %d", g.player.Reputation), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "timescale",
+		Usage: "timescale [scale]",
+		Help:  "shows or sets how fast the world runs, 1 is normal speed",
+		Run: func(args []string) (string, error) {
+			if len(args) == 0 {
+				return fmt.Sprintf("timescale %g", g.timeScale), nil
+			}
+			scale, err := strconv.ParseFloat(args[0], 64)
+			if err != nil || scale < 0 || scale > maxTimeScale {
+				return "", fmt.Errorf("the scale has to be a number from 0 to %d", maxTimeScale)
+			}
+			g.timeScale = scale
+			return fmt.Sprintf("timescale %g", scale), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "reseed",
+		Usage: "reseed [seed]",
+		Help:  "starts over in the world of the seed, a random one without it",
+		Run: func(args []string) (string, error) {
+			if g.isClient() {
+				return "", errNotHost
+			}
+			seed := rand.Int63()
+			if len(args) > 0 {
+				var err error
+				seed, err = strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return "", fmt.Errorf("%q is not a seed", args[0])
+				}
+			}
+			g.reseed(seed)
+			if g.host != nil {
+				// the clients build the new world once the next snapshot tells them the seed
+				g.host.Seed = seed
+			}
+			g.Initialize()
+			return fmt.Sprintf("world %d", seed), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "host",
+		Usage: "host [addr]",
+		Help:  "starts a fresh world others can join over the network, on " + defaultHostAddr + " without an address",
+		Run: func(args []string) (string, error) {
+			addr := defaultHostAddr
+			if len(args) > 0 {
+				addr = args[0]
+			}
+			if err := g.Host(addr); err != nil {
+				return "", err
+			}
+			return g.networkStatus(), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "connect",
+		Usage: "connect <addr>",
+		Help:  "joins the game hosted at the address, like 192.168.1.10:7777",
+		Run: func(args []string) (string, error) {
+			if len(args) == 0 {
+				return "", fmt.Errorf("usage: connect <addr>")
+			}
+			if err := g.Connect(args[0]); err != nil {
+				return "", err
+			}
+			return g.networkStatus(), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "disconnect",
+		Usage: "disconnect",
+		Help:  "leaves or closes the network game",
+		Run: func(args []string) (string, error) {
+			if g.host == nil && g.client == nil {
+				return "", errors.New("not in a network game")
+			}
+			g.openTitle()
+			return "disconnected", nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "netstatus",
+		Usage: "netstatus",
+		Help:  "tells whether this game hosts or joined a network game",
+		Run: func(args []string) (string, error) {
+			return g.networkStatus(), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "netsim",
+		Usage: "netsim <latency ms> [jitter ms] [loss %]",
+		Help:  "makes the network worse on purpose for the next host or connect, netsim 0 turns it off",
+		Run: func(args []string) (string, error) {
+			if len(args) == 0 {
+				return "", fmt.Errorf("usage: netsim <latency ms> [jitter ms] [loss %%]")
+			}
+			values := [3]float64{}
+			for i, arg := range args[:min(len(args), len(values))] {
+				value, err := strconv.ParseFloat(arg, 64)
+				if err != nil || value < 0 {
+					return "", fmt.Errorf("%q is not a number", arg)
+				}
+				values[i] = value
+			}
+			g.SimulateNetwork(netplay.Link{
+				Latency: time.Duration(values[0] * float64(time.Millisecond)),
+				Jitter:  time.Duration(values[1] * float64(time.Millisecond)),
+				Loss:    min(1, values[2]/100),
+			})
+			return fmt.Sprintf("latency %gms jitter %gms loss %g%%", values[0], values[1], values[2]), nil
+		},
+	})
+	c.Register(&console.Command{
+		Name:  "dump",
+		Usage: "dump [file]",
+		Help:  "prints the state of the world, or writes it to a file",
+		Run: func(args []string) (string, error) {
+			state := g.dumpState()
+			if len(args) == 0 {
+				return state, nil
+			}
+			if err := os.WriteFile(args[0], []byte(state+"\n"), 0o644); err != nil {
+				return "", err
+			}
+			return "wrote " + args[0], nil
+		},
+	})
+}
+
+func (g *Game) spawnCommand(args []string) (string, error) {
+	if g.isClient() {
+		return "", errNotHost
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("usage: spawn <type> [count]")
+	}
+	count, err := parseAmount(args, 1, 1)
+	if err != nil {
+		return "", err
+	}
+	count = max(1, min(count, maxSpawn))
+	x, y := g.cursorInWorld()
+	for i := range count {
+		// spread a group out so they do not stand on top of each other
+		offsetX, offsetY := float64(i%5)*40, float64(i/5)*40
+		switch args[0] {
+		case "gang":
+			g.companions = append(g.companions, g.newCompanion(x+offsetX-16, y+offsetY-16))
+		case "horse":
+			g.horses = append(g.horses, g.newHorse(x+offsetX, y+offsetY))
+		default:
+			sheet := g.enemySheet(args[0])
+			if sheet == nil {
+				return "", fmt.Errorf("unknown type %q, try one of %s", args[0], strings.Join(g.spawnTypes(), ", "))
+			}
+			g.enemies = append(g.enemies, g.newEnemy(x+offsetX-16, y+offsetY-16, sheet))
+		}
+	}
+
+	return fmt.Sprintf("spawned %d %s at %.0f %.0f", count, args[0], x, y), nil
+}
+
+func (g *Game) enemySheet(name string) *helpers.SpriteSheet {
+	for _, sheet := range g.sprites.Enemies {
+		if sheet.Name == name {
+			return sheet
+		}
+	}
+
+	return nil
+}
+
+func (g *Game) giveCommand(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("usage: give <item|money> [amount]")
+	}
+	amount, err := parseAmount(args, 1, 1)
+	if err != nil {
+		return "", err
+	}
+	if args[0] == "money" {
+		g.player.Money += amount
+		return fmt.Sprintf("$%d", g.player.Money), nil
+	}
+	item, ok := g.catalog[args[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown item %q", args[0])
+	}
+	if err := g.player.Inventory.Add(item, amount); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s x%d", item.Name, g.player.Inventory.Count(item.ID)), nil
+}
+
+func (g *Game) teleportCommand(args []string) (string, error) {
+	var x, y float64
+	switch {
+	case len(args) == 1 && args[0] == "cursor":
+		x, y = g.cursorInWorld()
+	case len(args) == 1 && args[0] == "objective":
+		if g.objective == nil {
+			return "", fmt.Errorf("there is no objective")
+		}
+		x, y = g.objective.X, g.objective.Y
+	case len(args) == 2:
+		var errX, errY error
+		x, errX = strconv.ParseFloat(args[0], 64)
+		y, errY = strconv.ParseFloat(args[1], 64)
+		if errX != nil || errY != nil {
+			return "", fmt.Errorf("usage: teleport <x> <y|cursor|objective>")
+		}
+	default:
+		return "", fmt.Errorf("usage: teleport <x> <y|cursor|objective>")
+	}
+	// the others land next to player one, so the camera does not pull them back
+	for i, cp := range g.players {
+		cp.X, cp.Y = x+float64(i)*playerSpacing, y
+		cp.UpdateHitbox()
+	}
+
+	return fmt.Sprintf("teleported to %.0f %.0f", x, y), nil
+}
+
+func (g *Game) bountyCommand(args []string) (string, error) {
+	if g.isClient() {
+		return "", errNotHost
+	}
+	if len(args) == 0 {
+		lines := []string{g.board.Region.Name}
+		if active := g.board.Active; active != nil {
+			lines = append(lines, fmt.Sprintf("hunting %s at %.0f %.0f", active.Name, g.objective.X, g.objective.Y))
+		}
+		for i, c := range g.board.Contracts {
+			lines = append(lines, fmt.Sprintf("%d %s %s $%d %s", i+1, c.Name, c.Stars(), c.Reward, c.Terms))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	if args[0] == "abandon" {
+		if err := g.abandonContract(); err != nil {
+			return "", err
+		}
+		return "abandoned", nil
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil || index < 1 || index > len(g.board.Contracts) {
+		return "", fmt.Errorf("%q is not a contract on the board", args[0])
+	}
+	c := g.board.Contracts[index-1]
+	if err := g.acceptContract(c); err != nil {
+		return "", err
+	}
+
+	return "hunting " + c.Name, nil
+}
+
+// dumpState describes everyone in the world, one per line.
+func (g *Game) dumpState() string {
+	lines := []string{
+		fmt.Sprintf("frame %d timescale %g god %t", g.frameCount, g.timeScale, g.god),
+	}
+	if active := g.board.Active; active != nil {
+		lines = append(lines, fmt.Sprintf("contract %s %s $%d target health %d/%d", active.Name, active.Terms, active.Reward, g.target.Health, g.target.MaxHealth))
+	}
+	for _, p := range g.players {
+		lines = append(lines, fmt.Sprintf("%s at %.0f %.0f health %d/%d down %t stamina %.0f ammo %d/%d money %d reputation %d", p.name, p.X, p.Y, p.Health, p.MaxHealth, p.Dead, p.Stamina, p.Ammo, p.CylinderSize, p.Money, p.Reputation))
+		for _, slot := range p.Inventory.Slots {
+			if slot == nil {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s x%d", slot.Item.ID, slot.Count))
+		}
+	}
+	for _, enemy := range g.enemies {
+		lines = append(lines, fmt.Sprintf("enemy %d at %.0f %.0f health %d/%d dead %t %s action %s %d", enemy.ID, enemy.X, enemy.Y, enemy.Health, enemy.MaxHealth, enemy.Dead, enemy.Capture, enemy.CurrentAction.Type, enemy.CurrentAction.Duration))
+	}
+	for i, companion := range g.companions {
+		lines = append(lines, fmt.Sprintf("gang %d at %.0f %.0f health %d/%d dead %t command %s", i, companion.X, companion.Y, companion.Health, companion.MaxHealth, companion.Dead, companion.Command))
+	}
+	for i, horse := range g.horses {
+		lines = append(lines, fmt.Sprintf("horse %d at %.0f %.0f health %d/%d ridden %t", i, horse.X, horse.Y, horse.Health, horse.MaxHealth, horse.Rider != nil))
+	}
+
+	return strings.Join(lines, "\n")
+}
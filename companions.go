@@ -0,0 +1,284 @@
+package farwest
+
+import (
+	"image/color"
+	"math/rand"
+	"strconv"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/economy"
+	"github.com/bramca/Far-West/hud"
+	"github.com/bramca/Far-West/items"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const maxGangSize = 3
+
+func (g *Game) newCompanion(x, y float64) *actors.Companion {
+	state := actors.PlayerRevolverRight
+	companion := &actors.Companion{
+		Player: &actors.Player{
+			X:              x,
+			Y:              y,
+			W:              float64(g.companionSprites[state].Bounds().Dx() - 5),
+			H:              float64(g.companionSprites[state].Bounds().Dy()),
+			Sprites:        g.companionSprites,
+			CurrentState:   state,
+			CurrentWeapon:  actors.Revolver,
+			Scale:          2,
+			BaseSpeed:      1.5,
+			AnimationSpeed: g.player.AnimationSpeed,
+			Animator:       actors.NewAnimator(g.sprites.Sheet("player").Animations),
+			DrawOptions:    &ebiten.DrawImageOptions{},
+			FireRate:       20 + rand.Intn(10),
+			BulletSprite:   g.bulletSprite,
+			Health:         15,
+			MaxHealth:      15,
+			IsNpc:          true,
+			MoveDirs:       map[actors.Direction]bool{},
+			Hitbox: &actors.HitBox{
+				X: float32(x) + 16,
+				Y: float32(y) + 16,
+				W: float32(g.companionSprites[state].Bounds().Dx() - 5),
+				H: float32(g.companionSprites[state].Bounds().Dy()),
+			},
+		},
+		Leader:     g.player,
+		FollowDist: 70 + rand.Float64()*40,
+		VisualDist: 350,
+	}
+	companion.UpdateSpeed()
+	// companions wear a greener tint so they stand out from the enemies
+	companion.DrawOptions.ColorScale.Scale(0.8, 1.1, 0.8, 1)
+	companion.Healthbar = &hud.ResourceBar{
+		X:          companion.X,
+		Y:          companion.Y - (companion.H - companion.H/3),
+		W:          companion.W + 5,
+		H:          enemyHealthBarSize,
+		Points:     companion.Health,
+		MaxPoints:  companion.MaxHealth,
+		FillColor:  g.gangHealthbarColors[0],
+		EmptyColor: g.gangHealthbarColors[1],
+		TextFont:   text.NewGoXFace(g.enemyHealthBarFont),
+		FontColor:  color.RGBA{0, 0, 0, 240},
+		FontSize:   g.enemyHealthBarFontSize,
+	}
+	companion.Healthbar.SetDrawOptions()
+
+	return companion
+}
+
+func (g *Game) livingCompanions() int {
+	alive := 0
+	for _, companion := range g.companions {
+		if !companion.Dead {
+			alive += 1
+		}
+	}
+
+	return alive
+}
+
+// recruit is called after paying for a hired gun at the saloon,
+// whether they actually join depends on the charisma of the player.
+func (g *Game) recruit(item *items.Item, shop *economy.Shop) string {
+	entry := shop.Entry(item.ID)
+	if g.livingCompanions() >= maxGangSize {
+		entry.Quantity += 1
+		g.player.Money += shop.BuyPrice(g.player, entry)
+		return "your gang is full"
+	}
+	if rand.Float64() > economy.RecruitChance(g.player) {
+		// the gunslinger stays in the saloon, the drinks are on you
+		entry.Quantity += 1
+		return item.Name + " turned you down"
+	}
+
+	companion := g.newCompanion(g.player.X-g.player.W, g.player.Y+g.player.H)
+	g.companions = append(g.companions, companion)
+	return item.Name + " joined your gang"
+}
+
+func (g *Game) nearestEnemy(x, y float64) *actors.Enemy {
+	var nearest *actors.Enemy
+	nearestDist := 0.0
+	for _, enemy := range g.enemies {
+		if enemy.Dead {
+			continue
+		}
+		dist := utils.DistanceBetweenPoints(x, y, enemy.X, enemy.Y)
+		if nearest == nil || dist < nearestDist {
+			nearest = enemy
+			nearestDist = dist
+		}
+	}
+
+	return nearest
+}
+
+// enemyTarget picks whoever is closest to the enemy, one of the players still standing or one of the gang.
+// Player one is the target when nobody else is left.
+func (g *Game) enemyTarget(enemy *actors.Enemy) *actors.Player {
+	target := g.player
+	targetDist := -1.0
+	candidates := []*actors.Player{}
+	for _, cp := range g.players {
+		candidates = append(candidates, cp.Player)
+	}
+	for _, companion := range g.companions {
+		candidates = append(candidates, companion.Player)
+	}
+	for _, candidate := range candidates {
+		if candidate.Dead {
+			continue
+		}
+		dist := utils.DistanceBetweenPoints(candidate.X, candidate.Y, enemy.X, enemy.Y)
+		if targetDist < 0 || dist < targetDist {
+			target = candidate
+			targetDist = dist
+		}
+	}
+
+	return target
+}
+
+func (g *Game) commandCompanions(in *playerInput) {
+	command := actors.Command(-1)
+	var target *actors.Enemy
+	switch {
+	case in.keyJustPressed(ebiten.KeyG) || in.buttonsJustPressed["LT"]:
+		command = actors.FollowCommand
+	case in.keyJustPressed(ebiten.KeyH) || in.buttonsJustPressed["LB"]:
+		command = actors.HoldCommand
+	case in.keyJustPressed(ebiten.KeyT) || in.buttonsJustPressed["LL"]:
+		command = actors.AttackCommand
+		target = g.nearestEnemy(g.player.X, g.player.Y)
+	}
+	if command < 0 {
+		return
+	}
+	for _, companion := range g.companions {
+		if !companion.Dead {
+			companion.Order(command, target)
+		}
+	}
+}
+
+func (g *Game) updateCompanions() {
+	for _, companion := range g.companions {
+		companion.MoveDirs = map[actors.Direction]bool{
+			actors.Up:    false,
+			actors.Down:  false,
+			actors.Right: false,
+			actors.Left:  false,
+		}
+		companion.UpdateBullets()
+		if companion.Dead {
+			continue
+		}
+		companion.ThinkAndAct(g.enemies, g.frameCount)
+	}
+}
+
+func (g *Game) addHit(p *actors.Player, damage int) {
+	hit := actors.Hit{
+		X:        p.X,
+		Y:        p.Y - p.H/2,
+		Color:    color.RGBA{255, 255, 255, 240},
+		Msg:      "-" + strconv.Itoa(damage),
+		TextFont: text.NewGoXFace(g.hitTextFont),
+		Duration: 2 * g.framesPerSecond / 3,
+	}
+	hit.SetDrawOptions()
+	p.Hits = append(p.Hits, hit)
+}
+
+// checkCompanionCollisions only lets gang bullets hurt enemies,
+// the gang never hits the player or each other.
+func (g *Game) checkCompanionCollisions() {
+	for _, companion := range g.companions {
+		for i := len(companion.Bullets) - 1; i >= 0; i-- {
+			bullet := companion.Bullets[i]
+			hit := false
+			for _, cactus := range g.cacti {
+				if bullet.Hitbox.CheckPixelCollision(cactus.Hitbox) {
+					cactus.Damage(bullet.Damage)
+					g.bulletImpact(bullet, cactus)
+					hit = true
+					break
+				}
+			}
+			for _, enemy := range g.enemies {
+				if hit {
+					break
+				}
+				if !enemy.Dead && bullet.Hitbox.CheckCollision(enemy.Hitbox) {
+					g.addHit(enemy.Player, enemy.TakeDamage(bullet.Damage))
+					g.bleed(bullet)
+					hit = true
+				}
+			}
+			if hit {
+				companion.Bullets = append(companion.Bullets[:i], companion.Bullets[i+1:]...)
+			}
+		}
+
+		if companion.Dead {
+			continue
+		}
+
+		for _, enemy := range g.enemies {
+			for i := len(enemy.Bullets) - 1; i >= 0; i-- {
+				bullet := enemy.Bullets[i]
+				if bullet.Hitbox.CheckCollision(companion.Hitbox) {
+					g.addHit(companion.Player, companion.TakeDamage(bullet.Damage))
+					g.bleed(bullet)
+					enemy.Bullets = append(enemy.Bullets[:i], enemy.Bullets[i+1:]...)
+				}
+			}
+		}
+
+		for _, cactus := range g.cacti {
+			if companion.Hitbox.CheckCollision(cactus.Hitbox) {
+				g.pushBack(companion.Player, 0)
+				companion.UpdateHitboxOffset(16)
+			}
+		}
+
+		if companion.Health <= 0 {
+			companion.Health = 0
+			companion.Healthbar.Update(companion.Healthbar.X, companion.Healthbar.Y, companion.Health, companion.MaxHealth)
+			companion.Dead = true
+			companion.UpdateCurrentState(actors.PlayerDead)
+			g.die(companion.Player)
+			g.notify("a gang member is down")
+		}
+	}
+}
+
+// pushBack undoes the last move of an actor that walked into something.
+func (g *Game) pushBack(p *actors.Player, extraSpeed float64) {
+	for dir, moving := range p.MoveDirs {
+		if moving {
+			switch dir {
+			case actors.Up:
+				p.Y += p.Speed + extraSpeed
+			case actors.Down:
+				p.Y -= p.Speed + extraSpeed
+			case actors.Right:
+				p.X -= p.Speed + extraSpeed
+			case actors.Left:
+				p.X += p.Speed + extraSpeed
+			}
+		}
+	}
+}
+
+func (g *Game) drawCompanions(screen *ebiten.Image) {
+	for _, companion := range g.companions {
+		companion.Draw(screen, g.camX, g.camY)
+		companion.DrawBullets(screen, g.camX, g.camY)
+	}
+}
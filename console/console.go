@@ -0,0 +1,383 @@
+// Package console is a drop-down developer console. Commands are registered by the game
+// and its subsystems, typed in or run from a script.
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	maxLines = 200
+	// slideFrames is how long the console takes to drop down or roll up
+	slideFrames = 10
+	prompt      = "> "
+)
+
+var (
+	backgroundColor = color.NRGBA{10, 8, 6, 220}
+	borderColor     = color.RGBA{200, 160, 90, 255}
+	textColor       = color.RGBA{230, 220, 200, 255}
+	inputColor      = color.RGBA{255, 200, 60, 255}
+)
+
+// ToggleKeys open and close the console, F1 is there for keyboards without a handy backquote
+var ToggleKeys = []ebiten.Key{ebiten.KeyBackquote, ebiten.KeyF1}
+
+// Command is something the console can run.
+type Command struct {
+	Name string
+	// Usage shows the arguments, like "give <item> [amount]"
+	Usage string
+	Help  string
+	// Args lists the values the argument at the index can have, for autocomplete, it is optional
+	Args func(index int) []string
+	// Run gets the words after the name of the command and returns what to print
+	Run func(args []string) (string, error)
+}
+
+type Console struct {
+	Face *text.GoXFace
+	// Height is the part of the screen the console covers when it is open
+	Height float64
+
+	open  bool
+	slide int
+	// frame makes the cursor blink
+	frame    int
+	commands map[string]*Command
+	lines    []string
+	// scroll is how many lines the output is scrolled back
+	scroll  int
+	input   string
+	history []string
+	// recalled is the index in the history of the line shown with up and down
+	recalled int
+}
+
+func New(face *text.GoXFace) *Console {
+	c := &Console{
+		Face:     face,
+		Height:   0.4,
+		commands: map[string]*Command{},
+	}
+	c.Register(&Command{
+		Name:  "help",
+		Usage: "help [command]",
+		Help:  "lists the commands or explains one",
+		Args: func(index int) []string {
+			return c.Names()
+		},
+		Run: c.help,
+	})
+	c.Register(&Command{
+		Name:  "clear",
+		Usage: "clear",
+		Help:  "empties the console",
+		Run: func(args []string) (string, error) {
+			c.lines = nil
+			return "", nil
+		},
+	})
+	c.Register(&Command{
+		Name:  "exec",
+		Usage: "exec <file>",
+		Help:  "runs every line of a script file",
+		Run: func(args []string) (string, error) {
+			if len(args) != 1 {
+				return "", fmt.Errorf("usage: exec <file>")
+			}
+			return "", c.RunFile(args[0])
+		},
+	})
+
+	return c
+}
+
+// Register adds a command, a command with the same name is replaced.
+func (c *Console) Register(command *Command) {
+	c.commands[command.Name] = command
+}
+
+func (c *Console) Names() []string {
+	names := []string{}
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (c *Console) help(args []string) (string, error) {
+	if len(args) == 0 {
+		lines := []string{}
+		for _, name := range c.Names() {
+			lines = append(lines, fmt.Sprintf("%-28s %s", c.commands[name].Usage, c.commands[name].Help))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	command, ok := c.commands[args[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return command.Usage + "\n" + command.Help, nil
+}
+
+// Printf adds a line to the output, newlines split it into more lines.
+func (c *Console) Printf(format string, args ...any) {
+	c.lines = append(c.lines, strings.Split(fmt.Sprintf(format, args...), "\n")...)
+	if len(c.lines) > maxLines {
+		c.lines = c.lines[len(c.lines)-maxLines:]
+	}
+	c.scroll = 0
+}
+
+// Exec runs every command on the line and prints what they return.
+func (c *Console) Exec(line string) error {
+	commands, err := Parse(line)
+	if err != nil {
+		c.Printf("error: %v", err)
+		return err
+	}
+	for _, words := range commands {
+		command, ok := c.commands[words[0]]
+		if !ok {
+			err := fmt.Errorf("unknown command %q", words[0])
+			c.Printf("error: %v", err)
+			return err
+		}
+		output, err := command.Run(words[1:])
+		if output != "" {
+			c.Printf("%s", output)
+		}
+		if err != nil {
+			c.Printf("error: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunScript runs a script line by line, empty lines and lines starting with # are skipped.
+// It stops at the first line that fails.
+func (c *Console) RunScript(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber += 1
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := c.Exec(line); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (c *Console) RunFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := c.RunScript(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Complete finishes the last word of the line as far as all candidates agree,
+// and returns the candidates.
+func (c *Console) Complete(line string) (string, []string) {
+	// only the last command on the line is completed
+	head := ""
+	if i := strings.LastIndex(line, ";"); i >= 0 {
+		head, line = line[:i+1], line[i+1:]
+	}
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	prefix := words[len(words)-1]
+
+	options := c.Names()
+	if len(words) > 1 {
+		command, ok := c.commands[words[0]]
+		if !ok || command.Args == nil {
+			return head + line, nil
+		}
+		options = command.Args(len(words) - 2)
+	}
+	candidates := []string{}
+	for _, option := range options {
+		if strings.HasPrefix(option, prefix) {
+			candidates = append(candidates, option)
+		}
+	}
+	if len(candidates) == 0 {
+		return head + line, nil
+	}
+
+	completed := candidates[0]
+	for _, candidate := range candidates[1:] {
+		completed = commonPrefix(completed, candidate)
+	}
+	if len(candidates) == 1 {
+		completed += " "
+	}
+	words[len(words)-1] = completed
+	if head != "" {
+		head += " "
+	}
+
+	return head + strings.Join(words, " "), candidates
+}
+
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n += 1
+	}
+
+	return a[:n]
+}
+
+func (c *Console) Open() {
+	c.open = true
+}
+
+func (c *Console) Close() {
+	c.open = false
+}
+
+// Visible reports whether any of the console is on the screen, it stays visible while it rolls up.
+func (c *Console) Visible() bool {
+	return c.open || c.slide > 0
+}
+
+// Toggled reports whether a toggle key was pressed this frame.
+func Toggled() bool {
+	for _, key := range ToggleKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Update types into the console while it is open.
+func (c *Console) Update() {
+	c.frame += 1
+	if c.open {
+		c.slide = min(slideFrames, c.slide+1)
+	} else {
+		c.slide = max(0, c.slide-1)
+		return
+	}
+
+	if Toggled() || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.Close()
+		return
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r != '`' {
+			c.input += string(r)
+		}
+	}
+
+	switch {
+	case repeated(ebiten.KeyBackspace) && len(c.input) > 0:
+		runes := []rune(c.input)
+		c.input = string(runes[:len(runes)-1])
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		line := strings.TrimSpace(c.input)
+		c.input = ""
+		if line == "" {
+			return
+		}
+		c.Printf("%s%s", prompt, line)
+		if len(c.history) == 0 || c.history[len(c.history)-1] != line {
+			c.history = append(c.history, line)
+		}
+		c.recalled = len(c.history)
+		c.Exec(line)
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		var candidates []string
+		c.input, candidates = c.Complete(c.input)
+		if len(candidates) > 1 {
+			c.Printf("%s", strings.Join(candidates, "  "))
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) && len(c.history) > 0:
+		c.recalled = max(0, c.recalled-1)
+		c.input = c.history[c.recalled]
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) && len(c.history) > 0:
+		c.recalled = min(len(c.history), c.recalled+1)
+		c.input = ""
+		if c.recalled < len(c.history) {
+			c.input = c.history[c.recalled]
+		}
+	case repeated(ebiten.KeyPageUp):
+		c.scroll = min(max(0, len(c.lines)-1), c.scroll+5)
+	case repeated(ebiten.KeyPageDown):
+		c.scroll = max(0, c.scroll-5)
+	}
+}
+
+// repeated is true when the key is pressed and again every few frames while it is held.
+func repeated(key ebiten.Key) bool {
+	duration := inpututil.KeyPressDuration(key)
+
+	return duration == 1 || (duration > 30 && duration%3 == 0)
+}
+
+func (c *Console) Draw(screen *ebiten.Image) {
+	if !c.Visible() {
+		return
+	}
+	width := float64(screen.Bounds().Dx())
+	height := c.Height * float64(screen.Bounds().Dy()) * float64(c.slide) / slideFrames
+	vector.FillRect(screen, 0, 0, float32(width), float32(height), backgroundColor, false)
+	vector.StrokeLine(screen, 0, float32(height), float32(width), float32(height), 2, borderColor, false)
+
+	_, lineHeight := text.Measure("M", c.Face, 0)
+	lineHeight *= 1.5
+	padding := lineHeight / 2
+	// the input sits at the bottom and the output scrolls up above it
+	y := height - padding - lineHeight
+	cursor := ""
+	if c.open && c.frame%60 < 30 {
+		cursor = "_"
+	}
+	c.drawLine(screen, prompt+c.input+cursor, inputColor, padding, y)
+	last := len(c.lines) - 1 - c.scroll
+	for i := last; i >= 0 && y-lineHeight >= 0; i-- {
+		y -= lineHeight
+		c.drawLine(screen, c.lines[i], textColor, padding, y)
+	}
+}
+
+func (c *Console) drawLine(screen *ebiten.Image, line string, clr color.Color, x, y float64) {
+	options := &text.DrawOptions{}
+	options.GeoM.Translate(x, y)
+	options.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, line, c.Face, options)
+}
@@ -0,0 +1,55 @@
+package console
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// Parse splits a line into commands on semicolons and every command into its words on spaces.
+// Double quotes keep spaces and semicolons inside a word.
+func Parse(line string) ([][]string, error) {
+	commands := [][]string{}
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+	quoted := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = []string{}
+		}
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case quoted:
+			word.WriteRune(r)
+		case r == ';':
+			endCommand()
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, errUnterminatedQuote
+	}
+	endCommand()
+
+	return commands, nil
+}
@@ -0,0 +1,569 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/netplay"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	maxPlayers = 2
+	// playerSpacing is how far apart the players start
+	playerSpacing = 60
+	// the players can not walk further apart than the screen, minus this margin
+	leashMargin = 60
+	// a downed player is helped up by someone standing this close holding interact
+	reviveDistance = 60
+	reviveDuration = 180
+)
+
+var (
+	playerNames = [maxPlayers]string{"player one", "player two"}
+	// every player after the first one gets a tint, so they can be told apart
+	playerTints = [maxPlayers][3]float32{{1, 1, 1}, {0.8, 0.9, 1.2}}
+	reviveColor = color.NRGBA{255, 255, 255, 200}
+)
+
+// playerInput is the state of the devices controlling one player.
+type playerInput struct {
+	// keyboard is set when the keyboard drives the player
+	keyboard bool
+	gamepads []ebiten.GamepadID
+
+	xLeftAxis, yLeftAxis   float64
+	xRightAxis, yRightAxis float64
+	buttonsPressed         map[string]bool
+	buttonsJustPressed     map[string]bool
+	// keys stand in for the keyboard of a player on another machine
+	keys            map[ebiten.Key]bool
+	keysJustPressed map[ebiten.Key]bool
+}
+
+// read takes the sticks and buttons of the gamepads of the player, the stick pushed furthest counts.
+// Presses add up until a step of the world used them, the world does not step every frame when it runs slower.
+func (in *playerInput) read(swapSticks bool) {
+	moveX, moveY := ebiten.StandardGamepadAxisLeftStickHorizontal, ebiten.StandardGamepadAxisLeftStickVertical
+	aimX, aimY := ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical
+	if swapSticks {
+		moveX, aimX = aimX, moveX
+		moveY, aimY = aimY, moveY
+	}
+	furthest := func(current, value float64) float64 {
+		if math.Abs(value) > math.Abs(current) {
+			return value
+		}
+		return current
+	}
+
+	in.xLeftAxis, in.yLeftAxis, in.xRightAxis, in.yRightAxis = 0, 0, 0, 0
+	in.buttonsPressed = map[string]bool{}
+	if in.buttonsJustPressed == nil {
+		in.idle()
+	}
+	if in.keyboard {
+		for _, key := range inpututil.AppendJustPressedKeys(nil) {
+			in.keysJustPressed[key] = true
+		}
+	}
+	for _, id := range in.gamepads {
+		in.xLeftAxis = furthest(in.xLeftAxis, ebiten.StandardGamepadAxisValue(id, moveX))
+		in.yLeftAxis = furthest(in.yLeftAxis, ebiten.StandardGamepadAxisValue(id, moveY))
+		in.xRightAxis = furthest(in.xRightAxis, ebiten.StandardGamepadAxisValue(id, aimX))
+		in.yRightAxis = furthest(in.yRightAxis, ebiten.StandardGamepadAxisValue(id, aimY))
+		for button, name := range standardButtonToString {
+			if ebiten.IsStandardGamepadButtonPressed(id, button) {
+				in.buttonsPressed[name] = true
+			}
+			if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+				in.buttonsJustPressed[name] = true
+			}
+		}
+	}
+}
+
+// keyPressed and keyJustPressed only see the keyboard when it drives the player.
+func (in *playerInput) keyPressed(keys ...ebiten.Key) bool {
+	if !in.keyboard && in.keys == nil {
+		return false
+	}
+	for _, key := range keys {
+		if in.keys[key] || (in.keyboard && ebiten.IsKeyPressed(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// switchWeapon draws the weapon the player picked, or the next one on a gamepad.
+func switchWeapon(p *actors.Player, in *playerInput) {
+	if in.keyJustPressed(ebiten.Key1) {
+		p.DrawWeapon(actors.Revolver)
+	}
+
+	if in.keyJustPressed(ebiten.Key2) {
+		p.DrawWeapon(actors.Lasso)
+	}
+
+	if in.keyJustPressed(ebiten.Key0) {
+		p.DrawWeapon(actors.Fists)
+	}
+
+	if in.buttonsJustPressed["RT"] {
+		p.DrawWeapon((p.CurrentWeapon + 1) % (actors.Lasso + 1))
+	}
+}
+
+func (in *playerInput) keyJustPressed(key ebiten.Key) bool {
+	return in.keysJustPressed[key]
+}
+
+// pausePressed is P or escape on the keyboard, or start on a gamepad.
+func (in *playerInput) pausePressed() bool {
+	return in.keyJustPressed(ebiten.KeyP) || in.keyJustPressed(ebiten.KeyEscape) || in.buttonsJustPressed["FBR"]
+}
+
+// idle drops the presses and keeps what is held down, like netplay.Input.Idle.
+func (in *playerInput) idle() {
+	in.buttonsJustPressed = map[string]bool{}
+	in.keysJustPressed = map[ebiten.Key]bool{}
+}
+
+// coopPlayer is one of the players sharing the screen, or playing along over the network.
+type coopPlayer struct {
+	*actors.Player
+
+	// id is the slot of the player, it picks their name and tint and is how the network knows them
+	id    uint16
+	name  string
+	input *playerInput
+	// gamepad is the controller of a player who joined, player one has the keyboard and the other controllers
+	gamepad ebiten.GamepadID
+	// remote players are on another machine, on the host their inputs come from peer
+	remote bool
+	peer   *netplay.Peer
+	// moving is set when the player walked this frame, a horse under them follows
+	moving    bool
+	reloading bool
+	// reviving counts the frames someone has been helping this downed player up
+	reviving int
+}
+
+func (cp *coopPlayer) tint() {
+	if cp.id > 0 {
+		tint := playerTints[cp.id]
+		cp.DrawOptions.ColorScale.Scale(tint[0], tint[1], tint[2], 1)
+	}
+}
+
+// ownsGamepad is true for the players who joined on a controller of their own.
+func (cp *coopPlayer) ownsGamepad(g *Game) bool {
+	return cp != g.players[0] && !cp.remote
+}
+
+// localPlayers are the players on this machine, the camera and the HUD are theirs.
+func (g *Game) localPlayers() []*coopPlayer {
+	local := []*coopPlayer{}
+	for _, cp := range g.players {
+		if !cp.remote {
+			local = append(local, cp)
+		}
+	}
+
+	return local
+}
+
+// freePlayerID is the first slot nobody has, false when the game is full.
+func (g *Game) freePlayerID() (uint16, bool) {
+	for id := range uint16(maxPlayers) {
+		if !slices.ContainsFunc(g.players, func(cp *coopPlayer) bool { return cp.id == id }) {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// addPlayer puts a new player next to player one.
+func (g *Game) addPlayer(id uint16) *coopPlayer {
+	cp := &coopPlayer{
+		Player: g.newPlayer(g.player.X+playerSpacing, g.player.Y),
+		id:     id,
+		name:   playerNames[id],
+		input:  &playerInput{},
+	}
+	cp.tint()
+	g.players = append(g.players, cp)
+
+	return cp
+}
+
+// playerActors are the actors of all players, downed ones included.
+func (g *Game) playerActors() []*actors.Player {
+	players := make([]*actors.Player, 0, len(g.players))
+	for _, cp := range g.players {
+		players = append(players, cp.Player)
+	}
+
+	return players
+}
+
+// spareGamepad is the controller a new player would get, the last one connected that nobody else has to themselves.
+func (g *Game) spareGamepad() (ebiten.GamepadID, bool) {
+	ids := g.sortedGamepads()
+	for i := len(ids) - 1; i >= 0; i-- {
+		if !slices.ContainsFunc(g.players, func(cp *coopPlayer) bool { return cp.ownsGamepad(g) && cp.gamepad == ids[i] }) {
+			return ids[i], true
+		}
+	}
+
+	return 0, false
+}
+
+func (g *Game) sortedGamepads() []ebiten.GamepadID {
+	ids := make([]ebiten.GamepadID, 0, len(g.gamepadIDs))
+	for id := range g.gamepadIDs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// canJoin is true when there is room for another player and a controller for them.
+func (g *Game) canJoin() bool {
+	_, spare := g.spareGamepad()
+	_, free := g.freePlayerID()
+	return spare && free && g.client == nil
+}
+
+// join adds a player next to player one, controlled by a spare gamepad.
+func (g *Game) join() {
+	gamepad, spare := g.spareGamepad()
+	id, free := g.freePlayerID()
+	if !spare || !free || g.client != nil {
+		return
+	}
+	cp := g.addPlayer(id)
+	cp.gamepad = gamepad
+	g.hud = g.newHUD()
+	g.notify("%s joined", cp.name)
+}
+
+// leave takes a player out of the game, player one always stays.
+func (g *Game) leave(cp *coopPlayer) {
+	i := slices.Index(g.players, cp)
+	if i < 1 {
+		return
+	}
+	if cp.Horse != nil {
+		cp.Dismount()
+	}
+	g.players = slices.Delete(g.players, i, i+1)
+	g.hud = g.newHUD()
+	g.notify("%s left", cp.name)
+}
+
+// updatePlayerInputs hands out the devices and reads them. A player whose controller
+// is unplugged leaves, player one gets the keyboard and every controller nobody else has.
+func (g *Game) updatePlayerInputs() {
+	for _, cp := range slices.Clone(g.players) {
+		if _, ok := g.gamepadIDs[cp.gamepad]; cp.ownsGamepad(g) && !ok {
+			g.leave(cp)
+		}
+	}
+
+	first := g.players[0].input
+	first.keyboard = true
+	first.gamepads = first.gamepads[:0]
+	for _, id := range g.sortedGamepads() {
+		if !slices.ContainsFunc(g.players, func(cp *coopPlayer) bool { return cp.ownsGamepad(g) && cp.gamepad == id }) {
+			first.gamepads = append(first.gamepads, id)
+		}
+	}
+	for _, cp := range g.players {
+		switch {
+		case cp.peer != nil:
+			decodeInput(cp.peer.NextInput(), cp.input)
+		case cp.remote:
+			// the other players of a client are moved by the snapshots
+		default:
+			if cp.ownsGamepad(g) {
+				cp.input.gamepads = []ebiten.GamepadID{cp.gamepad}
+			}
+			cp.input.read(g.settings.Controls.SwapSticks)
+		}
+	}
+}
+
+// centerCamera puts the middle of the players on this machine in the middle of the screen.
+func (g *Game) centerCamera() {
+	x, y := playersCenter(g.localPlayers())
+	g.camX = x - float64(g.viewport.Width)/2
+	g.camY = y - float64(g.viewport.Height)/2
+}
+
+func playersCenter(players []*coopPlayer) (float64, float64) {
+	x, y := 0.0, 0.0
+	for _, cp := range players {
+		x += (cp.X + cp.W/2) / float64(len(players))
+		y += (cp.Y + cp.H/2) / float64(len(players))
+	}
+
+	return x, y
+}
+
+// leashPlayers keeps the players on this machine from walking apart further than their shared camera can show.
+func (g *Game) leashPlayers() {
+	local := g.localPlayers()
+	if len(local) < 2 {
+		return
+	}
+	centerX, centerY := playersCenter(local)
+	reachX := max(0, float64(g.viewport.Width)/2-leashMargin)
+	reachY := max(0, float64(g.viewport.Height)/2-leashMargin)
+	for _, cp := range local {
+		x := max(centerX-reachX, min(centerX+reachX, cp.X+cp.W/2))
+		y := max(centerY-reachY, min(centerY+reachY, cp.Y+cp.H/2))
+		if x != cp.X+cp.W/2 || y != cp.Y+cp.H/2 {
+			cp.X, cp.Y = x-cp.W/2, y-cp.H/2
+			cp.UpdateHitbox()
+		}
+	}
+}
+
+// controlPlayer moves and fights with a player the way their devices tell them to.
+func (g *Game) controlPlayer(cp *coopPlayer) {
+	p := cp.Player
+	in := cp.input
+	p.MoveDirs = map[actors.Direction]bool{
+		actors.Up:    false,
+		actors.Down:  false,
+		actors.Right: false,
+		actors.Left:  false,
+	}
+	p.UpdateBullets()
+	cp.moving = false
+	if p.Dead {
+		p.UpdateAnimation()
+		return
+	}
+
+	switchWeapon(p, in)
+	cp.moving = g.steer(p, in)
+
+	if (in.keyJustPressed(ebiten.KeyShiftLeft) || in.buttonsJustPressed["FTL"]) && p.Stamina >= p.DodgeCost && p.Horse == nil {
+		p.Stamina -= p.DodgeCost
+		p.CurrentAction = actors.Action{
+			Duration: p.DodgeDuration,
+			Type:     actors.Dodge,
+			Actor:    p,
+		}
+	}
+
+	p.Act(g.frameCount)
+	p.UpdateStatus(g.frameCount, g.framesPerSecond)
+
+	// consumables
+	if in.keyJustPressed(ebiten.KeyX) || in.buttonsJustPressed["LR"] {
+		p.Inventory.CycleQuickSlot(1)
+	}
+
+	if in.keyJustPressed(ebiten.KeyF) || in.buttonsJustPressed["RR"] {
+		g.useQuickItem(cp)
+	}
+
+	if in.keyJustPressed(ebiten.KeyR) || in.buttonsJustPressed["FBL"] {
+		if !p.Reload() && p.ReloadTimer == 0 && p.Rounds() == 0 {
+			g.notify("out of ammo")
+		}
+	}
+
+	if cp.moving {
+		p.Animate()
+	} else {
+		p.StopAnimation()
+	}
+	p.UpdateAnimation()
+
+	if in.keyJustPressed(ebiten.KeySpace) || in.buttonsJustPressed["FTR"] {
+		p.Shoot()
+	}
+
+	// interact helps a downed partner up before anything else
+	if (in.keyJustPressed(ebiten.KeyE) || in.buttonsJustPressed["RL"]) && g.downedNear(p) == nil {
+		g.interact(cp)
+	}
+
+	if in.keyJustPressed(ebiten.KeyV) || in.buttonsJustPressed["LS"] {
+		g.throwDynamite(p)
+	}
+}
+
+// steer walks and aims p the way the input says and tells whether p walked.
+// A client runs it for its own player too, to move before the host answers.
+func (g *Game) steer(p *actors.Player, in *playerInput) bool {
+	deadZone := g.settings.Controls.DeadZone
+	moving := false
+	if in.keyPressed(ebiten.KeyS) || in.yLeftAxis > deadZone {
+		p.Move(actors.Down)
+		moving = true
+	}
+
+	if in.keyPressed(ebiten.KeyDown) || in.yRightAxis > deadZone {
+		p.Look(actors.Down)
+	}
+
+	if in.keyPressed(ebiten.KeyZ, ebiten.KeyW) || in.yLeftAxis < -deadZone {
+		p.Move(actors.Up)
+		moving = true
+	}
+
+	if in.keyPressed(ebiten.KeyUp) || in.yRightAxis < -deadZone {
+		p.Look(actors.Up)
+	}
+
+	if in.keyPressed(ebiten.KeyD) || in.xLeftAxis > deadZone {
+		p.Move(actors.Right)
+		p.UpdateHitbox()
+		moving = true
+	}
+
+	if in.keyPressed(ebiten.KeyRight) || in.xRightAxis > deadZone {
+		p.Look(actors.Right)
+	}
+
+	if in.keyPressed(ebiten.KeyQ, ebiten.KeyA) || in.xLeftAxis < -deadZone {
+		p.Move(actors.Left)
+		p.UpdateHitbox()
+		moving = true
+	}
+
+	if in.keyPressed(ebiten.KeyLeft) || in.xRightAxis < -deadZone {
+		p.Look(actors.Left)
+	}
+
+	return moving
+}
+
+// downedNear is a downed player close enough for p to help up, or nil.
+func (g *Game) downedNear(p *actors.Player) *coopPlayer {
+	for _, cp := range g.players {
+		if cp.Player != p && cp.Dead && utils.DistanceBetweenPoints(p.X, p.Y, cp.X, cp.Y) <= reviveDistance {
+			return cp
+		}
+	}
+
+	return nil
+}
+
+// downPlayers knocks down the players without health left, they lie there until someone revives them.
+func (g *Game) downPlayers() {
+	for _, cp := range g.players {
+		if cp.Dead || cp.Health > 0 {
+			continue
+		}
+		cp.Health = 0
+		cp.Healthbar.Update(cp.Healthbar.X, cp.Healthbar.Y, cp.Health, cp.MaxHealth)
+		if cp.Horse != nil {
+			cp.Dismount()
+		}
+		cp.Dead = true
+		cp.ReloadTimer = 0
+		cp.CurrentAction = actors.Action{}
+		cp.UpdateCurrentState(actors.PlayerDead)
+		cp.reviving = 0
+		g.die(cp.Player)
+		if len(g.players) > 1 {
+			g.notify("%s is down", cp.name)
+		}
+	}
+}
+
+// revivePlayers counts up for every downed player someone is holding interact next to,
+// letting go starts over.
+func (g *Game) revivePlayers() {
+	helped := map[*coopPlayer]bool{}
+	for _, helper := range g.players {
+		if helper.Dead || !(helper.input.keyPressed(ebiten.KeyE) || helper.input.buttonsPressed["RL"]) {
+			continue
+		}
+		if downed := g.downedNear(helper.Player); downed != nil {
+			helped[downed] = true
+		}
+	}
+	for _, cp := range g.players {
+		if !cp.Dead {
+			continue
+		}
+		if !helped[cp] {
+			cp.reviving = 0
+			continue
+		}
+		cp.reviving += 1
+		if cp.reviving >= reviveDuration {
+			cp.Dead = false
+			cp.reviving = 0
+			cp.Health = cp.MaxHealth / 3
+			cp.Healthbar.Update(cp.Healthbar.X, cp.Healthbar.Y, cp.Health, cp.MaxHealth)
+			cp.StopAnimation()
+			g.notify("%s is back up", cp.name)
+		}
+	}
+}
+
+// allDown is true when nobody is left standing to revive the others.
+func (g *Game) allDown() bool {
+	for _, cp := range g.players {
+		if cp.Health > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// drawRevives fills a bar over every downed player that is being helped up.
+func (g *Game) drawRevives(screen *ebiten.Image) {
+	for _, cp := range g.players {
+		if !cp.Dead || cp.reviving == 0 {
+			continue
+		}
+		width := float32(cp.W)
+		x, y := float32(cp.X-g.camX)-width/2, float32(cp.Y-g.camY-cp.H)-10
+		vector.StrokeRect(screen, x, y, width, 6, 1, reviveColor, false)
+		vector.FillRect(screen, x, y, width*float32(cp.reviving)/reviveDuration, 6, reviveColor, false)
+	}
+}
+
+// playersLabel is how many are playing on this machine, for the pause menu.
+func (g *Game) playersLabel() string {
+	return fmt.Sprintf("%d", len(g.localPlayers()))
+}
+
+// joinedOnController is the last player who joined on a controller of this machine, or nil.
+func (g *Game) joinedOnController() *coopPlayer {
+	for i := len(g.players) - 1; i >= 0; i-- {
+		if g.players[i].ownsGamepad(g) {
+			return g.players[i]
+		}
+	}
+
+	return nil
+}
+
+// togglePlayers lets a second player join on a controller, or leave when there already is one.
+func (g *Game) togglePlayers() {
+	if cp := g.joinedOnController(); cp != nil {
+		g.leave(cp)
+		return
+	}
+	g.join()
+}
@@ -0,0 +1,281 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// debugLayer is a part of the debug overlay that can be switched on and off on its own.
+type debugLayer int
+
+const (
+	debugHitboxes debugLayer = iota
+	debugAI
+	debugPerception
+	debugPaths
+	debugStats
+	debugGamepad
+	debugLayerCount
+)
+
+var debugLayerNames = [debugLayerCount]string{
+	debugHitboxes:   "hitboxes",
+	debugAI:         "ai",
+	debugPerception: "perception",
+	debugPaths:      "paths",
+	debugStats:      "stats",
+	debugGamepad:    "gamepad",
+}
+
+// the overlay is toggled with F3, its layers with F4 up to F9
+var (
+	debugToggleKey = ebiten.KeyF3
+	debugLayerKeys = [debugLayerCount]ebiten.Key{ebiten.KeyF4, ebiten.KeyF5, ebiten.KeyF6, ebiten.KeyF7, ebiten.KeyF8, ebiten.KeyF9}
+)
+
+var (
+	playerBoxColor    = color.RGBA{0, 255, 0, 255}
+	enemyBoxColor     = color.RGBA{255, 60, 60, 255}
+	gangBoxColor      = color.RGBA{60, 140, 255, 255}
+	horseBoxColor     = color.RGBA{255, 160, 0, 255}
+	cactusBoxColor    = color.RGBA{0, 0, 0, 255}
+	bulletBoxColor    = color.RGBA{255, 255, 255, 255}
+	perceptionColor   = color.NRGBA{255, 255, 0, 120}
+	pathColor         = color.RGBA{0, 255, 255, 255}
+	debugTextBackdrop = color.NRGBA{0, 0, 0, 160}
+)
+
+// debugOverlay draws what is going on inside the game on top of it. When it is off the only
+// thing it costs is checking a key and a bool every frame.
+type debugOverlay struct {
+	enabled bool
+	layers  [debugLayerCount]bool
+}
+
+func newDebugOverlay() *debugOverlay {
+	d := &debugOverlay{}
+	for layer := range d.layers {
+		d.layers[layer] = true
+	}
+
+	return d
+}
+
+func (d *debugOverlay) update() {
+	if inpututil.IsKeyJustPressed(debugToggleKey) {
+		d.enabled = !d.enabled
+	}
+	if !d.enabled {
+		return
+	}
+	for layer, key := range debugLayerKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			d.layers[layer] = !d.layers[layer]
+		}
+	}
+}
+
+func (d *debugOverlay) on(layer debugLayer) bool {
+	return d.enabled && d.layers[layer]
+}
+
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	d := g.debug
+	if !d.enabled {
+		return
+	}
+	if d.on(debugPerception) {
+		g.drawPerception(screen)
+	}
+	if d.on(debugPaths) {
+		g.drawPaths(screen)
+	}
+	if d.on(debugHitboxes) {
+		g.drawHitboxes(screen)
+	}
+	if d.on(debugAI) {
+		g.drawAI(screen)
+	}
+
+	lines := []string{}
+	for layer, name := range debugLayerNames {
+		state := "off"
+		if d.layers[layer] {
+			state = "on"
+		}
+		lines = append(lines, fmt.Sprintf("F%d %-10s %s", layer+4, name, state))
+	}
+	if d.on(debugStats) {
+		lines = append(lines, "", fmt.Sprintf("FPS %.1f TPS %.1f", ebiten.ActualFPS(), ebiten.ActualTPS()))
+		lines = append(lines, g.entityCounts()...)
+	}
+	if d.on(debugGamepad) {
+		lines = append(lines, "")
+		lines = append(lines, g.gamepadState()...)
+	}
+	drawDebugText(screen, lines, 10, 10)
+}
+
+func drawDebugText(screen *ebiten.Image, lines []string, x, y int) {
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	// the debug font is 6 by 16 pixels
+	vector.FillRect(screen, float32(x-4), float32(y-4), float32(width*6+8), float32(len(lines)*16+8), debugTextBackdrop, false)
+	ebitenutil.DebugPrintAt(screen, strings.Join(lines, "\n"), x, y)
+}
+
+func (g *Game) drawBox(screen *ebiten.Image, hitbox *actors.HitBox, clr color.Color) {
+	if hitbox == nil {
+		return
+	}
+	vector.StrokeRect(screen, hitbox.X-float32(g.camX), hitbox.Y-float32(g.camY), hitbox.W, hitbox.H, 1, clr, false)
+}
+
+func (g *Game) drawBulletBoxes(screen *ebiten.Image, bullets []*actors.Bullet) {
+	for _, bullet := range bullets {
+		g.drawBox(screen, bullet.Hitbox, bulletBoxColor)
+	}
+}
+
+func (g *Game) drawHitboxes(screen *ebiten.Image) {
+	for _, cactus := range g.cacti {
+		g.drawBox(screen, cactus.Hitbox, cactusBoxColor)
+	}
+	for _, horse := range g.horses {
+		g.drawBox(screen, horse.Hitbox, horseBoxColor)
+	}
+	for _, enemy := range g.enemies {
+		if !enemy.Dead {
+			g.drawBox(screen, enemy.Hitbox, enemyBoxColor)
+		}
+		g.drawBulletBoxes(screen, enemy.Bullets)
+	}
+	for _, companion := range g.companions {
+		if !companion.Dead {
+			g.drawBox(screen, companion.Hitbox, gangBoxColor)
+		}
+		g.drawBulletBoxes(screen, companion.Bullets)
+	}
+	for _, p := range g.playerActors() {
+		g.drawBox(screen, p.Hitbox, playerBoxColor)
+		g.drawBulletBoxes(screen, p.Bullets)
+	}
+}
+
+// drawAI labels every enemy and gang member with what it is doing and draws a line to its target.
+func (g *Game) drawAI(screen *ebiten.Image) {
+	for _, enemy := range g.enemies {
+		if enemy.Dead {
+			continue
+		}
+		target := g.enemyTarget(enemy)
+		targetX, targetY := target.Center()
+		x, y := enemy.Center()
+		x, y = x-g.camX, y-g.camY
+		vector.StrokeLine(screen, float32(x), float32(y), float32(targetX-g.camX), float32(targetY-g.camY), 1, enemyBoxColor, false)
+		label := fmt.Sprintf("%s %d", enemy.CurrentAction.Type, enemy.CurrentAction.Duration)
+		ebitenutil.DebugPrintAt(screen, label, int(x)-len(label)*3, int(y)+28)
+	}
+	for _, companion := range g.companions {
+		if companion.Dead {
+			continue
+		}
+		x, y := companion.Center()
+		x, y = x-g.camX, y-g.camY
+		if companion.Target != nil && !companion.Target.Dead {
+			targetX, targetY := companion.Target.Center()
+			vector.StrokeLine(screen, float32(x), float32(y), float32(targetX-g.camX), float32(targetY-g.camY), 1, gangBoxColor, false)
+		}
+		label := companion.Command.String()
+		ebitenutil.DebugPrintAt(screen, label, int(x)-len(label)*3, int(y)+28)
+	}
+}
+
+func (g *Game) drawPerception(screen *ebiten.Image) {
+	for _, enemy := range g.enemies {
+		if !enemy.Dead {
+			x, y := enemy.Center()
+			vector.StrokeCircle(screen, float32(x-g.camX), float32(y-g.camY), float32(enemy.VisualDist), 1, perceptionColor, false)
+		}
+	}
+	for _, companion := range g.companions {
+		if !companion.Dead {
+			x, y := companion.Center()
+			vector.StrokeCircle(screen, float32(x-g.camX), float32(y-g.camY), float32(companion.VisualDist), 1, perceptionColor, false)
+		}
+	}
+}
+
+// drawPaths draws the waypoints the gang is following around obstacles.
+func (g *Game) drawPaths(screen *ebiten.Image) {
+	for _, companion := range g.companions {
+		if companion.Dead || len(companion.Waypoints) == 0 {
+			continue
+		}
+		fromX, fromY := companion.Center()
+		fromX, fromY = fromX-g.camX, fromY-g.camY
+		for _, waypoint := range companion.Waypoints {
+			toX, toY := waypoint.X-g.camX, waypoint.Y-g.camY
+			vector.StrokeLine(screen, float32(fromX), float32(fromY), float32(toX), float32(toY), 1, pathColor, false)
+			vector.FillCircle(screen, float32(toX), float32(toY), 2, pathColor, false)
+			fromX, fromY = toX, toY
+		}
+	}
+}
+
+func (g *Game) entityCounts() []string {
+	alive := 0
+	bullets := 0
+	for _, p := range g.playerActors() {
+		bullets += len(p.Bullets)
+	}
+	for _, enemy := range g.enemies {
+		if !enemy.Dead {
+			alive += 1
+		}
+		bullets += len(enemy.Bullets)
+	}
+	gang := 0
+	for _, companion := range g.companions {
+		if !companion.Dead {
+			gang += 1
+		}
+		bullets += len(companion.Bullets)
+	}
+
+	return []string{
+		fmt.Sprintf("players    %d", len(g.players)),
+		fmt.Sprintf("enemies    %d/%d", alive, len(g.enemies)),
+		fmt.Sprintf("gang       %d/%d", gang, len(g.companions)),
+		fmt.Sprintf("horses     %d", len(g.horses)),
+		fmt.Sprintf("cacti      %d", len(g.cacti)),
+		fmt.Sprintf("bullets    %d", bullets),
+		fmt.Sprintf("dynamite   %d", len(g.dynamite)),
+		fmt.Sprintf("particles  %d", g.particles.Len()),
+	}
+}
+
+func (g *Game) gamepadState() []string {
+	lines := []string{fmt.Sprintf("gamepads   %d", len(g.gamepadIDs))}
+	lines = append(lines, fmt.Sprintf("left  %+.2f %+.2f", g.xLeftAxis, g.yLeftAxis))
+	lines = append(lines, fmt.Sprintf("right %+.2f %+.2f", g.xRightAxis, g.yRightAxis))
+	pressed := []string{}
+	for button, down := range g.buttonsPressed {
+		if down {
+			pressed = append(pressed, button)
+		}
+	}
+	sort.Strings(pressed)
+
+	return append(lines, "buttons "+strings.Join(pressed, " "))
+}
@@ -0,0 +1,467 @@
+package dialogue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/items"
+)
+
+var (
+	ErrUnknownDialogue = errors.New("no such dialogue")
+	ErrUnavailable     = errors.New("that is not an option right now")
+)
+
+// Check is what a condition looks at.
+type Check int
+
+const (
+	MoneyCheck Check = iota
+	ReputationCheck
+	StatCheck
+	ItemCheck
+	// ContractCheck is 1 while a contract is active and 0 without one
+	ContractCheck
+)
+
+var checkNames = map[Check]string{
+	MoneyCheck:      "money",
+	ReputationCheck: "reputation",
+	StatCheck:       "stat",
+	ItemCheck:       "item",
+	ContractCheck:   "contract",
+}
+
+func (c Check) String() string {
+	return checkNames[c]
+}
+
+func (c *Check) UnmarshalText(data []byte) error {
+	for check, name := range checkNames {
+		if name == string(data) {
+			*c = check
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown check %q", data)
+}
+
+// Action is what an effect does.
+type Action int
+
+const (
+	// GiveItem puts Amount of Item in the inventory of the player
+	GiveItem Action = iota
+	// TakeItem takes Amount of Item out of the inventory of the player
+	TakeItem
+	// Money pays the player Amount, a negative Amount is paid by the player
+	Money
+	// Reputation changes the standing of the player in town by Amount
+	Reputation
+	// StartContract takes the first contract on the bounty board
+	StartContract
+	// Trade opens the shop of the one talking once the conversation ends
+	Trade
+)
+
+var actionNames = map[Action]string{
+	GiveItem:      "give",
+	TakeItem:      "take",
+	Money:         "money",
+	Reputation:    "reputation",
+	StartContract: "contract",
+	Trade:         "trade",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+func (a *Action) UnmarshalText(data []byte) error {
+	for action, name := range actionNames {
+		if name == string(data) {
+			*a = action
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown effect %q", data)
+}
+
+// World is what conversations read and change, the game gives one for the player who is talking.
+// Every change that can fail has a Can method that tells why without changing anything.
+type World interface {
+	Money() int
+	Reputation() int
+	Stat(stat actors.Stat) float64
+	Count(item string) int
+	HasContract() bool
+	CanAddMoney(amount int) error
+	AddMoney(amount int) error
+	AddReputation(amount int)
+	// Give takes items away for a negative amount
+	CanGive(item string, amount int) error
+	Give(item string, amount int) error
+	CanStartContract() error
+	StartContract() error
+	CanTrade() error
+	Trade() error
+}
+
+// Condition holds when the checked value is at least AtLeast and below Below, a bound left out is not checked.
+type Condition struct {
+	Check   Check    `json:"check"`
+	Stat    string   `json:"stat"`
+	Item    string   `json:"item"`
+	AtLeast *float64 `json:"atLeast"`
+	Below   *float64 `json:"below"`
+
+	stat actors.Stat
+}
+
+func (c *Condition) value(w World) float64 {
+	switch c.Check {
+	case MoneyCheck:
+		return float64(w.Money())
+	case ReputationCheck:
+		return float64(w.Reputation())
+	case StatCheck:
+		return w.Stat(c.stat)
+	case ItemCheck:
+		return float64(w.Count(c.Item))
+	case ContractCheck:
+		if w.HasContract() {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (c *Condition) Holds(w World) bool {
+	value := c.value(w)
+	if c.AtLeast != nil && value < *c.AtLeast {
+		return false
+	}
+	if c.Below != nil && value >= *c.Below {
+		return false
+	}
+
+	return true
+}
+
+func holds(conditions []*Condition, w World) bool {
+	for _, condition := range conditions {
+		if !condition.Holds(w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Effect struct {
+	Do     Action `json:"do"`
+	Item   string `json:"item"`
+	Amount int    `json:"amount"`
+}
+
+// change is what the effects of a choice add up to, every item and the money change once
+// however many effects touch them, so they can be checked against the world as it is.
+type change struct {
+	money      int
+	reputation int
+	// items holds the amount of every item given, taken items count negative
+	items map[string]int
+	// order keeps the items in the order the effects name them
+	order    []string
+	contract bool
+	trade    bool
+}
+
+func sum(effects []*Effect) *change {
+	c := &change{items: map[string]int{}}
+	for _, effect := range effects {
+		switch effect.Do {
+		case GiveItem, TakeItem:
+			if _, ok := c.items[effect.Item]; !ok {
+				c.order = append(c.order, effect.Item)
+			}
+			if effect.Do == GiveItem {
+				c.items[effect.Item] += effect.Amount
+			} else {
+				c.items[effect.Item] -= effect.Amount
+			}
+		case Money:
+			c.money += effect.Amount
+		case Reputation:
+			c.reputation += effect.Amount
+		case StartContract:
+			c.contract = true
+		case Trade:
+			c.trade = true
+		}
+	}
+
+	return c
+}
+
+// check tells why the change can not be made, nil when it can. The reputation always changes.
+func (c *change) check(w World) error {
+	if c.money != 0 {
+		if err := w.CanAddMoney(c.money); err != nil {
+			return err
+		}
+	}
+	for _, item := range c.order {
+		if amount := c.items[item]; amount != 0 {
+			if err := w.CanGive(item, amount); err != nil {
+				return err
+			}
+		}
+	}
+	if c.contract {
+		if err := w.CanStartContract(); err != nil {
+			return err
+		}
+	}
+	if c.trade {
+		if err := w.CanTrade(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *change) apply(w World) error {
+	if c.money != 0 {
+		if err := w.AddMoney(c.money); err != nil {
+			return err
+		}
+	}
+	for _, item := range c.order {
+		if amount := c.items[item]; amount != 0 {
+			if err := w.Give(item, amount); err != nil {
+				return err
+			}
+		}
+	}
+	if c.reputation != 0 {
+		w.AddReputation(c.reputation)
+	}
+	if c.contract {
+		if err := w.StartContract(); err != nil {
+			return err
+		}
+	}
+	if c.trade {
+		if err := w.Trade(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type Choice struct {
+	Text string       `json:"text"`
+	If   []*Condition `json:"if"`
+	Do   []*Effect    `json:"do"`
+	// Next is the node the choice leads to, the conversation ends without one
+	Next string `json:"next"`
+}
+
+// Variant replaces the text of a node when its conditions hold.
+type Variant struct {
+	If   []*Condition `json:"if"`
+	Text string       `json:"text"`
+}
+
+type Node struct {
+	Text     string     `json:"text"`
+	Variants []*Variant `json:"variants"`
+	// Choices are offered once the text is read, a node without choices ends the conversation
+	Choices []*Choice `json:"choices"`
+}
+
+type Tree struct {
+	Start string           `json:"start"`
+	Nodes map[string]*Node `json:"nodes"`
+}
+
+// Trees are the dialogues by id, the npcs refer to them by that id.
+type Trees map[string]*Tree
+
+func Load(fsys fs.FS, path string, catalog items.Catalog) (Trees, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dialogue: %w", err)
+	}
+
+	trees := Trees{}
+	if err := json.Unmarshal(data, &trees); err != nil {
+		return nil, fmt.Errorf("failed to parse dialogue %s: %w", path, err)
+	}
+	for id, tree := range trees {
+		if err := tree.validate(catalog); err != nil {
+			return nil, fmt.Errorf("dialogue %q in %s: %w", id, path, err)
+		}
+	}
+
+	return trees, nil
+}
+
+func (t *Tree) validate(catalog items.Catalog) error {
+	if _, ok := t.Nodes[t.Start]; !ok {
+		return fmt.Errorf("starts at unknown node %q", t.Start)
+	}
+	for name, node := range t.Nodes {
+		for _, variant := range node.Variants {
+			if err := validateConditions(variant.If, catalog); err != nil {
+				return fmt.Errorf("node %q: %w", name, err)
+			}
+		}
+		for _, choice := range node.Choices {
+			if _, ok := t.Nodes[choice.Next]; choice.Next != "" && !ok {
+				return fmt.Errorf("node %q leads to unknown node %q", name, choice.Next)
+			}
+			if err := validateConditions(choice.If, catalog); err != nil {
+				return fmt.Errorf("node %q: %w", name, err)
+			}
+			for _, effect := range choice.Do {
+				if effect.Do != GiveItem && effect.Do != TakeItem {
+					continue
+				}
+				if err := validateItem(effect.Item, catalog); err != nil {
+					return fmt.Errorf("node %q: %w", name, err)
+				}
+				if effect.Amount <= 0 {
+					return fmt.Errorf("node %q: %s needs a positive amount", name, effect.Do)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateConditions(conditions []*Condition, catalog items.Catalog) error {
+	for _, condition := range conditions {
+		if condition.AtLeast == nil && condition.Below == nil {
+			return fmt.Errorf("%s check needs atLeast or below", condition.Check)
+		}
+		switch condition.Check {
+		case StatCheck:
+			stat, ok := actors.StatByName(condition.Stat)
+			if !ok {
+				return fmt.Errorf("unknown stat %q", condition.Stat)
+			}
+			condition.stat = stat
+		case ItemCheck:
+			if err := validateItem(condition.Item, catalog); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateItem only lets through items that go into the inventory.
+func validateItem(id string, catalog items.Catalog) error {
+	item, ok := catalog[id]
+	if !ok {
+		return fmt.Errorf("unknown item %q", id)
+	}
+	if !item.Kind.Carried() {
+		return fmt.Errorf("%s can not be carried", id)
+	}
+
+	return nil
+}
+
+// farewell is offered on nodes without choices.
+var farewell = &Choice{Text: "farewell"}
+
+// Conversation walks a player through a dialogue tree.
+type Conversation struct {
+	// Speaker is the name of the npc the player talks to
+	Speaker string
+
+	tree  *Tree
+	world World
+	node  *Node
+}
+
+func (t Trees) Start(id, speaker string, world World) (*Conversation, error) {
+	tree, ok := t[id]
+	if !ok {
+		return nil, ErrUnknownDialogue
+	}
+
+	return &Conversation{
+		Speaker: speaker,
+		tree:    tree,
+		world:   world,
+		node:    tree.Nodes[tree.Start],
+	}, nil
+}
+
+// Text is what the npc says, the first variant that holds replaces the text of the node.
+func (c *Conversation) Text() string {
+	for _, variant := range c.node.Variants {
+		if holds(variant.If, c.world) {
+			return variant.Text
+		}
+	}
+
+	return c.node.Text
+}
+
+// Choices are the answers the player can give, the ones whose conditions do not hold are left out.
+func (c *Conversation) Choices() []*Choice {
+	if len(c.node.Choices) == 0 {
+		return []*Choice{farewell}
+	}
+	choices := []*Choice{}
+	for _, choice := range c.node.Choices {
+		if holds(choice.If, c.world) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices
+}
+
+// Choose makes the change the effects of the choice add up to and moves on to the next node.
+// The whole change is checked before any of it is made, so when the money or an item can not
+// change by its total, or the contract or the shop are not available, nothing changes and the
+// conversation stays where it is.
+func (c *Conversation) Choose(choice *Choice) error {
+	if c.Done() {
+		return ErrUnavailable
+	}
+	if choice != farewell && !holds(choice.If, c.world) {
+		return ErrUnavailable
+	}
+	change := sum(choice.Do)
+	if err := change.check(c.world); err != nil {
+		return err
+	}
+	if err := change.apply(c.world); err != nil {
+		return err
+	}
+	c.node = c.tree.Nodes[choice.Next]
+
+	return nil
+}
+
+// Done is true once a choice led out of the tree.
+func (c *Conversation) Done() bool {
+	return c.node == nil
+}
@@ -0,0 +1,271 @@
+package dialogue
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/items"
+)
+
+var testCatalog = items.Catalog{
+	"beans": {ID: "beans", Kind: items.Consumable},
+	"horse": {ID: "horse", Kind: items.Mount},
+}
+
+var (
+	errBroke    = errors.New("not enough money")
+	errMissing  = errors.New("not in inventory")
+	errFull     = errors.New("inventory full")
+	errActive   = errors.New("contract active")
+	errNoTrader = errors.New("nothing to trade")
+)
+
+// testWorld holds at most maxItems of every item.
+type testWorld struct {
+	money      int
+	reputation int
+	items      map[string]int
+	maxItems   int
+	contract   bool
+	trading    bool
+}
+
+func (w *testWorld) Money() int                    { return w.money }
+func (w *testWorld) Reputation() int               { return w.reputation }
+func (w *testWorld) Stat(stat actors.Stat) float64 { return 5 }
+func (w *testWorld) Count(item string) int         { return w.items[item] }
+func (w *testWorld) HasContract() bool             { return w.contract }
+
+func (w *testWorld) CanAddMoney(amount int) error {
+	if w.money+amount < 0 {
+		return errBroke
+	}
+	return nil
+}
+
+func (w *testWorld) AddMoney(amount int) error {
+	if err := w.CanAddMoney(amount); err != nil {
+		return err
+	}
+	w.money += amount
+	return nil
+}
+
+func (w *testWorld) AddReputation(amount int) {
+	w.reputation += amount
+}
+
+func (w *testWorld) CanGive(item string, amount int) error {
+	switch count := w.items[item] + amount; {
+	case count < 0:
+		return errMissing
+	case count > w.maxItems:
+		return errFull
+	}
+	return nil
+}
+
+func (w *testWorld) Give(item string, amount int) error {
+	if err := w.CanGive(item, amount); err != nil {
+		return err
+	}
+	w.items[item] += amount
+	return nil
+}
+
+func (w *testWorld) CanStartContract() error {
+	if w.contract {
+		return errActive
+	}
+	return nil
+}
+
+func (w *testWorld) StartContract() error {
+	if err := w.CanStartContract(); err != nil {
+		return err
+	}
+	w.contract = true
+	return nil
+}
+
+func (w *testWorld) CanTrade() error {
+	if w.maxItems == 0 {
+		return errNoTrader
+	}
+	return nil
+}
+
+func (w *testWorld) Trade() error {
+	if err := w.CanTrade(); err != nil {
+		return err
+	}
+	w.trading = true
+	return nil
+}
+
+func parseTree(t *testing.T, data string) *Tree {
+	t.Helper()
+	tree := &Tree{}
+	if err := json.Unmarshal([]byte(data), tree); err != nil {
+		t.Fatalf("parse %s: %v", data, err)
+	}
+	return tree
+}
+
+func TestTreeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tree    string
+		wantErr string
+	}{
+		{"valid", `{"start": "a", "nodes": {
+			"a": {"text": "hi", "variants": [{"if": [{"check": "stat", "stat": "charisma", "atLeast": 3}], "text": "hello"}],
+				"choices": [{"text": "buy", "if": [{"check": "item", "item": "beans", "below": 2}], "do": [{"do": "give", "item": "beans", "amount": 1}, {"do": "money", "amount": -2}], "next": "b"}]},
+			"b": {"text": "bye"}}}`, ""},
+		{"unknown start", `{"start": "x", "nodes": {"a": {"text": "hi"}}}`, `unknown node "x"`},
+		{"unknown next", `{"start": "a", "nodes": {"a": {"choices": [{"text": "go", "next": "x"}]}}}`, `leads to unknown node "x"`},
+		{"unknown item", `{"start": "a", "nodes": {"a": {"choices": [{"do": [{"do": "give", "item": "gold", "amount": 1}]}]}}}`, `unknown item "gold"`},
+		{"item not carried", `{"start": "a", "nodes": {"a": {"choices": [{"do": [{"do": "take", "item": "horse", "amount": 1}]}]}}}`, "can not be carried"},
+		{"no amount", `{"start": "a", "nodes": {"a": {"choices": [{"do": [{"do": "give", "item": "beans"}]}]}}}`, "needs a positive amount"},
+		{"unbounded condition", `{"start": "a", "nodes": {"a": {"choices": [{"if": [{"check": "money"}]}]}}}`, "needs atLeast or below"},
+		{"unknown stat", `{"start": "a", "nodes": {"a": {"variants": [{"if": [{"check": "stat", "stat": "wit", "atLeast": 1}]}]}}}`, `unknown stat "wit"`},
+		{"unknown item check", `{"start": "a", "nodes": {"a": {"choices": [{"if": [{"check": "item", "item": "gold", "atLeast": 1}]}]}}}`, `unknown item "gold"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseTree(t, tt.tree).validate(testCatalog)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("validate: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("validate: %v, want an error with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	const tree = `{"start": "start", "nodes": {
+		"start": {"choices": [{"text": "pick", "next": "end"}]},
+		"end": {"text": "done"}}}`
+	tests := []struct {
+		name      string
+		world     testWorld
+		choice    string
+		want      testWorld
+		wantErr   error
+		wantMoved bool
+	}{
+		{
+			name:      "pays and gives",
+			world:     testWorld{money: 10, items: map[string]int{}, maxItems: 5},
+			choice:    `{"next": "end", "do": [{"do": "money", "amount": -4}, {"do": "give", "item": "beans", "amount": 2}, {"do": "reputation", "amount": 3}]}`,
+			want:      testWorld{money: 6, reputation: 3, items: map[string]int{"beans": 2}, maxItems: 5},
+			wantMoved: true,
+		},
+		{
+			name:      "takes and pays back",
+			world:     testWorld{money: 0, items: map[string]int{"beans": 3}, maxItems: 5},
+			choice:    `{"next": "end", "do": [{"do": "take", "item": "beans", "amount": 3}, {"do": "money", "amount": 6}, {"do": "trade"}]}`,
+			want:      testWorld{money: 6, items: map[string]int{"beans": 0}, maxItems: 5, trading: true},
+			wantMoved: true,
+		},
+		{
+			name:      "gives what it takes first",
+			world:     testWorld{items: map[string]int{"beans": 5}, maxItems: 5},
+			choice:    `{"next": "end", "do": [{"do": "take", "item": "beans", "amount": 2}, {"do": "give", "item": "beans", "amount": 2}]}`,
+			want:      testWorld{items: map[string]int{"beans": 5}, maxItems: 5},
+			wantMoved: true,
+		},
+		{
+			name:    "rolls back two payments that do not add up",
+			world:   testWorld{money: 10, items: map[string]int{}, maxItems: 5},
+			choice:  `{"next": "end", "do": [{"do": "reputation", "amount": 1}, {"do": "money", "amount": -6}, {"do": "money", "amount": -6}]}`,
+			want:    testWorld{money: 10, items: map[string]int{}, maxItems: 5},
+			wantErr: errBroke,
+		},
+		{
+			name:    "rolls back two takes that do not add up",
+			world:   testWorld{money: 10, items: map[string]int{"beans": 3}, maxItems: 5},
+			choice:  `{"next": "end", "do": [{"do": "money", "amount": 5}, {"do": "take", "item": "beans", "amount": 2}, {"do": "take", "item": "beans", "amount": 2}]}`,
+			want:    testWorld{money: 10, items: map[string]int{"beans": 3}, maxItems: 5},
+			wantErr: errMissing,
+		},
+		{
+			name:    "rolls back when the inventory is full",
+			world:   testWorld{money: 10, items: map[string]int{"beans": 4}, maxItems: 5},
+			choice:  `{"next": "end", "do": [{"do": "money", "amount": -2}, {"do": "give", "item": "beans", "amount": 2}]}`,
+			want:    testWorld{money: 10, items: map[string]int{"beans": 4}, maxItems: 5},
+			wantErr: errFull,
+		},
+		{
+			name:    "rolls back when a contract is taken",
+			world:   testWorld{money: 10, items: map[string]int{}, maxItems: 5, contract: true},
+			choice:  `{"next": "end", "do": [{"do": "money", "amount": -2}, {"do": "contract"}]}`,
+			want:    testWorld{money: 10, items: map[string]int{}, maxItems: 5, contract: true},
+			wantErr: errActive,
+		},
+		{
+			name:    "condition does not hold",
+			world:   testWorld{money: 1, items: map[string]int{}, maxItems: 5},
+			choice:  `{"next": "end", "if": [{"check": "money", "atLeast": 5}], "do": [{"do": "money", "amount": -1}]}`,
+			want:    testWorld{money: 1, items: map[string]int{}, maxItems: 5},
+			wantErr: ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world := tt.world
+			world.items = maps.Clone(tt.world.items)
+			choice := &Choice{}
+			if err := json.Unmarshal([]byte(tt.choice), choice); err != nil {
+				t.Fatal(err)
+			}
+			trees := Trees{"test": parseTree(t, tree)}
+			if err := trees["test"].validate(testCatalog); err != nil {
+				t.Fatal(err)
+			}
+			conversation, err := trees.Start("test", "tester", &world)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = conversation.Choose(choice)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Choose: %v, want %v", err, tt.wantErr)
+			}
+			if world.money != tt.want.money || world.reputation != tt.want.reputation || !maps.Equal(world.items, tt.want.items) ||
+				world.contract != tt.want.contract || world.trading != tt.want.trading {
+				t.Errorf("world is %+v, want %+v", world, tt.want)
+			}
+			if moved := conversation.Text() == "done"; moved != tt.wantMoved {
+				t.Errorf("moved on to the next node: %t, want %t", moved, tt.wantMoved)
+			}
+		})
+	}
+}
+
+func TestChooseEnds(t *testing.T) {
+	trees := Trees{"test": parseTree(t, `{"start": "a", "nodes": {"a": {"text": "hi"}}}`)}
+	conversation, err := trees.Start("test", "tester", &testWorld{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	choices := conversation.Choices()
+	if len(choices) != 1 || choices[0] != farewell {
+		t.Fatalf("choices %v, want only the farewell", choices)
+	}
+	if err := conversation.Choose(farewell); err != nil {
+		t.Fatalf("Choose: %v", err)
+	}
+	if !conversation.Done() {
+		t.Errorf("the conversation goes on after the farewell")
+	}
+	if err := conversation.Choose(farewell); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Choose after the end: %v, want %v", err, ErrUnavailable)
+	}
+}
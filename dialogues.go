@@ -0,0 +1,359 @@
+package farwest
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/bounty"
+	"github.com/bramca/Far-West/dialogue"
+	"github.com/bramca/Far-West/economy"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/world"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// typewriterSpeed is how many letters of dialogue appear per frame
+	typewriterSpeed = 0.75
+	// a blip sounds for every few letters typed
+	blipEvery = 3
+)
+
+var (
+	dialogueChoiceColor = color.RGBA{180, 170, 150, 255}
+	dialogueFocusColor  = color.RGBA{255, 220, 120, 255}
+	dialogueErrorColor  = color.RGBA{230, 110, 90, 255}
+)
+
+var (
+	errNothingToTrade = errors.New("there is nothing to trade here")
+	errBoardEmpty     = errors.New("the bounty board is empty")
+)
+
+// dialogueWorld lets a conversation read and change player one, the gang purse and the bounty board.
+type dialogueWorld struct {
+	g *Game
+	// shop belongs to the shopkeeper talking, nil for the other townsfolk
+	shop *economy.Shop
+	// trading is set when the conversation ends in the shop
+	trading bool
+}
+
+func (w *dialogueWorld) Money() int {
+	return w.g.player.Money
+}
+
+func (w *dialogueWorld) Reputation() int {
+	return w.g.player.Reputation
+}
+
+func (w *dialogueWorld) Stat(stat actors.Stat) float64 {
+	return w.g.player.Stat(stat)
+}
+
+func (w *dialogueWorld) Count(item string) int {
+	return w.g.player.Inventory.Count(item)
+}
+
+func (w *dialogueWorld) HasContract() bool {
+	return w.g.board.Active != nil
+}
+
+func (w *dialogueWorld) CanAddMoney(amount int) error {
+	if w.g.player.Money+amount < 0 {
+		return economy.ErrNotEnoughMoney
+	}
+
+	return nil
+}
+
+func (w *dialogueWorld) AddMoney(amount int) error {
+	if err := w.CanAddMoney(amount); err != nil {
+		return err
+	}
+	w.g.player.Money += amount
+	if amount < 0 {
+		w.g.notify("paid $%d", -amount)
+	} else if amount > 0 {
+		w.g.notify("got $%d", amount)
+	}
+
+	return nil
+}
+
+func (w *dialogueWorld) AddReputation(amount int) {
+	w.g.changeReputation(amount)
+}
+
+func (w *dialogueWorld) CanGive(item string, amount int) error {
+	if amount < 0 {
+		if w.g.player.Inventory.Count(item) < -amount {
+			return actors.ErrNotInInventory
+		}
+		return nil
+	}
+
+	return w.g.player.Inventory.CanAdd(w.g.catalog[item], amount)
+}
+
+func (w *dialogueWorld) Give(item string, amount int) error {
+	if amount < 0 {
+		if err := w.g.player.Inventory.Remove(item, -amount); err != nil {
+			return err
+		}
+		w.g.notify("handed over %d %s", -amount, w.g.catalog[item].Name)
+		return nil
+	}
+	if err := w.g.player.Inventory.Add(w.g.catalog[item], amount); err != nil {
+		return err
+	}
+	w.g.notify("got %d %s", amount, w.g.catalog[item].Name)
+
+	return nil
+}
+
+func (w *dialogueWorld) CanStartContract() error {
+	if w.g.board.Active != nil {
+		return bounty.ErrContractActive
+	}
+	if len(w.g.board.Contracts) == 0 {
+		return errBoardEmpty
+	}
+
+	return nil
+}
+
+func (w *dialogueWorld) StartContract() error {
+	if err := w.CanStartContract(); err != nil {
+		return err
+	}
+
+	return w.g.acceptContract(w.g.board.Contracts[0])
+}
+
+func (w *dialogueWorld) CanTrade() error {
+	if w.shop == nil {
+		return errNothingToTrade
+	}
+
+	return nil
+}
+
+func (w *dialogueWorld) Trade() error {
+	if err := w.CanTrade(); err != nil {
+		return err
+	}
+	w.trading = true
+
+	return nil
+}
+
+// changeReputation changes the standing of the gang in town and tells the player.
+func (g *Game) changeReputation(amount int) {
+	if amount == 0 {
+		return
+	}
+	g.player.Reputation += amount
+	g.notify("reputation %+d", amount)
+}
+
+// newInformant puts a shady character at the end of the row of shops, who sells where the outlaws hide.
+func (g *Game) newInformant(x, y float64) *world.NPC {
+	sprites := g.sprites.Enemies[0].Images()
+	informant := world.NewNPC("Informant", x, y, sprites[actors.PlayerNoGunLeft], text.NewGoXFace(g.hitTextFont))
+	informant.Dialogue = "informant"
+	informant.DrawOptions.ColorScale.Scale(0.6, 0.6, 0.7, 1)
+
+	return informant
+}
+
+// talk starts the dialogue with the id, shopkeepers pass their shop so the conversation can lead to it.
+func (g *Game) talk(speaker, id string, shop *economy.Shop) error {
+	w := &dialogueWorld{g: g, shop: shop}
+	conversation, err := g.dialogues.Start(id, speaker, w)
+	if err != nil {
+		return err
+	}
+	g.scenes.Push(&dialogueScene{g: g, conversation: conversation, world: w})
+
+	return nil
+}
+
+// wrapText breaks the text into lines that fit in width, words longer than a line get one of their own.
+func wrapText(s string, face text.Face, width float64) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if line != "" && text.Advance(next, face) > width {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// dialogueScene types out what an npc says in a box at the bottom of the screen and lets the player answer.
+type dialogueScene struct {
+	g            *Game
+	conversation *dialogue.Conversation
+	world        *dialogueWorld
+
+	text string
+	// shown is how many letters of the text are typed out
+	shown    float64
+	selected int
+	message  string
+}
+
+func (s *dialogueScene) Enter() {
+	s.show()
+}
+
+// show starts typing the text of the node the conversation is at.
+func (s *dialogueScene) show() {
+	s.text = s.conversation.Text()
+	s.shown = 0
+	s.selected = 0
+}
+
+func (s *dialogueScene) Exit() {}
+
+func (s *dialogueScene) Overlay() bool {
+	return true
+}
+
+// end closes the dialogue box, and opens the shop when the conversation asked for it.
+func (s *dialogueScene) end() {
+	s.g.scenes.Pop()
+	if s.world.trading {
+		s.g.openShop(s.world.shop)
+	}
+}
+
+func (s *dialogueScene) typing() bool {
+	return int(s.shown) < len([]rune(s.text))
+}
+
+func (s *dialogueScene) Update() error {
+	g := s.g
+	in := g.menuInput(g.buttonsJustPressed)
+	if in.Back {
+		s.end()
+		return nil
+	}
+	if s.typing() {
+		letters := []rune(s.text)
+		if in.Confirm {
+			s.shown = float64(len(letters))
+			return nil
+		}
+		before := int(s.shown)
+		s.shown += typewriterSpeed
+		for i := before; i < min(int(s.shown), len(letters)); i++ {
+			if i%blipEvery == 0 && !unicode.IsSpace(letters[i]) {
+				g.sounds.Play(sound.Blip)
+			}
+		}
+		return nil
+	}
+
+	choices := s.conversation.Choices()
+	if len(choices) == 0 {
+		s.end()
+		return nil
+	}
+	if in.Up {
+		s.selected -= 1
+	}
+	if in.Down {
+		s.selected += 1
+	}
+	s.selected = (s.selected + len(choices)) % len(choices)
+	if !in.Confirm {
+		return nil
+	}
+	s.message = ""
+	if err := s.conversation.Choose(choices[s.selected]); err != nil {
+		s.message = err.Error()
+		return nil
+	}
+	if s.conversation.Done() {
+		s.end()
+		return nil
+	}
+	s.show()
+
+	return nil
+}
+
+func (s *dialogueScene) Draw(screen *ebiten.Image) {
+	g := s.g
+	face := text.NewGoXFace(g.shopFont)
+	lineHeight := float64(g.shopFontSize + g.newlinePadding/2)
+
+	width, height := g.viewport.Width, g.viewport.Height
+	panelW, panelH := float32(3*width/4), float32(2*height/5)
+	panelX, panelY := float32(width/8), float32(height)-panelH-float32(height/16)
+	vector.FillRect(screen, panelX, panelY, panelW, panelH, color.RGBA{20, 14, 8, 220}, false)
+	vector.StrokeRect(screen, panelX, panelY, panelW, panelH, 3.0, color.RGBA{200, 160, 90, 255}, false)
+
+	x := float64(panelX) + 30
+	y := float64(panelY) + 30
+
+	nameOptions := &text.DrawOptions{}
+	nameOptions.GeoM.Translate(x, y)
+	nameOptions.ColorScale = g.titleFontColorScale
+	text.Draw(screen, s.conversation.Speaker, text.NewGoXFace(g.arcadeFont), nameOptions)
+	y += 2 * lineHeight
+
+	// the letters that are not typed yet still take their place, so the words do not jump to the next line
+	left := int(s.shown)
+	for _, line := range wrapText(s.text, face, float64(panelW)-60) {
+		letters := []rune(line)
+		lineOptions := &text.DrawOptions{}
+		lineOptions.GeoM.Translate(x, y)
+		text.Draw(screen, string(letters[:min(left, len(letters))]), face, lineOptions)
+		// the space the line was wrapped on is typed too
+		left = max(0, left-len(letters)-1)
+		y += lineHeight
+	}
+	if s.typing() {
+		return
+	}
+
+	y += lineHeight / 2
+	for i, choice := range s.conversation.Choices() {
+		cursor := "  "
+		choiceOptions := &text.DrawOptions{}
+		choiceOptions.ColorScale.ScaleWithColor(dialogueChoiceColor)
+		if i == s.selected {
+			cursor = "> "
+			choiceOptions.ColorScale.Reset()
+			choiceOptions.ColorScale.ScaleWithColor(dialogueFocusColor)
+		}
+		choiceOptions.GeoM.Translate(x, y)
+		text.Draw(screen, cursor+choice.Text, face, choiceOptions)
+		y += lineHeight
+	}
+
+	if s.message != "" {
+		messageOptions := &text.DrawOptions{}
+		messageOptions.GeoM.Translate(x, float64(panelY+panelH)-2*lineHeight)
+		messageOptions.ColorScale.ScaleWithColor(dialogueErrorColor)
+		text.Draw(screen, s.message, face, messageOptions)
+	}
+}
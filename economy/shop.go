@@ -0,0 +1,182 @@
+package economy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/items"
+)
+
+var (
+	ErrUnknownItem    = errors.New("shop does not trade this item")
+	ErrOutOfStock     = errors.New("item is out of stock")
+	ErrNotEnoughMoney = errors.New("not enough money")
+	ErrInvalidAmount  = errors.New("amount must be positive")
+)
+
+type StockEntry struct {
+	Item     *items.Item
+	Quantity int
+	// Price overrides the base price of the item when it is not 0
+	Price int
+}
+
+type Shop struct {
+	Name      string
+	Stock     []*StockEntry
+	BuyMarkup float64
+	SellRate  float64
+	// PriceFactor scales every price for the given customer,
+	// a nil PriceFactor falls back to CharismaPriceFactor
+	PriceFactor func(p *actors.Player) float64
+}
+
+type stockData struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+type shopData struct {
+	Name      string      `json:"name"`
+	BuyMarkup float64     `json:"buyMarkup"`
+	SellRate  float64     `json:"sellRate"`
+	Stock     []stockData `json:"stock"`
+}
+
+func LoadShops(fsys fs.FS, path string, catalog items.Catalog) ([]*Shop, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shops: %w", err)
+	}
+
+	list := []shopData{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse shops %s: %w", path, err)
+	}
+
+	shops := []*Shop{}
+	for _, sd := range list {
+		if sd.BuyMarkup <= 0 {
+			return nil, fmt.Errorf("shop %q in %s needs a positive buyMarkup", sd.Name, path)
+		}
+		shop := &Shop{
+			Name:      sd.Name,
+			BuyMarkup: sd.BuyMarkup,
+			SellRate:  sd.SellRate,
+		}
+		for _, entry := range sd.Stock {
+			item, ok := catalog[entry.Item]
+			if !ok {
+				return nil, fmt.Errorf("shop %q in %s stocks unknown item %q", sd.Name, path, entry.Item)
+			}
+			shop.Stock = append(shop.Stock, &StockEntry{
+				Item:     item,
+				Quantity: entry.Quantity,
+				Price:    entry.Price,
+			})
+		}
+		shops = append(shops, shop)
+	}
+
+	return shops, nil
+}
+
+func (s *Shop) Entry(itemID string) *StockEntry {
+	for _, entry := range s.Stock {
+		if entry.Item.ID == itemID {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// CharismaPriceFactor is the default price factor,
+// every point of charisma takes a few percent off.
+func CharismaPriceFactor(p *actors.Player) float64 {
+	return math.Max(0.6, math.Min(1.2, 1-0.03*p.Stat(actors.CharismaStat)))
+}
+
+func (s *Shop) priceFactor(p *actors.Player) float64 {
+	if s.PriceFactor == nil {
+		return CharismaPriceFactor(p)
+	}
+
+	return s.PriceFactor(p)
+}
+
+// basePrice is the price the shop asks for the entry before markup and charisma.
+func (e *StockEntry) basePrice() int {
+	if e.Price != 0 {
+		return e.Price
+	}
+
+	return e.Item.Price
+}
+
+// BuyPrice is what the player pays for one unit of the entry.
+func (s *Shop) BuyPrice(p *actors.Player, entry *StockEntry) int {
+	return max(1, int(math.Round(float64(entry.basePrice())*s.BuyMarkup*s.priceFactor(p))))
+}
+
+// SellPrice is what the shop pays the player for one unit of the entry.
+// Better prices for the player mean a lower factor, so selling divides by it.
+func (s *Shop) SellPrice(p *actors.Player, entry *StockEntry) int {
+	return int(math.Floor(float64(entry.basePrice()) * s.SellRate / s.priceFactor(p)))
+}
+
+func (s *Shop) Buy(p *actors.Player, itemID string, amount int) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	entry := s.Entry(itemID)
+	if entry == nil {
+		return ErrUnknownItem
+	}
+	if entry.Quantity < amount {
+		return ErrOutOfStock
+	}
+	cost := s.BuyPrice(p, entry) * amount
+	if p.Money < cost {
+		return ErrNotEnoughMoney
+	}
+	if entry.Item.Kind.Carried() {
+		if err := p.Inventory.Add(entry.Item, amount); err != nil {
+			return err
+		}
+	}
+
+	p.Money -= cost
+	entry.Quantity -= amount
+
+	return nil
+}
+
+func (s *Shop) Sell(p *actors.Player, itemID string, amount int) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	entry := s.Entry(itemID)
+	if entry == nil {
+		return ErrUnknownItem
+	}
+	if err := p.Inventory.Remove(itemID, amount); err != nil {
+		return err
+	}
+
+	entry.Quantity += amount
+	p.Money += s.SellPrice(p, entry) * amount
+
+	return nil
+}
+
+// RecruitChance is the chance a hired gun agrees to join the gang,
+// every point of charisma makes it a bit more likely.
+func RecruitChance(p *actors.Player) float64 {
+	return math.Max(0.1, math.Min(0.95, 0.4+0.1*p.Stat(actors.CharismaStat)))
+}
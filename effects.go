@@ -0,0 +1,185 @@
+package farwest
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/particles"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/world"
+)
+
+const maxParticles = 8000
+
+var (
+	sparkParticles = &particles.Config{
+		Speed:       1.5,
+		SpeedSpread: 2,
+		Spread:      0.9,
+		Gravity:     0.08,
+		Drag:        0.05,
+		Life:        10,
+		LifeSpread:  10,
+		StartColor:  color.NRGBA{255, 240, 150, 255},
+		EndColor:    color.NRGBA{255, 120, 20, 0},
+		StartSize:   3,
+		EndSize:     1,
+	}
+	muzzleFlashParticles = &particles.Config{
+		Speed:       1,
+		SpeedSpread: 2.5,
+		Spread:      0.35,
+		Drag:        0.2,
+		Life:        4,
+		LifeSpread:  4,
+		StartColor:  color.NRGBA{255, 250, 200, 255},
+		EndColor:    color.NRGBA{255, 140, 30, 0},
+		StartSize:   6,
+		EndSize:     2,
+	}
+	dustParticles = &particles.Config{
+		Speed:       0.2,
+		SpeedSpread: 0.4,
+		Angle:       -math.Pi / 2,
+		Spread:      math.Pi / 2,
+		Drag:        0.04,
+		Life:        25,
+		LifeSpread:  15,
+		StartColor:  color.NRGBA{200, 170, 120, 160},
+		EndColor:    color.NRGBA{220, 200, 160, 0},
+		StartSize:   4,
+		EndSize:     9,
+	}
+	splinterParticles = &particles.Config{
+		Speed:       1,
+		SpeedSpread: 2,
+		Spread:      1.2,
+		Gravity:     0.15,
+		Drag:        0.03,
+		Life:        25,
+		LifeSpread:  20,
+		StartColor:  color.NRGBA{90, 160, 60, 255},
+		EndColor:    color.NRGBA{140, 120, 60, 0},
+		StartSize:   3,
+		EndSize:     2,
+	}
+	bloodParticles = &particles.Config{
+		Speed:       0.8,
+		SpeedSpread: 1.5,
+		Spread:      0.8,
+		Gravity:     0.12,
+		Drag:        0.06,
+		Life:        18,
+		LifeSpread:  14,
+		StartColor:  color.NRGBA{190, 20, 20, 255},
+		EndColor:    color.NRGBA{110, 10, 10, 0},
+		StartSize:   4,
+		EndSize:     2,
+	}
+	fuseParticles = &particles.Config{
+		Speed:       0.3,
+		SpeedSpread: 0.6,
+		Angle:       -math.Pi / 2,
+		Spread:      0.8,
+		Gravity:     0.03,
+		Life:        6,
+		LifeSpread:  6,
+		StartColor:  color.NRGBA{255, 230, 120, 255},
+		EndColor:    color.NRGBA{255, 110, 20, 0},
+		StartSize:   2,
+		EndSize:     1,
+	}
+	smokeParticles = &particles.Config{
+		Speed:       0.5,
+		SpeedSpread: 2.5,
+		Spread:      math.Pi,
+		Gravity:     -0.02,
+		Drag:        0.04,
+		Life:        40,
+		LifeSpread:  40,
+		StartColor:  color.NRGBA{90, 80, 70, 200},
+		EndColor:    color.NRGBA{160, 150, 140, 0},
+		StartSize:   8,
+		EndSize:     20,
+	}
+)
+
+// feet is where dust gets kicked up, the pivot of the sprite frame is the point it stands on.
+// Npc sprites are drawn from the top left and the player sprite is drawn around its position.
+func (g *Game) feet(p *actors.Player) (float64, float64) {
+	x, y := p.X, p.Y
+	if !p.IsNpc {
+		x -= p.W / 2
+		y -= p.H / 2
+	}
+	pivot, ok := g.sprites.Pivot(p.Sprites[p.CurrentState])
+	if !ok {
+		return x + p.W*p.Scale/2, y + p.H*p.Scale
+	}
+
+	return x + pivot.X*p.Scale, y + pivot.Y*p.Scale
+}
+
+// kickUpDust puts a puff of dust and a footstep sound under every footstep of the run animation.
+func (g *Game) kickUpDust(p *actors.Player) {
+	if p.Animator == nil {
+		return
+	}
+	for _, event := range p.Animator.Events() {
+		if event == actors.FootstepEvent {
+			x, y := g.feet(p)
+			g.particles.Burst(dustParticles, x, y, 3)
+			g.sounds.PlayAt(sound.Footstep, x, y)
+		}
+	}
+}
+
+// muzzleFlashes shows a flash and plays a shot for every bullet that was fired since the last frame.
+func (g *Game) muzzleFlashes(p *actors.Player) {
+	for _, bullet := range p.Bullets {
+		if bullet.Flashed {
+			continue
+		}
+		bullet.Flashed = true
+		x, y := bullet.Center()
+		if !g.settings.Accessibility.ReduceFlashes {
+			g.particles.BurstTowards(muzzleFlashParticles, x, y, bullet.R, 8)
+		}
+		g.sounds.PlayAt(sound.Gunshot, x, y)
+	}
+}
+
+// bulletImpact throws sparks and splinters back towards the shooter.
+func (g *Game) bulletImpact(bullet *actors.Bullet, cactus *world.Cactus) {
+	x, y := bullet.Center()
+	g.particles.BurstTowards(sparkParticles, x, y, bullet.R+math.Pi, 6)
+	g.particles.BurstTowards(splinterParticles, x, y, bullet.R+math.Pi, 4)
+	if cactus.Destroyed {
+		centerX, centerY := cactus.Center()
+		g.particles.Burst(splinterParticles, centerX, centerY, 30)
+	}
+}
+
+// bleed sprays blood in the direction the bullet was flying and plays the hit.
+func (g *Game) bleed(bullet *actors.Bullet) {
+	x, y := bullet.Center()
+	g.particles.BurstTowards(bloodParticles, x, y, bullet.R, 10)
+	g.sounds.PlayAt(sound.Hit, x, y)
+}
+
+func (g *Game) updateParticles() {
+	for _, p := range g.playerActors() {
+		g.muzzleFlashes(p)
+		g.kickUpDust(p)
+	}
+	for _, enemy := range g.enemies {
+		g.muzzleFlashes(enemy.Player)
+		g.kickUpDust(enemy.Player)
+	}
+	for _, companion := range g.companions {
+		g.muzzleFlashes(companion.Player)
+		g.kickUpDust(companion.Player)
+	}
+	g.particles.Update()
+}
@@ -0,0 +1,198 @@
+package farwest
+
+import (
+	"math"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/particles"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/utils"
+	"github.com/bramca/Far-West/world"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	navCellSize       = 32.0
+	debrisPieces      = 12
+	debrisDuration    = 240
+	dynamiteFuse      = 90
+	dynamiteRadius    = 120.0
+	dynamiteDamage    = 8
+	explosionDuration = 20
+	// craterSmokeDuration is how many frames smoke rises from where the dynamite went off
+	craterSmokeDuration = 180
+	// cacti caught in a blast are always destroyed
+	explosionCactusDamage = 100
+)
+
+// updateEnvironment turns destroyed cacti into stumps and moves
+// everything that was blown or thrown around.
+func (g *Game) updateEnvironment() {
+	for i := len(g.cacti) - 1; i >= 0; i-- {
+		cactus := g.cacti[i]
+		if !cactus.Destroyed {
+			continue
+		}
+		g.navGrid.Unblock(cactus.Hitbox)
+		g.debris = append(g.debris, world.BreakApart(cactus, debrisPieces, debrisDuration)...)
+		g.stumps = append(g.stumps, cactus)
+		g.cacti = append(g.cacti[:i], g.cacti[i+1:]...)
+	}
+
+	for i := len(g.debris) - 1; i >= 0; i-- {
+		g.debris[i].Update()
+		if g.debris[i].Duration <= 0 {
+			g.debris = append(g.debris[:i], g.debris[i+1:]...)
+		}
+	}
+
+	for i := len(g.dynamite) - 1; i >= 0; i-- {
+		d := g.dynamite[i]
+		exploded := d.Update()
+		if fuse, ok := g.fuses[d]; ok {
+			// the fuse sticks out of the right end of the stick
+			fuse.X, fuse.Y = d.X+11, d.Y-d.Z-1
+			fuse.Stopped = exploded
+		}
+		if exploded {
+			delete(g.fuses, d)
+			g.explode(d)
+			g.dynamite = append(g.dynamite[:i], g.dynamite[i+1:]...)
+		}
+	}
+
+	for i := len(g.explosions) - 1; i >= 0; i-- {
+		g.explosions[i].Update()
+		if g.explosions[i].Duration <= 0 {
+			g.explosions = append(g.explosions[:i], g.explosions[i+1:]...)
+		}
+	}
+}
+
+func (g *Game) throwDynamite(p *actors.Player) {
+	if err := p.Inventory.Remove("dynamite", 1); err != nil {
+		g.notify("no dynamite")
+		return
+	}
+	angle := map[actors.Direction]float64{
+		actors.Right:     0,
+		actors.RightUp:   -math.Pi / 4,
+		actors.RightDown: math.Pi / 4,
+		actors.Left:      math.Pi,
+		actors.LeftUp:    -3 * math.Pi / 4,
+		actors.LeftDown:  3 * math.Pi / 4,
+	}[p.VisualDir]
+	throwSpeed := 3.0
+	d := &world.Dynamite{
+		X:      p.X,
+		Y:      p.Y,
+		Z:      20,
+		VX:     throwSpeed * math.Cos(angle),
+		VY:     throwSpeed * math.Sin(angle),
+		VZ:     3,
+		Fuse:   dynamiteFuse,
+		Radius: dynamiteRadius,
+		Damage: dynamiteDamage,
+	}
+	g.dynamite = append(g.dynamite, d)
+	g.fuses[d] = g.particles.Emit(&particles.Emitter{
+		Config:   fuseParticles,
+		X:        d.X,
+		Y:        d.Y - d.Z,
+		Rate:     0.5,
+		Duration: -1,
+	})
+}
+
+// explode hurts everything in the blast radius, including the players.
+func (g *Game) explode(d *world.Dynamite) {
+	g.explosions = append(g.explosions, &world.Explosion{
+		X:           d.X,
+		Y:           d.Y,
+		Radius:      d.Radius,
+		Duration:    explosionDuration,
+		MaxDuration: explosionDuration,
+	})
+	g.particles.Burst(smokeParticles, d.X, d.Y, 60)
+	// the crater keeps smoking for a while after the blast
+	g.particles.Emit(&particles.Emitter{
+		Config:   smokeParticles,
+		X:        d.X,
+		Y:        d.Y,
+		Rate:     0.3,
+		Duration: craterSmokeDuration,
+	})
+	if !g.settings.Accessibility.ReduceFlashes {
+		g.particles.Burst(sparkParticles, d.X, d.Y, 40)
+	}
+	g.sounds.PlayAt(sound.Explosion, d.X, d.Y)
+
+	inRange := func(x, y float64) bool {
+		return utils.DistanceBetweenPoints(d.X, d.Y, x, y) <= d.Radius
+	}
+	for _, cactus := range g.cacti {
+		if inRange(cactus.Center()) {
+			cactus.Damage(explosionCactusDamage)
+		}
+	}
+	victims := g.playerActors()
+	for _, enemy := range g.enemies {
+		victims = append(victims, enemy.Player)
+	}
+	for _, companion := range g.companions {
+		victims = append(victims, companion.Player)
+	}
+	for _, victim := range victims {
+		if !victim.Dead && inRange(victim.X, victim.Y) {
+			g.addHit(victim, victim.TakeDamage(d.Damage))
+		}
+	}
+	for _, horse := range g.horses {
+		if !horse.Dead && inRange(horse.X+horse.W*horse.Scale/2, horse.Y+horse.H*horse.Scale/2) {
+			horse.Shot(d.Damage, d.X, d.Y, horseFleeDuration)
+			if horse.Dead {
+				g.sounds.PlayAt(sound.Death, horse.X, horse.Y)
+			}
+		}
+	}
+}
+
+// drawGround draws what lies flat on the ground below the actors.
+func (g *Game) drawGround(screen *ebiten.Image) {
+	for _, stump := range g.stumps {
+		stump.Draw(screen, g.camX, g.camY)
+	}
+	for _, piece := range g.debris {
+		piece.Draw(screen, g.camX, g.camY)
+	}
+}
+
+func (g *Game) drawExplosives(screen *ebiten.Image) {
+	for _, d := range g.dynamite {
+		d.Draw(screen, g.camX, g.camY)
+	}
+	for _, explosion := range g.explosions {
+		explosion.Draw(screen, g.camX, g.camY)
+	}
+}
+
+// routeCompanions plans a way around obstacles for the gang,
+// paths are only refreshed a few times per second.
+func (g *Game) routeCompanions() {
+	if g.frameCount%20 != 0 {
+		return
+	}
+	maxNodes := 800
+	for _, companion := range g.companions {
+		if companion.Dead {
+			continue
+		}
+		destX, destY, ok := companion.Destination()
+		if !ok {
+			companion.Waypoints = nil
+			continue
+		}
+		x, y := companion.Center()
+		companion.Waypoints = g.navGrid.FindPath(x, y, destX+16, destY+16, maxNodes)
+	}
+}
@@ -0,0 +1,117 @@
+package farwest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/helpers"
+	"github.com/bramca/Far-West/items"
+	"github.com/bramca/Far-West/menu"
+)
+
+// outfitChance is the chance an enemy wears something in each slot.
+const outfitChance = 0.4
+
+// loadEquipment finds the sprite sheet of every piece of clothing in the sprite manifest.
+func loadEquipment(sprites *helpers.Manifest, catalog items.Catalog) (map[string]*actors.Equipment, error) {
+	equipment := map[string]*actors.Equipment{}
+	for id, item := range catalog {
+		if item.Kind != items.Clothing {
+			continue
+		}
+		sheet, ok := sprites.Sheets[item.Sprite]
+		if !ok {
+			return nil, fmt.Errorf("failed to load equipment: clothing %q has unknown sprite sheet %q", id, item.Sprite)
+		}
+		piece, err := actors.NewEquipment(item, sheet.Images())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load equipment: %w", err)
+		}
+		equipment[id] = piece
+	}
+
+	return equipment, nil
+}
+
+// randomOutfit dresses an npc so not every enemy looks the same.
+func (g *Game) randomOutfit(p *actors.Player) {
+	// in the order of the ids, so the same seed dresses the same
+	bySlot := map[actors.EquipSlot][]*actors.Equipment{}
+	for _, id := range slices.Sorted(maps.Keys(g.equipment)) {
+		piece := g.equipment[id]
+		bySlot[piece.Slot] = append(bySlot[piece.Slot], piece)
+	}
+	for _, slot := range actors.EquipSlots {
+		pieces := bySlot[slot]
+		if len(pieces) == 0 || g.rand.Float64() > outfitChance {
+			continue
+		}
+		p.Equip(pieces[g.rand.Intn(len(pieces))])
+	}
+}
+
+// wear takes clothing out of the inventory and puts it on,
+// whatever was worn in that slot goes back into the inventory.
+func (g *Game) wear(p *actors.Player, item *items.Item) error {
+	piece, ok := g.equipment[item.ID]
+	if !ok {
+		return fmt.Errorf("%s can not be worn", item.Name)
+	}
+	if err := p.Inventory.Remove(item.ID, 1); err != nil {
+		return err
+	}
+	if old := p.Equip(piece); old != nil {
+		if err := p.Inventory.Add(old.Item, 1); err != nil {
+			// no room to carry the old piece, keep wearing it
+			p.Equip(old)
+			_ = p.Inventory.Add(item, 1)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// takeOff puts the clothing worn in the slot back into the inventory.
+func (g *Game) takeOff(p *actors.Player, slot actors.EquipSlot) error {
+	piece, ok := p.Equipped[slot]
+	if !ok {
+		return fmt.Errorf("not wearing a %s", slot)
+	}
+	if err := p.Inventory.Add(piece.Item, 1); err != nil {
+		return err
+	}
+	p.Unequip(slot)
+
+	return nil
+}
+
+// newClothingMenu lists what player one wears, picking a piece takes it off.
+func (g *Game) newClothingMenu() *menu.Menu {
+	m := &menu.Menu{Title: "Clothing"}
+	for _, slot := range actors.EquipSlots {
+		m.Items = append(m.Items, &menu.Item{
+			Label: slot.String(),
+			Value: func() string {
+				if piece, ok := g.player.Equipped[slot]; ok {
+					return piece.Item.Name
+				}
+				return "-"
+			},
+			Action: func() {
+				name := g.player.Equipped[slot].Item.Name
+				if err := g.takeOff(g.player, slot); err != nil {
+					g.notify("%v", err)
+					return
+				}
+				g.notify("took off %s", name)
+			},
+			Disabled: func() bool { return g.player.Equipped[slot] == nil },
+		})
+	}
+	m.Items = append(m.Items, &menu.Item{Label: "back", Back: true})
+
+	return m
+}
@@ -2,12 +2,27 @@ package farwest
 
 import (
 	"embed"
+	"fmt"
 	"image/color"
 	"math/rand"
-	"strconv"
+	"slices"
+	"strings"
 
 	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/bounty"
+	"github.com/bramca/Far-West/console"
+	"github.com/bramca/Far-West/dialogue"
+	"github.com/bramca/Far-West/economy"
 	"github.com/bramca/Far-West/helpers"
+	"github.com/bramca/Far-West/hud"
+	"github.com/bramca/Far-West/items"
+	"github.com/bramca/Far-West/menu"
+	"github.com/bramca/Far-West/netplay"
+	"github.com/bramca/Far-West/particles"
+	"github.com/bramca/Far-West/scene"
+	"github.com/bramca/Far-West/settings"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/viewport"
 	"github.com/bramca/Far-West/world"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
@@ -17,15 +32,6 @@ import (
 	"golang.org/x/image/font/opentype"
 )
 
-type Mode int
-
-const (
-	ModeTitle Mode = iota
-	ModeGame
-	ModeGameOver
-	ModePause
-)
-
 const (
 	ScreenWidth  = 1280
 	ScreenHeight = 860
@@ -34,9 +40,12 @@ const (
 	enemyHealthBarSize  = 7.0
 )
 
-//go:embed assets/*
+//go:embed assets/* piskel/*.piskel
 var assets embed.FS
 
+// requiredSheets are the sprite sheets the game loads by name.
+var requiredSheets = []string{"player", "bullet", "horse", "icon-fists", "icon-revolver", "icon-lasso"}
+
 // gamepad mappings
 var standardButtonToString = map[ebiten.StandardGamepadButton]string{
 	ebiten.StandardGamepadButtonRightBottom:      "RB",
@@ -60,19 +69,39 @@ var standardButtonToString = map[ebiten.StandardGamepadButton]string{
 
 // Game implements ebiten.Game interface.
 type Game struct {
-	mode Mode
+	scenes *scene.Manager
 
 	assets embed.FS
 
-	titleTexts    []string
-	gameOverTexts []string
-	pauseTexts    []string
+	// menus of the title, pause and game over screens
+	menus        *menu.Navigator
+	titleMenu    *menu.Menu
+	pauseMenu    *menu.Menu
+	gameOverMenu *menu.Menu
+	controlsMenu *menu.Menu
+	// settings are the preferences of the player, saved when the settings screen is left
+	settings settings.Settings
+	// viewport is the size of the screen, which follows the window
+	viewport *viewport.Viewport
+	debug    *debugOverlay
+	console  *console.Console
+
+	// developer cheats, set from the console
+	god bool
+	// timeScale is how many world updates run per frame, timeBank keeps the part of one left over
+	timeScale float64
+	timeBank  float64
+	// runStarted is set once New Game is picked, so Continue can go back to it
+	runStarted bool
+	// quit ends the game at the end of the next update
+	quit bool
 
 	fontSize                int
 	titleFontSize           int
 	playerHealthBarFontSize int
 	enemyHealthBarFontSize  int
 	hitFontSize             int
+	shopFontSize            int
 	titleFontColorScale     ebiten.ColorScale
 
 	titleArcadeFont     font.Face
@@ -80,38 +109,85 @@ type Game struct {
 	playerHealthBarFont font.Face
 	enemyHealthBarFont  font.Face
 	hitTextFont         font.Face
+	shopFont            font.Face
 
 	backgroundColor       color.RGBA
 	playerHealthbarColors []color.RGBA
 	enemyHealthbarColors  []color.RGBA
+	staminaBarColors      []color.RGBA
+	// companion bars are blue so the gang is easy to tell apart in a fight
+	gangHealthbarColors []color.RGBA
 
 	camX float64
 	camY float64
 
-	// text geo matrices
-	titleGeoMatrix    ebiten.GeoM
-	gameOverGeoMatrix ebiten.GeoM
-	pauseGeoMatrix    ebiten.GeoM
-
 	// text padding
 	newlinePadding int
 
 	framesPerSecond int
 
-	// draw options
-	titleDrawOptions    *text.DrawOptions
-	gameOverDrawOptions *text.DrawOptions
-	pauseDrawOptions    *text.DrawOptions
-
 	// actors
-	player       *actors.Player
+	// player is player one, who leads the gang and does the shopping
+	player *actors.Player
+	// players are everyone playing on this screen, player one first
+	players      []*coopPlayer
 	bulletSprite *ebiten.Image
 	enemies      []*actors.Enemy
+	// nextEnemyID is the id the last enemy got, a client builds the same ids from the seed
+	nextEnemyID  uint16
+	horseSprites []*ebiten.Image
+	// horses are kept for the whole run, including the ones the player bought
+	horses           []*actors.Horse
+	companionSprites []*ebiten.Image
+	companions       []*actors.Companion
+	// frame duration of the horse sprites, from the fps in the sprite manifest
+	horseAnimationSpeed int
+
+	// sprites are all sprite sheets from the sprite manifest
+	sprites *helpers.Manifest
 
 	// world
-	cactusSprites  []*ebiten.Image
-	cactusHitboxes []*actors.HitBox
-	cacti          []*world.Cactus
+	cacti []*world.Cactus
+	// stumps are what is left of destroyed cacti, they no longer collide
+	stumps   []*world.Cactus
+	debris   []*world.Debris
+	dynamite []*world.Dynamite
+	// fuses spark from the lit dynamite until it goes off
+	fuses      map[*world.Dynamite]*particles.Emitter
+	explosions []*world.Explosion
+	navGrid    *world.NavGrid
+	particles  *particles.System
+
+	// hud
+	hud       *playerHUD
+	objective *objective
+
+	// sound
+	sounds *sound.Manager
+	// combatTimer keeps the combat music playing for a while after a fight
+	combatTimer int
+
+	// economy
+	catalog     items.Catalog
+	shops       []*economy.Shop
+	shopkeepers []*world.Shopkeeper
+	shopScreen  *shopScreen
+	// equipment holds the wearable version of every clothing item
+	equipment map[string]*actors.Equipment
+
+	// bounties
+	bounties    *bounty.Data
+	board       *bounty.Board
+	bountyBoard *world.BountyBoard
+	// target is the outlaw of the active contract, nil without one
+	target *actors.Enemy
+	// sheriff takes the outlaws brought in alive
+	sheriff *world.NPC
+
+	// dialogue
+	dialogues dialogue.Trees
+	// townsfolk are the npcs to talk to who do not run a shop, the sheriff among them
+	townsfolk []*world.NPC
 
 	// gameplay
 	frameCount   int
@@ -125,21 +201,44 @@ type Game struct {
 	xRightAxis     float64
 	yRightAxis     float64
 	buttonsPressed map[string]bool
+	// buttonsJustPressed are the buttons pressed this frame
+	buttonsJustPressed map[string]bool
+	// last direction of the left stick in a menu, -1, 0 or 1
+	menuStickX, menuStickY int
+
+	// mouse
+	cursorX, cursorY float64
+
+	// network, at most one of host and client is set
+	host    *netplay.Host
+	client  *netplay.Client
+	netLink netplay.Link
+	// netSeed is the seed of the world the client built
+	netSeed int64
+	// rand generates the world, a host and its clients seed it the same to build the same world
+	rand *rand.Rand
+	// bulletIDs are the ids the host gave the bullets in flight
+	bulletIDs    map[*actors.Bullet]uint32
+	nextBulletID uint32
+	// netBullets are the bullets of the other actors on a client by id
+	netBullets map[uint32]*actors.Bullet
+	// netCacti are the cacti of a client by position, destroyed ones move to the stumps
+	netCacti map[[2]float32]*world.Cactus
 }
 
 func NewGame() *Game {
 	game := &Game{
-		titleTexts:              []string{"FAR WEST", "PRESS SPACE KEY OR START BUTTON"},
-		gameOverTexts:           []string{"GAME OVER!", "PRESS SPACE KEY OR START BUTTON"},
-		pauseTexts:              []string{"PAUSED", "PRESS SPACE KEY OR START BUTTON"},
 		fontSize:                24,
 		titleFontSize:           36,
 		playerHealthBarFontSize: playerHealthBarSize,
 		enemyHealthBarFontSize:  enemyHealthBarSize,
 		hitFontSize:             8,
+		shopFontSize:            14,
 		backgroundColor:         color.RGBA{R: 76, G: 70, B: 50, A: 1},
 		playerHealthbarColors:   []color.RGBA{{0, 255, 0, 240}, {255, 0, 0, 240}},
 		enemyHealthbarColors:    []color.RGBA{{0, 255, 0, 240}, {255, 0, 0, 240}},
+		staminaBarColors:        []color.RGBA{{240, 200, 40, 240}, {90, 70, 40, 240}},
+		gangHealthbarColors:     []color.RGBA{{60, 140, 255, 240}, {255, 0, 0, 240}},
 		camX:                    0.0,
 		camY:                    0.0,
 		newlinePadding:          20,
@@ -178,171 +277,299 @@ func NewGame() *Game {
 		Hinting: font.HintingVertical,
 	})
 
+	game.shopFont, _ = opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    float64(game.shopFontSize),
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+
 	game.titleFontColorScale.ScaleWithColor(color.White)
 
-	game.titleGeoMatrix.Translate(float64(ScreenWidth-len(game.titleTexts[0])*game.titleFontSize)/2, float64(4*game.titleFontSize))
-	game.gameOverGeoMatrix.Translate(float64(ScreenWidth-len(game.gameOverTexts[0])*game.fontSize)/2, float64(8*game.fontSize))
-	game.pauseGeoMatrix.Translate(float64((ScreenWidth-len(game.pauseTexts[0])*game.fontSize)/2), float64(8*game.fontSize))
+	var err error
+	game.sprites, err = helpers.LoadManifest(assets, "assets/data/sprites.json", game.framesPerSecond, requiredSheets)
+	if err != nil {
+		panic(err)
+	}
+	playerSheet := game.sprites.Sheet("player")
+	if err := playerSheet.RequireAnimations(actors.HumanoidAnimations()); err != nil {
+		panic(fmt.Sprintf("player sheet: %v", err))
+	}
+	playerSprites := playerSheet.Images()
 
-	// set text draw options
-	game.titleDrawOptions = &text.DrawOptions{
-		DrawImageOptions: ebiten.DrawImageOptions{
-			GeoM:       game.titleGeoMatrix,
-			ColorScale: game.titleFontColorScale,
-		},
+	game.bulletSprite = game.sprites.Sheet("bullet").Images()[0]
+
+	game.catalog, err = items.LoadCatalog(assets, "assets/data/items.json")
+	if err != nil {
+		panic(err)
 	}
-	game.gameOverDrawOptions = &text.DrawOptions{
-		DrawImageOptions: ebiten.DrawImageOptions{
-			GeoM:       game.gameOverGeoMatrix,
-			ColorScale: game.titleFontColorScale,
-		},
+	game.bounties, err = bounty.Load(assets, "assets/data/bounties.json")
+	if err != nil {
+		panic(err)
 	}
-	game.pauseDrawOptions = &text.DrawOptions{
-		DrawImageOptions: ebiten.DrawImageOptions{
-			GeoM:       game.pauseGeoMatrix,
-			ColorScale: game.titleFontColorScale,
-		},
+	game.dialogues, err = dialogue.Load(assets, "assets/data/dialogue.json", game.catalog)
+	if err != nil {
+		panic(err)
+	}
+	game.equipment, err = loadEquipment(game.sprites, game.catalog)
+	if err != nil {
+		panic(err)
 	}
 
-	playerSprites := helpers.LoadSprites(assets, []string{
-		"assets/player-no-gun.png",
-		"assets/player-revolver.png",
-	}, 32, 32)
+	game.companionSprites = playerSprites
 
-	enemySprites := helpers.LoadSprites(assets, []string{
-		"assets/enemy-1-no-gun.png",
-		"assets/enemy-1-revolver.png",
-		"assets/enemy-1-dead.png",
-	}, 32, 32)
+	horseSheet := game.sprites.Sheet("horse")
+	game.horseSprites = horseSheet.Images()
+	game.horseAnimationSpeed = horseSheet.FrameDuration(game.framesPerSecond)
 
-	game.bulletSprite = helpers.LoadSprites(assets, []string{
-		"assets/bullet.png",
-	}, 32, 32)[0]
+	game.sounds = sound.NewManager()
+	game.sounds.PlayMusic(sound.ExploreMusic)
 
-	game.player = &actors.Player{
-		X:              0.0,
-		Y:              0.0,
+	game.viewport = viewport.New(ScreenWidth, ScreenHeight)
+	game.debug = newDebugOverlay()
+	game.timeScale = 1
+	game.reseed(rand.Int63())
+	game.loadSettings()
+	game.Initialize()
+	game.applySettings()
+	game.newMenus()
+	game.scenes = &scene.Manager{}
+	game.scenes.Push(&titleScene{g: game})
+
+	game.console = console.New(text.NewGoXFace(game.shopFont))
+	game.registerCommands()
+	game.runStartupScript()
+
+	return game
+}
+
+// reseed starts the random source of the world over, the same seed generates the same world.
+func (g *Game) reseed(seed int64) {
+	g.rand = rand.New(rand.NewSource(seed))
+}
+
+// Initialize starts a new run: a fresh player in a freshly spawned world.
+// The sprites, sounds and item catalog are loaded once in NewGame and kept.
+func (g *Game) Initialize() {
+	playerSheet := g.sprites.Sheet("player")
+	playerSprites := playerSheet.Images()
+
+	g.frameCount = 1
+	g.enemies = nil
+	g.nextEnemyID = 0
+	g.companions = nil
+	g.horses = nil
+	g.stumps = nil
+	g.debris = nil
+	g.dynamite = nil
+	g.fuses = map[*world.Dynamite]*particles.Emitter{}
+	g.explosions = nil
+	g.combatTimer = 0
+
+	// players who joined stay in the next run, as long as their controller or connection is still there
+	if len(g.players) == 0 {
+		g.players = []*coopPlayer{{name: playerNames[0], input: &playerInput{}}}
+	}
+	for i, cp := range g.players {
+		cp.Player = g.newPlayer(float64(i)*playerSpacing, 0)
+		cp.tint()
+		cp.reviving = 0
+		cp.reloading = false
+	}
+	g.player = g.players[0].Player
+
+	nEnemies := 5
+	for range nEnemies {
+		x := g.rand.Float64()*ScreenWidth + 20
+		y := g.rand.Float64()*ScreenHeight + 20
+		// any enemy sheet in the manifest can show up
+		enemySheet := g.sprites.Enemies[g.rand.Intn(len(g.sprites.Enemies))]
+		g.enemies = append(g.enemies, g.newEnemy(x, y, enemySheet))
+	}
+
+	cactusAmount := 60
+	cactusSpawnBoundY := 3 * ScreenHeight
+	cactusSpawnBoundX := 3 * ScreenWidth
+	cactusSpriteScale := 4.0
+	cactusHealth := 9
+	g.cacti = helpers.SpawnCacti(g.rand, cactusSpawnBoundX, cactusSpawnBoundY, cactusAmount, cactusSpriteScale, g.sprites.Cacti.Images(), g.sprites.DamagedCacti.Images(), g.sprites.DestroyedCacti.Images(), g.sprites.Cacti.Hitboxes(), cactusHealth)
+
+	g.particles = particles.NewSystem(maxParticles)
+
+	g.navGrid = world.NewNavGrid(navCellSize)
+	for _, cactus := range g.cacti {
+		g.navGrid.Block(cactus.Hitbox)
+	}
+
+	var err error
+	g.shops, err = economy.LoadShops(assets, "assets/data/shops.json", g.catalog)
+	if err != nil {
+		panic(err)
+	}
+	g.horses = append(g.horses, g.newHorse(150, 100), g.newHorse(-300, 200))
+
+	g.shopkeepers = helpers.SpawnShopkeepers(-200, -250, 160, playerSprites[actors.PlayerNoGunRight], g.shops, text.NewGoXFace(g.hitTextFont))
+
+	for _, shopkeeper := range g.shopkeepers {
+		if id := strings.ToLower(shopkeeper.Shop.Name); g.dialogues[id] != nil {
+			shopkeeper.Dialogue = id
+		}
+	}
+
+	g.newBountyBoard(-200-160, -250)
+	g.newSheriff(-200-2*160, -250)
+	g.townsfolk = []*world.NPC{g.sheriff, g.newInformant(-200+float64(len(g.shopkeepers))*160, -250)}
+
+	g.hud = g.newHUD()
+	g.objective = g.townObjective()
+}
+
+// newPlayer puts a player with full health, a revolver and some rounds at x, y.
+func (g *Game) newPlayer(x, y float64) *actors.Player {
+	playerSheet := g.sprites.Sheet("player")
+	playerSprites := playerSheet.Images()
+
+	p := &actors.Player{
+		X:              x,
+		Y:              y,
 		W:              float64(playerSprites[0].Bounds().Dx()),
 		H:              float64(playerSprites[0].Bounds().Dy()),
 		Sprites:        playerSprites,
 		Scale:          2,
-		Speed:          2.0,
+		BaseSpeed:      2.0,
 		DodgeSpeed:     1.7,
 		DodgeDuration:  20,
-		AnimationSpeed: 15,
+		AnimationSpeed: playerSheet.FrameDuration(g.framesPerSecond),
+		Animator:       actors.NewAnimator(playerSheet.Animations),
 		DrawOptions:    &ebiten.DrawImageOptions{},
-		BulletSprite:   game.bulletSprite,
+		BulletSprite:   g.bulletSprite,
 		Health:         20,
 		MaxHealth:      20,
+		Money:          50,
+		Inventory:      actors.NewInventory(8, 25),
+		Stamina:        100,
+		MaxStamina:     100,
+		StaminaRegen:   0.25,
+		DodgeCost:      35,
+		Ammo:           6,
+		CylinderSize:   6,
+		Stats: actors.NewStats(map[actors.Stat]float64{
+			actors.HealthStat:      20,
+			actors.StaminaStat:     100,
+			actors.AccuracyStat:    70,
+			actors.ReloadSpeedStat: 50,
+			actors.CharismaStat:    1,
+			actors.LuckStat:        5,
+		}),
 		Hitbox: &actors.HitBox{
-			X: 0.0,
-			Y: 0.0,
+			X: float32(x),
+			Y: float32(y),
 			W: float32(playerSprites[0].Bounds().Dx() - 5),
 			H: float32(playerSprites[0].Bounds().Dy()),
 		},
 	}
 
-	game.player.Healthbar = &actors.HealthBar{
-		X:               40,
-		Y:               ScreenHeight - 40,
-		W:               100,
-		H:               playerHealthBarSize,
-		FixedSize:       true,
-		FixedPos:        true,
-		Points:          game.player.Health,
-		MaxPoints:       game.player.MaxHealth,
-		HealthBarColor:  game.playerHealthbarColors[0],
-		HealthLostColor: game.playerHealthbarColors[1],
-		TextFont:        text.NewGoXFace(game.playerHealthBarFont),
-		FontColor:       color.RGBA{0, 0, 0, 240},
-		FontSize:        game.playerHealthBarFontSize,
+	p.UpdateSpeed()
+	p.Healthbar = &hud.ResourceBar{
+		W:          100,
+		H:          playerHealthBarSize,
+		FixedSize:  true,
+		Points:     p.Health,
+		MaxPoints:  p.MaxHealth,
+		FillColor:  g.playerHealthbarColors[0],
+		EmptyColor: g.playerHealthbarColors[1],
+		TextFont:   text.NewGoXFace(g.playerHealthBarFont),
+		FontColor:  color.RGBA{0, 0, 0, 240},
+		FontSize:   g.playerHealthBarFontSize,
 	}
-	game.player.Healthbar.SetDrawOptions()
-
-	nEnemies := 5
-	for range nEnemies {
-		x := rand.Float64()*ScreenWidth + 20
-		y := rand.Float64()*ScreenHeight + 20
-		state := actors.PlayerRevolverLeft
-		enemy := &actors.Enemy{
-			Player: &actors.Player{
-				X:              x,
-				Y:              y,
-				W:              float64(enemySprites[state].Bounds().Dx() - 5),
-				H:              float64(enemySprites[state].Bounds().Dy()),
-				Sprites:        enemySprites,
-				CurrentState:   state,
-				CurrentWeapon:  actors.Revolver,
-				Scale:          2,
-				Speed:          0.5 + rand.Float64(),
-				DodgeSpeed:     0.3 + rand.Float64()*0.4,
-				AnimationSpeed: 15,
-				DrawOptions:    &ebiten.DrawImageOptions{},
-				FireRate:       25 + rand.Intn(15),
-				BulletSprite:   game.bulletSprite,
-				Health:         10,
-				MaxHealth:      10,
-				IsNpc:          true,
-				Hitbox: &actors.HitBox{
-					X: float32(x) + 16,
-					Y: float32(y) + 16,
-					W: float32(enemySprites[state].Bounds().Dx() - 5),
-					H: float32(enemySprites[state].Bounds().Dy()),
-				},
-			},
-			VisualDist: rand.Intn(200) + 250,
-		}
-		enemy.Healthbar = &actors.HealthBar{
-			X:               enemy.X,
-			Y:               enemy.Y - (enemy.H - enemy.H/3),
-			W:               enemy.W + 5,
-			H:               enemyHealthBarSize,
-			Points:          enemy.Health,
-			MaxPoints:       enemy.MaxHealth,
-			HealthBarColor:  game.enemyHealthbarColors[0],
-			HealthLostColor: game.enemyHealthbarColors[1],
-			TextFont:        text.NewGoXFace(game.enemyHealthBarFont),
-			FontColor:       color.RGBA{0, 0, 0, 240},
-			FontSize:        game.enemyHealthBarFontSize,
-		}
-		enemy.Healthbar.SetDrawOptions()
-		game.enemies = append(game.enemies, enemy)
+	p.Healthbar.SetDrawOptions()
+
+	p.Staminabar = &hud.ResourceBar{
+		W:          100,
+		H:          playerHealthBarSize,
+		FixedSize:  true,
+		Points:     int(p.Stamina),
+		MaxPoints:  int(p.MaxStamina),
+		FillColor:  g.staminaBarColors[0],
+		EmptyColor: g.staminaBarColors[1],
+		TextFont:   text.NewGoXFace(g.playerHealthBarFont),
+		FontColor:  color.RGBA{0, 0, 0, 240},
+		FontSize:   g.playerHealthBarFontSize,
+	}
+	p.Staminabar.SetDrawOptions()
+	if err := p.Inventory.Add(g.catalog[actors.RevolverRounds], 4); err != nil {
+		panic(err)
 	}
 
-	game.cactusSprites = helpers.LoadSprites(assets, []string{
-		"assets/cactus.png",
-	}, 32, 32)
-
-	game.cactusHitboxes = helpers.InitializeCactusHitboxes()
-
-	cactusAmount := 60
-	cactusSpawnBoundY := 3 * ScreenHeight
-	cactusSpawnBoundX := 3 * ScreenWidth
-	cactusSpriteScale := 4.0
-	game.cacti = helpers.SpawnCacti(cactusSpawnBoundX, cactusSpawnBoundY, cactusAmount, cactusSpriteScale, game.cactusSprites, game.cactusHitboxes)
-
-	return game
+	return p
 }
 
-func (g *Game) Initialize() {
-	// TODO: What happens after game over?
-	// Calculate the position of the screen center based on the player's position
-	// camX = player.x + player.w/2 - ScreenWidth/2
-	// camY = player.y + player.h/2 - ScreenHeight/2
+// newEnemy puts an enemy with the looks of the sheet at x, y.
+func (g *Game) newEnemy(x, y float64, enemySheet *helpers.SpriteSheet) *actors.Enemy {
+	state := actors.PlayerRevolverLeft
+	enemySprites := enemySheet.Images()
+	enemy := &actors.Enemy{
+		Player: &actors.Player{
+			X:              x,
+			Y:              y,
+			W:              float64(enemySprites[state].Bounds().Dx() - 5),
+			H:              float64(enemySprites[state].Bounds().Dy()),
+			Sprites:        enemySprites,
+			CurrentState:   state,
+			CurrentWeapon:  actors.Revolver,
+			Scale:          2,
+			BaseSpeed:      0.5 + g.rand.Float64(),
+			DodgeSpeed:     0.3 + g.rand.Float64()*0.4,
+			AnimationSpeed: enemySheet.FrameDuration(g.framesPerSecond),
+			Animator:       actors.NewAnimator(enemySheet.Animations),
+			DrawOptions:    &ebiten.DrawImageOptions{},
+			FireRate:       25 + g.rand.Intn(15),
+			BulletSprite:   g.bulletSprite,
+			Health:         10,
+			MaxHealth:      10,
+			IsNpc:          true,
+			Hitbox: &actors.HitBox{
+				X: float32(x) + 16,
+				Y: float32(y) + 16,
+				W: float32(enemySprites[state].Bounds().Dx() - 5),
+				H: float32(enemySprites[state].Bounds().Dy()),
+			},
+		},
+		VisualDist: g.rand.Intn(200) + 250,
+	}
+	g.nextEnemyID += 1
+	enemy.ID = g.nextEnemyID
+	enemy.UpdateSpeed()
+	enemy.Healthbar = &hud.ResourceBar{
+		X:          enemy.X,
+		Y:          enemy.Y - (enemy.H - enemy.H/3),
+		W:          enemy.W + 5,
+		H:          enemyHealthBarSize,
+		Points:     enemy.Health,
+		MaxPoints:  enemy.MaxHealth,
+		FillColor:  g.enemyHealthbarColors[0],
+		EmptyColor: g.enemyHealthbarColors[1],
+		TextFont:   text.NewGoXFace(g.enemyHealthBarFont),
+		FontColor:  color.RGBA{0, 0, 0, 240},
+		FontSize:   g.enemyHealthBarFontSize,
+	}
+	enemy.Healthbar.SetDrawOptions()
+	g.randomOutfit(enemy.Player)
+
+	return enemy
 }
 
 func (g *Game) CheckCollisions() {
-	// TODO: check bullet collision and damage the environment
 	for _, cactus := range g.cacti {
-		removeIndices := []int{}
-		for i, bullet := range g.player.Bullets {
-			if bullet.Hitbox.CheckCollision(cactus.Hitbox) {
-				removeIndices = append(removeIndices, i)
+		for _, p := range g.playerActors() {
+			for i := len(p.Bullets) - 1; i >= 0; i-- {
+				bullet := p.Bullets[i]
+				if bullet.Hitbox.CheckPixelCollision(cactus.Hitbox) {
+					cactus.Damage(bullet.Damage)
+					g.bulletImpact(bullet, cactus)
+					p.Bullets = append(p.Bullets[:i], p.Bullets[i+1:]...)
+				}
 			}
 		}
-		for _, index := range removeIndices {
-			g.player.Bullets = append(g.player.Bullets[:index], g.player.Bullets[index+1:]...)
-		}
 
 		for i, enemy := range g.enemies {
 			if enemy.Dead {
@@ -394,50 +621,45 @@ func (g *Game) CheckCollisions() {
 
 			}
 
-			removeIndices := []int{}
-			for i, bullet := range enemy.Bullets {
-				if bullet.Hitbox.CheckCollision(cactus.Hitbox) {
-					removeIndices = append(removeIndices, i)
+			for i := len(enemy.Bullets) - 1; i >= 0; i-- {
+				bullet := enemy.Bullets[i]
+				if bullet.Hitbox.CheckPixelCollision(cactus.Hitbox) {
+					cactus.Damage(bullet.Damage)
+					g.bulletImpact(bullet, cactus)
+					enemy.Bullets = append(enemy.Bullets[:i], enemy.Bullets[i+1:]...)
+					continue
 				}
-				if bullet.Hitbox.CheckCollision(g.player.Hitbox) {
-					removeIndices = append(removeIndices, i)
-					g.player.Health -= bullet.Damage
-					hit := actors.Hit{
-						X:        g.player.X,
-						Y:        g.player.Y - g.player.H/2,
-						Color:    color.RGBA{255, 255, 255, 240},
-						Msg:      "-" + strconv.Itoa(bullet.Damage),
-						TextFont: text.NewGoXFace(g.hitTextFont),
-						Duration: 2 * g.framesPerSecond / 3,
+				for _, p := range g.playerActors() {
+					if !p.Dead && bullet.Hitbox.CheckCollision(p.Hitbox) {
+						g.addHit(p, p.TakeDamage(bullet.Damage))
+						g.bleed(bullet)
+						enemy.Bullets = append(enemy.Bullets[:i], enemy.Bullets[i+1:]...)
+						break
 					}
-					hit.SetDrawOptions()
-					g.player.Hits = append(g.player.Hits, hit)
-					// TODO: What if player health <= 0?
 				}
 			}
-			for _, index := range removeIndices {
-				enemy.Bullets = append(enemy.Bullets[:index], enemy.Bullets[index+1:]...)
-			}
 		}
 
-		if g.player.Hitbox.CheckCollision(cactus.Hitbox) {
-			dodgeCalc := 0.0
-			if g.player.CurrentAction.Duration > 0 && g.player.CurrentAction.Type == actors.Dodge {
-				dodgeCalc = g.player.DodgeSpeed
-			}
-			for dir, moving := range g.player.MoveDirs {
-				if moving {
-					switch dir {
-					case actors.Up:
-						g.player.Y += g.player.Speed + dodgeCalc
-					case actors.Down:
-						g.player.Y -= g.player.Speed + dodgeCalc
-					case actors.Right:
-						g.player.X -= g.player.Speed + dodgeCalc
-					case actors.Left:
-						g.player.X += g.player.Speed + dodgeCalc
+		for _, p := range g.playerActors() {
+			if p.Hitbox.CheckCollision(cactus.Hitbox) {
+				dodgeCalc := 0.0
+				if p.CurrentAction.Duration > 0 && p.CurrentAction.Type == actors.Dodge {
+					dodgeCalc = p.DodgeSpeed
+				}
+				for dir, moving := range p.MoveDirs {
+					if moving {
+						switch dir {
+						case actors.Up:
+							p.Y += p.Speed + dodgeCalc
+						case actors.Down:
+							p.Y -= p.Speed + dodgeCalc
+						case actors.Right:
+							p.X -= p.Speed + dodgeCalc
+						case actors.Left:
+							p.X += p.Speed + dodgeCalc
+						}
+						p.UpdateHitbox()
 					}
-					g.player.UpdateHitbox()
 				}
 			}
 		}
@@ -447,61 +669,150 @@ func (g *Game) CheckCollisions() {
 		if enemy.Dead {
 			continue
 		}
-		removeIndices := []int{}
-		for i, bullet := range g.player.Bullets {
-			if bullet.Hitbox.CheckCollision(enemy.Hitbox) {
-				removeIndices = append(removeIndices, i)
-				enemy.Health -= bullet.Damage
-				hit := actors.Hit{
-					X:        enemy.X,
-					Y:        enemy.Y - enemy.H/2,
-					Color:    color.RGBA{255, 255, 255, 240},
-					Msg:      "-" + strconv.Itoa(bullet.Damage),
-					TextFont: text.NewGoXFace(g.hitTextFont),
-					Duration: 2 * g.framesPerSecond / 3,
+		for _, p := range g.playerActors() {
+			for i := len(p.Bullets) - 1; i >= 0; i-- {
+				bullet := p.Bullets[i]
+				if bullet.Hitbox.CheckCollision(enemy.Hitbox) {
+					g.addHit(enemy.Player, enemy.TakeDamage(bullet.Damage))
+					g.bleed(bullet)
+					p.Bullets = append(p.Bullets[:i], p.Bullets[i+1:]...)
 				}
-				hit.SetDrawOptions()
-				enemy.Hits = append(enemy.Hits, hit)
-
 			}
 		}
-		for _, index := range removeIndices {
-			g.player.Bullets = append(g.player.Bullets[:index], g.player.Bullets[index+1:]...)
-		}
 
 		if enemy.Health <= 0 {
 			enemy.Health = 0
 			enemy.Healthbar.Update(enemy.Healthbar.X, enemy.Healthbar.Y, enemy.Health, enemy.MaxHealth)
 			enemy.Dead = true
 			enemy.UpdateCurrentState(actors.PlayerDead)
+			g.die(enemy.Player)
 			continue
 		}
 
-		if g.player.Hitbox.CheckCollision(enemy.Hitbox) {
-			for dir, moving := range g.player.MoveDirs {
-				if moving {
-					switch dir {
-					case actors.Up:
-						g.player.Y += g.player.Speed
-					case actors.Down:
-						g.player.Y -= g.player.Speed
-					case actors.Right:
-						g.player.X -= g.player.Speed
-					case actors.Left:
-						g.player.X += g.player.Speed
+		for _, p := range g.playerActors() {
+			// a captive on a short rope does not get in the way of its captor
+			if enemy.Captor == p {
+				continue
+			}
+			if p.Hitbox.CheckCollision(enemy.Hitbox) {
+				for dir, moving := range p.MoveDirs {
+					if moving {
+						switch dir {
+						case actors.Up:
+							p.Y += p.Speed
+						case actors.Down:
+							p.Y -= p.Speed
+						case actors.Right:
+							p.X -= p.Speed
+						case actors.Left:
+							p.X += p.Speed
+						}
+						p.UpdateHitbox()
 					}
-					g.player.UpdateHitbox()
+				}
+			}
+		}
+	}
+
+	if g.settings.Coop.FriendlyFire {
+		g.checkFriendlyFire()
+	}
+}
+
+// checkFriendlyFire lets the bullets of a player hit the other players who are still standing.
+func (g *Game) checkFriendlyFire() {
+	for _, shooter := range g.playerActors() {
+		for i := len(shooter.Bullets) - 1; i >= 0; i-- {
+			bullet := shooter.Bullets[i]
+			for _, p := range g.playerActors() {
+				if p != shooter && !p.Dead && bullet.Hitbox.CheckCollision(p.Hitbox) {
+					g.addHit(p, p.TakeDamage(bullet.Damage))
+					g.bleed(bullet)
+					shooter.Bullets = append(shooter.Bullets[:i], shooter.Bullets[i+1:]...)
+					break
 				}
 			}
 		}
 	}
 }
 
+// interact handles the interact button: get off the horse, talk to a shopkeeper, read the bounty board,
+// talk to the townsfolk, tie up or lead a captive or climb on a nearby horse, in that order. Only player
+// one does the shopping, the talking and takes contracts.
+func (g *Game) interact(cp *coopPlayer) {
+	if cp.Horse != nil {
+		cp.Dismount()
+		return
+	}
+	for _, shopkeeper := range g.shopkeepers {
+		if cp.Player != g.player || !shopkeeper.InRange(g.player) {
+			continue
+		}
+		if shopkeeper.Dialogue == "" {
+			g.openShop(shopkeeper.Shop)
+			return
+		}
+		if err := g.talk(shopkeeper.Shop.Name, shopkeeper.Dialogue, shopkeeper.Shop); err != nil {
+			g.notify("%v", err)
+		}
+		return
+	}
+	if cp.Player == g.player && g.bountyBoard.InRange(g.player) {
+		g.openBounties()
+		return
+	}
+	for _, npc := range g.townsfolk {
+		if cp.Player != g.player || npc.Dialogue == "" || !npc.InRange(g.player) {
+			continue
+		}
+		if err := g.talk(npc.Name, npc.Dialogue, nil); err != nil {
+			g.notify("%v", err)
+		}
+		return
+	}
+	if g.interactCaptive(cp) {
+		return
+	}
+	if horse := g.nearestHorse(cp.Player); horse != nil {
+		cp.Mount(horse)
+	}
+}
+
+// useQuickItem uses the item in the quick slot and tells the player how it went,
+// clothing in the quick slot is put on.
+func (g *Game) useQuickItem(cp *coopPlayer) {
+	slot := cp.Inventory.QuickItem()
+	if slot == nil {
+		g.notify("nothing in the quick slot")
+		return
+	}
+	item := slot.Item
+	verb := "used"
+	if item.Kind == items.Clothing {
+		if err := g.wear(cp.Player, item); err != nil {
+			g.notify("%v", err)
+			return
+		}
+		if cp.Inventory.QuickItem() == nil {
+			cp.Inventory.CycleQuickSlot(1)
+		}
+		verb = "put on"
+	} else if err := cp.UseQuickItem(); err != nil {
+		g.notify("%v", err)
+		return
+	}
+	if len(g.players) > 1 {
+		g.notify("%s %s %s", cp.name, verb, item.Name)
+		return
+	}
+	g.notify("%s %s", verb, item.Name)
+}
+
 // Update proceeds the game state.
 // Update is called every tick (1/60 [s] by default).
 func (g *Game) Update() error {
 	// gamepad logic
-	buttonsJustPressed := map[string]bool{}
+	g.buttonsJustPressed = map[string]bool{}
 	if g.gamepadIDs == nil {
 		g.gamepadIDs = map[ebiten.GamepadID]struct{}{}
 	}
@@ -519,24 +830,31 @@ func (g *Game) Update() error {
 		}
 	}
 
+	// the left stick moves and the right one aims, unless they are swapped in the settings
+	moveX, moveY := ebiten.StandardGamepadAxisLeftStickHorizontal, ebiten.StandardGamepadAxisLeftStickVertical
+	aimX, aimY := ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical
+	if g.settings.Controls.SwapSticks {
+		moveX, aimX = aimX, moveX
+		moveY, aimY = aimY, moveY
+	}
 	for id := range g.gamepadIDs {
 		// log axis events
-		xLeftAxisPressed := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		xLeftAxisPressed := ebiten.StandardGamepadAxisValue(id, moveX)
 		if xLeftAxisPressed != g.xLeftAxis {
 			g.xLeftAxis = xLeftAxisPressed
 			// log.Printf("Left Stick X: %+0.2f", g.xLeftAxis)
 		}
-		yLeftAyisPressed := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		yLeftAyisPressed := ebiten.StandardGamepadAxisValue(id, moveY)
 		if yLeftAyisPressed != g.yLeftAxis {
 			g.yLeftAxis = yLeftAyisPressed
 			// log.Printf("Left Stick Y: %+0.2f", g.yLeftAxis)
 		}
-		xRightAxisPressed := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickHorizontal)
+		xRightAxisPressed := ebiten.StandardGamepadAxisValue(id, aimX)
 		if xRightAxisPressed != g.xRightAxis {
 			g.xRightAxis = xRightAxisPressed
 			// log.Printf("Right Stick X: %+0.2f", g.xRightAxis)
 		}
-		yRightAxisPressed := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickVertical)
+		yRightAxisPressed := ebiten.StandardGamepadAxisValue(id, aimY)
 		if yRightAxisPressed != g.yRightAxis {
 			g.yRightAxis = yRightAxisPressed
 			// log.Printf("Right Stick Y: %+0.2f", g.yRightAxis)
@@ -548,7 +866,7 @@ func (g *Game) Update() error {
 			if inpututil.IsGamepadButtonJustPressed(id, b) {
 				// log.Printf("button pressed: id: %d, button: %d - %s", id, b, standardButtonToString[ebiten.StandardGamepadButton(b)])
 				g.buttonsPressed[standardButtonToString[ebiten.StandardGamepadButton(b)]] = true
-				buttonsJustPressed[standardButtonToString[ebiten.StandardGamepadButton(b)]] = true
+				g.buttonsJustPressed[standardButtonToString[ebiten.StandardGamepadButton(b)]] = true
 			}
 
 			if inpututil.IsGamepadButtonJustReleased(id, b) {
@@ -559,141 +877,98 @@ func (g *Game) Update() error {
 
 	}
 
-	// Calculate the position of the screen center based on the player's position
-	g.camX = g.player.X + g.player.W/2 - ScreenWidth/2
-	g.camY = g.player.Y + g.player.H/2 - ScreenHeight/2
+	g.updateNetwork()
+	g.updatePlayerInputs()
+	g.centerCamera()
 
-	// controls
-	switch g.mode {
-	case ModeTitle:
-		if ebiten.IsKeyPressed(ebiten.KeySpace) || buttonsJustPressed["FBR"] {
-			g.mode = ModeGame
-		}
-	case ModeGameOver:
-		if ebiten.IsKeyPressed(ebiten.KeySpace) || buttonsJustPressed["FBR"] {
-			g.Initialize()
-			g.mode = ModeGame
-		}
-	case ModePause:
-		if ebiten.IsKeyPressed(ebiten.KeySpace) || buttonsJustPressed["FBR"] {
-			g.mode = ModeGame
+	g.debug.update()
+	_, running := g.scenes.Top().(*worldScene)
+	running = running && !g.scenes.Transitioning()
+	if err := g.scenes.Update(); err != nil {
+		return err
+	}
+	// presses made in a menu are not kept for the world behind it
+	if !running {
+		for _, cp := range g.players {
+			cp.input.idle()
 		}
-	case ModeGame:
-		g.player.MoveDirs = map[actors.Direction]bool{
+	}
+	g.sendSnapshot()
+	g.updateConsole()
+	g.updateHUD()
+	g.sounds.Update()
+	if g.quit {
+		g.stopNetwork()
+		return ebiten.Termination
+	}
+
+	return nil
+}
+
+// updateWorld moves everything in the world one frame forward and handles the controls of the players.
+func (g *Game) updateWorld() {
+	for _, enemy := range g.enemies {
+		enemy.MoveDirs = map[actors.Direction]bool{
 			actors.Up:    false,
 			actors.Down:  false,
 			actors.Right: false,
 			actors.Left:  false,
 		}
 
-		for _, enemy := range g.enemies {
-			enemy.MoveDirs = map[actors.Direction]bool{
-				actors.Up:    false,
-				actors.Down:  false,
-				actors.Right: false,
-				actors.Left:  false,
-			}
-
-			enemy.UpdateBullets()
-		}
-
-		g.frameCount += 1
-
-		g.player.UpdateBullets()
-
-		// weapon switching
-		if inpututil.IsKeyJustPressed(ebiten.Key1) {
-			g.player.DrawWeapon(actors.Revolver)
-		}
-
-		if inpututil.IsKeyJustPressed(ebiten.Key0) {
-			g.player.DrawWeapon(actors.Fists)
-		}
-
-		if buttonsJustPressed["RT"] {
-			g.player.DrawWeapon((g.player.CurrentWeapon + 1) % (actors.Revolver + 1))
-		}
-
-		directionKeyPressed := false
-		if ebiten.IsKeyPressed(ebiten.KeyS) || g.yLeftAxis > 0.5 {
-			g.player.Move(actors.Down)
-			directionKeyPressed = true
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyDown) || g.yRightAxis > 0.5 {
-			g.player.Look(actors.Down)
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyZ) || ebiten.IsKeyPressed(ebiten.KeyW) || g.yLeftAxis < -0.5 {
-			g.player.Move(actors.Up)
-			directionKeyPressed = true
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyUp) || g.yRightAxis < -0.5 {
-			g.player.Look(actors.Up)
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyD) || g.xLeftAxis > 0.5 {
-			g.player.Move(actors.Right)
-			g.player.UpdateHitbox()
-			directionKeyPressed = true
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyRight) || g.xRightAxis > 0.5 {
-			g.player.Look(actors.Right)
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyQ) || ebiten.IsKeyPressed(ebiten.KeyA) || g.xLeftAxis < -0.5 {
-			g.player.Move(actors.Left)
-			g.player.UpdateHitbox()
-			directionKeyPressed = true
-		}
-
-		if ebiten.IsKeyPressed(ebiten.KeyLeft) || g.xRightAxis < -0.5 {
-			g.player.Look(actors.Left)
-		}
-
-		if inpututil.IsKeyJustPressed(ebiten.KeyShiftLeft) || buttonsJustPressed["FTL"] {
-			// TODO: only dodge when stamina is replenished
-			g.player.CurrentAction = actors.Action{
-				Duration: g.player.DodgeDuration,
-				Type:     actors.Dodge,
-				Actor:    g.player,
-			}
-		}
+		enemy.UpdateBullets()
+	}
 
-		g.player.Act(g.frameCount)
+	g.frameCount += 1
 
-		if directionKeyPressed && g.frameCount%g.player.AnimationSpeed == 0 {
-			g.player.Animate()
-		}
+	for _, cp := range g.players {
+		g.controlPlayer(cp)
+	}
+	g.leashPlayers()
+	g.revivePlayers()
 
-		if !directionKeyPressed {
-			g.player.StopAnimation()
-		}
+	g.updateHorses()
 
-		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || buttonsJustPressed["FTR"] {
-			g.player.Shoot()
+	for _, enemy := range g.enemies {
+		if enemy.Dead || enemy.Capture != actors.Free {
+			continue
 		}
+		target := g.enemyTarget(enemy)
+		enemy.ThinkAndAct(target, target.Bullets, g.frameCount)
+	}
 
-		for _, enemy := range g.enemies {
-			if enemy.Dead {
-				continue
+	// the gang listens to player one
+	g.commandCompanions(g.players[0].input)
+	g.routeCompanions()
+	g.updateCompanions()
+
+	g.updateLassos()
+	g.updateCaptives()
+	g.CheckCollisions()
+	g.updateBounty()
+	g.checkHorseCollisions()
+	g.checkCompanionCollisions()
+	g.updateEnvironment()
+	g.updateParticles()
+	g.updateSounds()
+
+	if slices.ContainsFunc(g.localPlayers(), func(cp *coopPlayer) bool { return cp.input.pausePressed() }) {
+		g.openPause()
+	}
+	if g.god {
+		for _, cp := range g.players {
+			if !cp.Dead {
+				cp.Health = cp.MaxHealth
 			}
-			enemy.ThinkAndAct(g.player, g.player.Bullets, g.frameCount)
-		}
-
-		g.CheckCollisions()
-
-		if ebiten.IsKeyPressed(ebiten.KeyP) || buttonsJustPressed["FBR"] {
-			g.mode = ModePause
 		}
+	}
+	g.downPlayers()
+	if g.allDown() {
+		g.openGameOver()
+	}
 
-		if g.frameCount%g.maxFramCount == 0 {
-			g.frameCount = 1
-		}
+	if g.frameCount%g.maxFramCount == 0 {
+		g.frameCount = 1
 	}
-	return nil
 }
 
 // Draw draws the game screen.
@@ -701,84 +976,80 @@ func (g *Game) Update() error {
 func (g *Game) Draw(screen *ebiten.Image) {
 	// Write your game's rendering.
 	screen.Fill(g.backgroundColor)
-	switch g.mode {
-	case ModeTitle:
-		for _, cactus := range g.cacti {
-			cactus.Draw(screen, g.camX, g.camY)
-		}
-		for _, enemy := range g.enemies {
-			enemy.Draw(screen, g.camX, g.camY)
-		}
+	g.scenes.Draw(screen)
+	g.drawDebug(screen)
+}
 
-		g.player.Draw(screen, g.camX, g.camY)
+// drawWorld draws everything in the world around the camera.
+func (g *Game) drawWorld(screen *ebiten.Image) {
+	g.drawGround(screen)
+	for _, cactus := range g.cacti {
+		cactus.Draw(screen, g.camX, g.camY)
+	}
+	for _, shopkeeper := range g.shopkeepers {
+		shopkeeper.Draw(screen, g.camX, g.camY)
+	}
+	g.bountyBoard.Draw(screen, g.camX, g.camY)
+	for _, npc := range g.townsfolk {
+		npc.Draw(screen, g.camX, g.camY)
+	}
+	g.drawHorses(screen)
+	for _, enemy := range g.enemies {
+		enemy.Draw(screen, g.camX, g.camY)
+		enemy.DrawBullets(screen, g.camX, g.camY)
+	}
+	g.drawBountyTarget(screen)
+	g.drawLassos(screen)
 
-		for i, l := range g.titleTexts {
-			tx := 0
-			if i > 0 {
-				tx = (len(g.titleTexts[i-1]) - len(l)) * g.titleFontSize / 2
-			}
-			g.titleDrawOptions.GeoM.Translate(float64(tx), float64(i+g.titleFontSize+g.newlinePadding))
-			text.Draw(screen, l, text.NewGoXFace(g.titleArcadeFont), g.titleDrawOptions)
-		}
-		g.titleDrawOptions.GeoM = g.titleGeoMatrix
+	g.drawCompanions(screen)
 
-	case ModeGameOver:
-		for i, l := range g.gameOverTexts {
-			tx := 0
-			if i > 0 {
-				tx = (len(g.gameOverTexts[i-1]) - len(l)) * g.fontSize / 2
-			}
-			g.gameOverDrawOptions.GeoM.Translate(float64(tx), float64(i+g.fontSize+g.newlinePadding))
-			text.Draw(screen, l, text.NewGoXFace(g.arcadeFont), g.gameOverDrawOptions)
-		}
-		g.gameOverDrawOptions.GeoM = g.gameOverGeoMatrix
+	for _, cp := range g.players {
+		cp.Draw(screen, g.camX, g.camY)
+		cp.DrawBullets(screen, g.camX, g.camY)
+	}
+	g.drawRevives(screen)
+	g.drawExplosives(screen)
+	g.particles.Draw(screen, g.camX, g.camY)
+}
 
-	case ModePause:
-		for _, cactus := range g.cacti {
-			cactus.Draw(screen, g.camX, g.camY)
-		}
-		for _, enemy := range g.enemies {
-			enemy.Draw(screen, g.camX, g.camY)
-			enemy.DrawBullets(screen, g.camX, g.camY)
+// drawStats lists every stat with the bonus from equipment and consumables.
+func (g *Game) drawStats(screen *ebiten.Image) {
+	face := text.NewGoXFace(g.shopFont)
+	drawOptions := &text.DrawOptions{}
+	drawOptions.ColorScale = g.titleFontColorScale
+	drawOptions.GeoM.Translate(float64(3*g.viewport.Width/5), float64(8*g.fontSize))
+	for _, stat := range actors.AllStats {
+		line := fmt.Sprintf("%-14s %4.0f", strings.ToUpper(stat.String()), g.player.Stats.Value(stat))
+		if bonus := g.player.Stats.Bonus(stat); bonus != 0 {
+			line += fmt.Sprintf(" (%+.0f)", bonus)
 		}
+		text.Draw(screen, line, face, drawOptions)
+		drawOptions.GeoM.Translate(0, float64(g.shopFontSize+g.newlinePadding/2))
+	}
 
-		g.player.Draw(screen, g.camX, g.camY)
-		g.player.DrawBullets(screen, g.camX, g.camY)
+	text.Draw(screen, fmt.Sprintf("%-14s %4d", "REPUTATION", g.player.Reputation), face, drawOptions)
+	drawOptions.GeoM.Translate(0, float64(g.shopFontSize+g.newlinePadding/2))
 
-		for i, l := range g.pauseTexts {
-			tx := 0
-			if i > 0 {
-				tx = (len(g.pauseTexts[i-1]) - len(l)) * g.fontSize / 2
-			}
-			g.pauseDrawOptions.GeoM.Translate(float64(tx), float64(i+g.fontSize+g.newlinePadding))
-			text.Draw(screen, l, text.NewGoXFace(g.arcadeFont), g.pauseDrawOptions)
+	drawOptions.GeoM.Translate(0, float64(g.shopFontSize+g.newlinePadding/2))
+	for _, slot := range actors.EquipSlots {
+		worn := "-"
+		if equipment, ok := g.player.Equipped[slot]; ok {
+			worn = equipment.Item.Name
 		}
-		g.pauseDrawOptions.GeoM = g.pauseGeoMatrix
-
-	case ModeGame:
-		for _, cactus := range g.cacti {
-			cactus.Draw(screen, g.camX, g.camY)
-			// cactus.DrawHitbox(screen, g.camX, g.camY)
-		}
-		for _, enemy := range g.enemies {
-			enemy.Draw(screen, g.camX, g.camY)
-			enemy.DrawBullets(screen, g.camX, g.camY)
-			// enemy.DrawHitbox(screen, g.camX, g.camY)
-		}
-
-		// TODO: for debugging (remove eventually)
-		// textDrawOptions := &text.DrawOptions{}
-		// textDrawOptions.GeoM.Translate(10, 10)
-		// text.Draw(screen, fmt.Sprintf("%+v", g.buttonsPressed), text.NewGoXFace(g.playerHealthBarFont), textDrawOptions)
-
-		g.player.Draw(screen, g.camX, g.camY)
-		// g.player.DrawHitbox(screen, g.camX, g.camY)
-		g.player.DrawBullets(screen, g.camX, g.camY)
+		text.Draw(screen, fmt.Sprintf("%-14s %s", strings.ToUpper(slot.String()), worn), face, drawOptions)
+		drawOptions.GeoM.Translate(0, float64(g.shopFontSize+g.newlinePadding/2))
 	}
 }
 
 // Layout takes the outside size (e.g., the window size) and returns the (logical) screen size.
 // If you don't have to adjust the screen size with the outside size, just return a fixed size.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return ScreenWidth, ScreenHeight
+	width, height := g.viewport.Layout(outsideWidth, outsideHeight)
+	g.hud.Layout(width, height)
+	return width, height
+}
+
+// DrawFinalScreen draws the screen into the window the way the scaling setting asks for.
+func (g *Game) DrawFinalScreen(screen ebiten.FinalScreen, offscreen *ebiten.Image, geoM ebiten.GeoM) {
+	g.viewport.DrawFinalScreen(screen, offscreen, geoM)
 }
@@ -7,8 +7,10 @@ import (
 	"math/rand"
 
 	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/economy"
 	"github.com/bramca/Far-West/world"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 func LoadImage(assets embed.FS, imagePath string) (*ebiten.Image, error) {
@@ -31,92 +33,32 @@ func LoadImage(assets embed.FS, imagePath string) (*ebiten.Image, error) {
 	return ebiten.NewImageFromImage(img), nil
 }
 
-func InitializeCactusHitboxes() []*actors.HitBox {
-	result := []*actors.HitBox{
-		{
-			X: 11,
-			Y: 12,
-			W: 7,
-			H: 13,
-		},
-		{
-			X: 10,
-			Y: 12,
-			W: 9,
-			H: 13,
-		},
-		{
-			X: 11,
-			Y: 9,
-			W: 8,
-			H: 16,
-		},
-		{
-			X: 11,
-			Y: 6,
-			W: 8,
-			H: 19,
-		},
-		{
-			X: 11,
-			Y: 16,
-			W: 7,
-			H: 9,
-		},
-		{
-			X: 11,
-			Y: 12,
-			W: 8,
-			H: 13,
-		},
-	}
-
-	return result
-}
-
-func LoadSprites(assets embed.FS, fileNames []string, xFrameSize int, yFrameSize int) []*ebiten.Image {
-	result := []*ebiten.Image{}
-	for _, fileName := range fileNames {
-		img, err := LoadImage(assets, fileName)
-		if err != nil {
-			panic(fmt.Sprintf("could not load player sprite: %e", err))
-		}
-
-		verticalFrames := int(img.Bounds().Dy() / yFrameSize)
-		horizontalFrames := int(img.Bounds().Dx() / xFrameSize)
-
-		for i := range verticalFrames {
-			for j := range horizontalFrames {
-				subImg := img.SubImage(image.Rect(j*xFrameSize, i*yFrameSize, (j+1)*xFrameSize, (i+1)*yFrameSize)).(*ebiten.Image)
-				result = append(result, subImg)
-			}
-		}
-	}
-
-	return result
-}
-
-func SpawnCacti(xBound, yBound int, amount int, spriteScale float64, cactusSprites []*ebiten.Image, hitboxes []*actors.HitBox) []*world.Cactus {
+func SpawnCacti(r *rand.Rand, xBound, yBound int, amount int, spriteScale float64, cactusSprites, damagedSprites, destroyedSprites []*ebiten.Image, hitboxes []*actors.HitBox, health int) []*world.Cactus {
 	cacti := []*world.Cactus{}
 	for range amount {
-		x := float64(rand.Intn(xBound))
-		y := float64(rand.Intn(yBound))
-		i := rand.Intn(len(cactusSprites))
+		x := float64(r.Intn(xBound))
+		y := float64(r.Intn(yBound))
+		i := r.Intn(len(cactusSprites))
 		sprite := cactusSprites[i]
 		hitbox := hitboxes[i]
 		cactus := &world.Cactus{
-			X:           x,
-			Y:           y,
-			W:           float64(sprite.Bounds().Dx()),
-			H:           float64(sprite.Bounds().Dy()),
-			Sprite:      sprite,
-			DrawOptions: &ebiten.DrawImageOptions{},
-			Scale:       spriteScale,
+			X:               x,
+			Y:               y,
+			W:               float64(sprite.Bounds().Dx()),
+			H:               float64(sprite.Bounds().Dy()),
+			Sprite:          sprite,
+			DamagedSprite:   damagedSprites[i],
+			DestroyedSprite: destroyedSprites[i],
+			DrawOptions:     &ebiten.DrawImageOptions{},
+			Scale:           spriteScale,
+			Health:          health,
+			MaxHealth:       health,
 			Hitbox: &actors.HitBox{
-				X: float32(x + float64(hitbox.X*float32(spriteScale))),
-				Y: float32(y + float64(hitbox.Y*float32(spriteScale))),
-				W: hitbox.W * float32(spriteScale),
-				H: hitbox.H * float32(spriteScale),
+				X:    float32(x + float64(hitbox.X*float32(spriteScale))),
+				Y:    float32(y + float64(hitbox.Y*float32(spriteScale))),
+				W:    hitbox.W * float32(spriteScale),
+				H:    hitbox.H * float32(spriteScale),
+				Mask: hitbox.Mask,
 			},
 		}
 		cacti = append(cacti, cactus)
@@ -124,3 +66,34 @@ func SpawnCacti(xBound, yBound int, amount int, spriteScale float64, cactusSprit
 
 	return cacti
 }
+
+// SpawnShopkeepers places one shopkeeper per shop on a row starting at x, y.
+func SpawnShopkeepers(x, y, spacing float64, sprite *ebiten.Image, shops []*economy.Shop, font *text.GoXFace) []*world.Shopkeeper {
+	shopkeepers := []*world.Shopkeeper{}
+	spriteScale := 2.0
+	for i, shop := range shops {
+		shopX := x + float64(i)*spacing
+		shopkeeper := &world.Shopkeeper{
+			X:              shopX,
+			Y:              y,
+			W:              float64(sprite.Bounds().Dx()),
+			H:              float64(sprite.Bounds().Dy()),
+			Sprite:         sprite,
+			DrawOptions:    &ebiten.DrawImageOptions{},
+			Scale:          spriteScale,
+			Shop:           shop,
+			InteractRadius: 80,
+			TextFont:       font,
+			Hitbox: &actors.HitBox{
+				X: float32(shopX),
+				Y: float32(y),
+				W: float32(float64(sprite.Bounds().Dx()) * spriteScale),
+				H: float32(float64(sprite.Bounds().Dy()) * spriteScale),
+			},
+		}
+		shopkeeper.DrawOptions.ColorScale.Scale(0.8, 0.9, 1.2, 1)
+		shopkeepers = append(shopkeepers, shopkeeper)
+	}
+
+	return shopkeepers
+}
@@ -0,0 +1,453 @@
+package helpers
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"path"
+	"sort"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/hitmask"
+	"github.com/bramca/Far-West/piskel"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SpriteFrame is a single frame of a sprite sheet. The pivot is the point the
+// frame stands on and the hitbox is in the pixels of the frame, before scaling.
+type SpriteFrame struct {
+	Image  *ebiten.Image
+	Pivot  utils.Point
+	Hitbox *actors.HitBox
+	// FPS of the source the frame was loaded from
+	FPS int
+}
+
+type SpriteSheet struct {
+	Name       string
+	Frames     []*SpriteFrame
+	Animations map[string]*actors.Animation
+	FPS        int
+}
+
+// Manifest holds every sprite sheet of the game, as described in the sprite manifest.
+type Manifest struct {
+	Sheets map[string]*SpriteSheet
+	// Enemies are the sheets an enemy can be spawned with
+	Enemies []*SpriteSheet
+	// a cactus variant is a frame, the damage stages have a frame for every variant
+	Cacti          *SpriteSheet
+	DamagedCacti   *SpriteSheet
+	DestroyedCacti *SpriteSheet
+
+	pivots map[*ebiten.Image]utils.Point
+}
+
+type pointDef struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type hitboxDef struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+
+type frameDef struct {
+	Pivot  *pointDef  `json:"pivot"`
+	Hitbox *hitboxDef `json:"hitbox"`
+}
+
+type sheetDef struct {
+	// Sources are piskel files or png sprite sheets, their frames are appended in order
+	Sources []string `json:"sources"`
+	// FrameWidth and FrameHeight are only needed for png sources
+	FrameWidth  int `json:"frameWidth"`
+	FrameHeight int `json:"frameHeight"`
+	// FPS overrides the fps of the piskel sources
+	FPS int `json:"fps"`
+	// Pivot and Hitbox are used for every frame that does not set its own
+	Pivot  *pointDef        `json:"pivot"`
+	Hitbox *hitboxDef       `json:"hitbox"`
+	Frames map[int]frameDef `json:"frames"`
+	// Animations is the name of an animation set
+	Animations string `json:"animations"`
+
+	// AutoHitbox fits a hitbox around the opaque pixels of every frame without one
+	AutoHitbox bool `json:"autoHitbox"`
+	// PixelPerfect gives every hitbox a mask of the opaque pixels inside it
+	PixelPerfect bool `json:"pixelPerfect"`
+	// AlphaThreshold is the alpha a pixel needs to be opaque, hitmask.DefaultThreshold when zero
+	AlphaThreshold uint8 `json:"alphaThreshold"`
+}
+
+type animationDef struct {
+	Frames []int `json:"frames"`
+	// Durations are in game frames, without them the fps of the frame sources is used
+	Durations []int                `json:"durations"`
+	FPS       int                  `json:"fps"`
+	Mode      actors.AnimationMode `json:"mode"`
+	Events    map[int]string       `json:"events"`
+}
+
+type manifestDef struct {
+	Sheets        map[string]sheetDef                `json:"sheets"`
+	AnimationSets map[string]map[string]animationDef `json:"animationSets"`
+	Enemies       []string                           `json:"enemies"`
+	Cacti         struct {
+		Healthy   string `json:"healthy"`
+		Damaged   string `json:"damaged"`
+		Destroyed string `json:"destroyed"`
+	} `json:"cacti"`
+}
+
+// LoadManifest reads the sprite manifest and every sheet in it, the required sheets
+// have to be in the manifest. Enemy sheets need all humanoid animations.
+func LoadManifest(assets embed.FS, manifestPath string, ticksPerSecond int, required []string) (*Manifest, error) {
+	data, err := assets.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sprite manifest: %w", err)
+	}
+	var def manifestDef
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse sprite manifest %s: %w", manifestPath, err)
+	}
+
+	manifest := &Manifest{
+		Sheets: map[string]*SpriteSheet{},
+		pivots: map[*ebiten.Image]utils.Point{},
+	}
+	names := []string{}
+	for name := range def.Sheets {
+		names = append(names, name)
+	}
+	// sorted so the first error is the same every run
+	sort.Strings(names)
+	for _, name := range names {
+		sheet, err := loadSheet(assets, name, def.Sheets[name], def.AnimationSets, ticksPerSecond)
+		if err != nil {
+			return nil, fmt.Errorf("sprite manifest %s: sheet %q: %w", manifestPath, name, err)
+		}
+		manifest.Sheets[name] = sheet
+		for _, frame := range sheet.Frames {
+			manifest.pivots[frame.Image] = frame.Pivot
+		}
+	}
+
+	for _, name := range required {
+		if _, ok := manifest.Sheets[name]; !ok {
+			return nil, fmt.Errorf("sprite manifest %s: missing sheet %q", manifestPath, name)
+		}
+	}
+	if len(def.Enemies) == 0 {
+		return nil, fmt.Errorf("sprite manifest %s: no enemy sheets", manifestPath)
+	}
+	for _, name := range def.Enemies {
+		sheet, ok := manifest.Sheets[name]
+		if !ok {
+			return nil, fmt.Errorf("sprite manifest %s: unknown enemy sheet %q", manifestPath, name)
+		}
+		if err := sheet.RequireAnimations(actors.HumanoidAnimations()); err != nil {
+			return nil, fmt.Errorf("sprite manifest %s: enemy sheet %q: %w", manifestPath, name, err)
+		}
+		manifest.Enemies = append(manifest.Enemies, sheet)
+	}
+	if err := manifest.loadCacti(def); err != nil {
+		return nil, fmt.Errorf("sprite manifest %s: %w", manifestPath, err)
+	}
+
+	return manifest, nil
+}
+
+func (m *Manifest) loadCacti(def manifestDef) error {
+	stages := []**SpriteSheet{&m.Cacti, &m.DamagedCacti, &m.DestroyedCacti}
+	for i, name := range []string{def.Cacti.Healthy, def.Cacti.Damaged, def.Cacti.Destroyed} {
+		sheet, ok := m.Sheets[name]
+		if !ok {
+			return fmt.Errorf("unknown cactus sheet %q", name)
+		}
+		*stages[i] = sheet
+	}
+	if len(m.DamagedCacti.Frames) != len(m.Cacti.Frames) || len(m.DestroyedCacti.Frames) != len(m.Cacti.Frames) {
+		return fmt.Errorf("cactus sheets need the same number of frames, got %d, %d and %d",
+			len(m.Cacti.Frames), len(m.DamagedCacti.Frames), len(m.DestroyedCacti.Frames))
+	}
+	for i, frame := range m.Cacti.Frames {
+		if frame.Hitbox == nil {
+			return fmt.Errorf("cactus sheet %q: frame %d has no hitbox", def.Cacti.Healthy, i)
+		}
+	}
+
+	return nil
+}
+
+// Sheet panics on unknown names, required sheets are checked when the manifest is loaded.
+func (m *Manifest) Sheet(name string) *SpriteSheet {
+	sheet, ok := m.Sheets[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown sprite sheet %q", name))
+	}
+
+	return sheet
+}
+
+// Pivot looks up the pivot of a frame image of any sheet in the manifest.
+func (m *Manifest) Pivot(img *ebiten.Image) (utils.Point, bool) {
+	pivot, ok := m.pivots[img]
+	return pivot, ok
+}
+
+func (s *SpriteSheet) Images() []*ebiten.Image {
+	images := []*ebiten.Image{}
+	for _, frame := range s.Frames {
+		images = append(images, frame.Image)
+	}
+
+	return images
+}
+
+func (s *SpriteSheet) Hitboxes() []*actors.HitBox {
+	hitboxes := []*actors.HitBox{}
+	for _, frame := range s.Frames {
+		hitboxes = append(hitboxes, frame.Hitbox)
+	}
+
+	return hitboxes
+}
+
+// FrameDuration is how many game ticks a frame stays on screen at the fps of the sheet.
+func (s *SpriteSheet) FrameDuration(ticksPerSecond int) int {
+	if s.FPS <= 0 {
+		return ticksPerSecond
+	}
+
+	return max(1, ticksPerSecond/s.FPS)
+}
+
+func (s *SpriteSheet) RequireAnimations(names []string) error {
+	for _, name := range names {
+		if _, ok := s.Animations[name]; !ok {
+			return fmt.Errorf("missing animation %q", name)
+		}
+	}
+
+	return nil
+}
+
+func loadSheet(assets embed.FS, name string, def sheetDef, sets map[string]map[string]animationDef, ticksPerSecond int) (*SpriteSheet, error) {
+	if len(def.Sources) == 0 {
+		return nil, fmt.Errorf("no sources")
+	}
+	sheet := &SpriteSheet{
+		Name:       name,
+		Animations: map[string]*actors.Animation{},
+		FPS:        def.FPS,
+	}
+
+	// the decoded frames, masks are read from these since ebiten images can not be read before the game runs
+	raw := []image.Image{}
+	for _, source := range def.Sources {
+		images, fps, err := loadSource(assets, source, def)
+		if err != nil {
+			return nil, err
+		}
+		if sheet.FPS == 0 {
+			sheet.FPS = fps
+		}
+		if def.FPS > 0 {
+			fps = def.FPS
+		}
+		for _, frame := range images {
+			img := ebiten.NewImageFromImage(frame)
+			raw = append(raw, frame)
+			sheet.Frames = append(sheet.Frames, &SpriteFrame{
+				Image:  img,
+				FPS:    fps,
+				Pivot:  def.Pivot.point(img),
+				Hitbox: def.Hitbox.hitbox(),
+			})
+		}
+	}
+
+	for index, frame := range def.Frames {
+		if index < 0 || index >= len(sheet.Frames) {
+			return nil, fmt.Errorf("frame %d does not exist, the sheet has %d frames", index, len(sheet.Frames))
+		}
+		if frame.Pivot != nil {
+			sheet.Frames[index].Pivot = frame.Pivot.point(sheet.Frames[index].Image)
+		}
+		if frame.Hitbox != nil {
+			sheet.Frames[index].Hitbox = frame.Hitbox.hitbox()
+		}
+	}
+	if def.AutoHitbox || def.PixelPerfect {
+		if err := sheet.fitHitboxes(raw, def); err != nil {
+			return nil, err
+		}
+	}
+
+	if def.Animations == "" {
+		return sheet, nil
+	}
+	set, ok := sets[def.Animations]
+	if !ok {
+		return nil, fmt.Errorf("unknown animation set %q", def.Animations)
+	}
+	for animationName, animationDef := range set {
+		animation, err := sheet.buildAnimation(animationName, animationDef, ticksPerSecond)
+		if err != nil {
+			return nil, fmt.Errorf("animation %q: %w", animationName, err)
+		}
+		sheet.Animations[animationName] = animation
+	}
+
+	return sheet, nil
+}
+
+func loadSource(assets embed.FS, source string, def sheetDef) ([]image.Image, int, error) {
+	switch path.Ext(source) {
+	case ".piskel":
+		file, err := piskel.Load(assets, source)
+		if err != nil {
+			return nil, 0, err
+		}
+		return file.Frames, file.FPS, nil
+	case ".png":
+		if def.FrameWidth <= 0 || def.FrameHeight <= 0 {
+			return nil, 0, fmt.Errorf("png source %s needs frameWidth and frameHeight", source)
+		}
+		img, err := decodeImage(assets, source)
+		if err != nil {
+			return nil, 0, fmt.Errorf("source %s: %w", source, err)
+		}
+		if img.Bounds().Dx()%def.FrameWidth != 0 || img.Bounds().Dy()%def.FrameHeight != 0 {
+			return nil, 0, fmt.Errorf("source %s is %dx%d, not a multiple of the %dx%d frame size",
+				source, img.Bounds().Dx(), img.Bounds().Dy(), def.FrameWidth, def.FrameHeight)
+		}
+		images := []image.Image{}
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y += def.FrameHeight {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x += def.FrameWidth {
+				images = append(images, img.SubImage(image.Rect(x, y, x+def.FrameWidth, y+def.FrameHeight)))
+			}
+		}
+		return images, 0, nil
+	}
+
+	return nil, 0, fmt.Errorf("source %s is not a piskel or png file", source)
+}
+
+func decodeImage(assets embed.FS, imagePath string) (*image.NRGBA, error) {
+	file, err := assets.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image file: %w", err)
+	}
+	nrgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return nrgba, nil
+}
+
+// fitHitboxes fits the missing hitboxes around the opaque pixels of their frame
+// and gives every hitbox a pixel mask when the sheet is pixel perfect.
+func (s *SpriteSheet) fitHitboxes(raw []image.Image, def sheetDef) error {
+	threshold := def.AlphaThreshold
+	if threshold == 0 {
+		threshold = hitmask.DefaultThreshold
+	}
+	for i, frame := range s.Frames {
+		if frame.Hitbox == nil && def.AutoHitbox {
+			bounds := hitmask.Bounds(raw[i], threshold)
+			if bounds.Empty() {
+				return fmt.Errorf("frame %d has no opaque pixels to fit a hitbox around", i)
+			}
+			frame.Hitbox = &actors.HitBox{
+				X: float32(bounds.Min.X),
+				Y: float32(bounds.Min.Y),
+				W: float32(bounds.Dx()),
+				H: float32(bounds.Dy()),
+			}
+		}
+		if frame.Hitbox != nil && def.PixelPerfect {
+			h := frame.Hitbox
+			rect := image.Rect(int(h.X), int(h.Y), int(h.X+h.W), int(h.Y+h.H))
+			if rect.Empty() {
+				return fmt.Errorf("frame %d has an empty hitbox", i)
+			}
+			h.Mask = hitmask.New(raw[i], rect, threshold)
+		}
+	}
+
+	return nil
+}
+
+func (s *SpriteSheet) buildAnimation(name string, def animationDef, ticksPerSecond int) (*actors.Animation, error) {
+	if len(def.Frames) == 0 {
+		return nil, fmt.Errorf("no frames")
+	}
+	if len(def.Durations) > 0 && len(def.Durations) != len(def.Frames) {
+		return nil, fmt.Errorf("%d durations for %d frames", len(def.Durations), len(def.Frames))
+	}
+	animation := &actors.Animation{
+		Name: name,
+		Mode: def.Mode,
+	}
+	for i, index := range def.Frames {
+		if index < 0 || index >= len(s.Frames) {
+			return nil, fmt.Errorf("frame %d does not exist, the sheet has %d frames", index, len(s.Frames))
+		}
+		fps := s.Frames[index].FPS
+		if def.FPS > 0 {
+			fps = def.FPS
+		}
+		duration := ticksPerSecond
+		if fps > 0 {
+			duration = max(1, ticksPerSecond/fps)
+		}
+		if len(def.Durations) > 0 {
+			duration = def.Durations[i]
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("frame %d has no duration", i)
+		}
+		animation.Frames = append(animation.Frames, actors.Frame{
+			Sprite:   index,
+			Duration: duration,
+			Event:    def.Events[i],
+		})
+	}
+	for i := range def.Events {
+		if i < 0 || i >= len(def.Frames) {
+			return nil, fmt.Errorf("event on frame %d, the animation has %d frames", i, len(def.Frames))
+		}
+	}
+
+	return animation, nil
+}
+
+// point defaults to the middle of the frame.
+func (p *pointDef) point(img *ebiten.Image) utils.Point {
+	if p == nil {
+		return utils.Point{X: float64(img.Bounds().Dx()) / 2, Y: float64(img.Bounds().Dy()) / 2}
+	}
+
+	return utils.Point{X: p.X, Y: p.Y}
+}
+
+func (h *hitboxDef) hitbox() *actors.HitBox {
+	if h == nil {
+		return nil
+	}
+
+	return &actors.HitBox{X: h.X, Y: h.Y, W: h.W, H: h.H}
+}
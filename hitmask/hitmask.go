@@ -0,0 +1,73 @@
+// Package hitmask finds the opaque pixels of sprite frames, for tight hitboxes
+// and pixel perfect collisions.
+package hitmask
+
+import (
+	"image"
+)
+
+// DefaultThreshold is the alpha a pixel needs to count as solid.
+const DefaultThreshold = 128
+
+// Bounds is the smallest rectangle around all solid pixels of img,
+// it is empty when the image has no solid pixels.
+func Bounds(img image.Image, threshold uint8) image.Rectangle {
+	bounds := image.Rectangle{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !solid(img, x, y, threshold) {
+				continue
+			}
+			pixel := image.Rect(x, y, x+1, y+1)
+			if bounds.Empty() {
+				bounds = pixel
+			} else {
+				bounds = bounds.Union(pixel)
+			}
+		}
+	}
+
+	return bounds.Sub(b.Min)
+}
+
+func solid(img image.Image, x, y int, threshold uint8) bool {
+	_, _, _, a := img.At(x, y).RGBA()
+	return a>>8 >= uint32(threshold)
+}
+
+// Mask marks the solid pixels of a rectangle of a sprite frame.
+type Mask struct {
+	W, H int
+	bits []uint64
+}
+
+// New builds the mask of the part of img inside rect, rect is relative to the image bounds.
+func New(img image.Image, rect image.Rectangle, threshold uint8) *Mask {
+	mask := &Mask{
+		W:    rect.Dx(),
+		H:    rect.Dy(),
+		bits: make([]uint64, (rect.Dx()*rect.Dy()+63)/64),
+	}
+	origin := img.Bounds().Min.Add(rect.Min)
+	for y := range mask.H {
+		for x := range mask.W {
+			if solid(img, origin.X+x, origin.Y+y, threshold) {
+				i := y*mask.W + x
+				mask.bits[i/64] |= 1 << (i % 64)
+			}
+		}
+	}
+
+	return mask
+}
+
+// At reports whether the pixel is solid, pixels outside of the mask never are.
+func (m *Mask) At(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.W || y >= m.H {
+		return false
+	}
+	i := y*m.W + x
+
+	return m.bits[i/64]&(1<<(i%64)) != 0
+}
@@ -0,0 +1,167 @@
+package farwest
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/hud"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	horseMountRadius  = 60.0
+	horseFleeDuration = 180
+)
+
+func (g *Game) newHorse(x, y float64) *actors.Horse {
+	horse := &actors.Horse{
+		X:              x,
+		Y:              y,
+		W:              float64(g.horseSprites[actors.HorseRight].Bounds().Dx()),
+		H:              float64(g.horseSprites[actors.HorseRight].Bounds().Dy()),
+		Sprites:        g.horseSprites,
+		Scale:          2,
+		Speed:          4.0,
+		Stamina:        100,
+		MaxStamina:     100,
+		StaminaRegen:   0.3,
+		StaminaDrain:   0.2,
+		Health:         15,
+		MaxHealth:      15,
+		AnimationSpeed: g.horseAnimationSpeed,
+		DrawOptions:    &ebiten.DrawImageOptions{},
+		Hitbox: &actors.HitBox{
+			W: float32(g.horseSprites[actors.HorseRight].Bounds().Dx()) * 4 / 3,
+			H: float32(g.horseSprites[actors.HorseRight].Bounds().Dy()),
+		},
+	}
+	horse.Healthbar = &hud.ResourceBar{
+		W:          horse.W,
+		H:          enemyHealthBarSize,
+		Points:     horse.Health,
+		MaxPoints:  horse.MaxHealth,
+		FillColor:  g.enemyHealthbarColors[0],
+		EmptyColor: g.enemyHealthbarColors[1],
+		TextFont:   text.NewGoXFace(g.enemyHealthBarFont),
+		FontColor:  color.RGBA{0, 0, 0, 240},
+		FontSize:   g.enemyHealthBarFontSize,
+	}
+	horse.Healthbar.SetDrawOptions()
+	horse.UpdateHitbox()
+
+	return horse
+}
+
+// nearestHorse returns the closest horse p can climb on, or nil.
+func (g *Game) nearestHorse(p *actors.Player) *actors.Horse {
+	var nearest *actors.Horse
+	nearestDist := horseMountRadius
+	for _, horse := range g.horses {
+		if horse.Dead || horse.Rider != nil {
+			continue
+		}
+		dist := utils.DistanceBetweenPoints(p.X, p.Y, horse.X+horse.W, horse.Y+horse.H)
+		if dist <= nearestDist {
+			nearest = horse
+			nearestDist = dist
+		}
+	}
+
+	return nearest
+}
+
+func (g *Game) updateHorses() {
+	for _, cp := range g.players {
+		if cp.Horse != nil {
+			cp.Horse.FollowRider(cp.moving, g.frameCount)
+		}
+	}
+	for _, horse := range g.horses {
+		horse.Update(g.frameCount)
+	}
+}
+
+func (g *Game) shootHorse(horse *actors.Horse, bullet *actors.Bullet) {
+	alive := !horse.Dead
+	horse.Shot(bullet.Damage, bullet.X, bullet.Y, horseFleeDuration)
+	if alive && horse.Dead {
+		g.sounds.PlayAt(sound.Death, horse.X, horse.Y)
+	}
+	hit := actors.Hit{
+		X:        horse.X + horse.W/2,
+		Y:        horse.Y,
+		Color:    color.RGBA{255, 255, 255, 240},
+		Msg:      "-" + strconv.Itoa(bullet.Damage),
+		TextFont: text.NewGoXFace(g.hitTextFont),
+		Duration: 2 * g.framesPerSecond / 3,
+	}
+	hit.SetDrawOptions()
+	horse.Hits = append(horse.Hits, hit)
+}
+
+// checkHorseCollisions lets stray bullets spook horses, a ridden horse throws off its rider,
+// and keeps fleeing horses from running through cacti.
+func (g *Game) checkHorseCollisions() {
+	shooters := g.playerActors()
+	for _, enemy := range g.enemies {
+		shooters = append(shooters, enemy.Player)
+	}
+
+	for _, horse := range g.horses {
+		if horse.Dead {
+			continue
+		}
+		for _, shooter := range shooters {
+			if horse.Rider != nil && !g.canSpook(shooter, horse.Rider) {
+				continue
+			}
+			for i := len(shooter.Bullets) - 1; i >= 0; i-- {
+				bullet := shooter.Bullets[i]
+				if bullet.Hitbox.CheckCollision(horse.Hitbox) {
+					g.shootHorse(horse, bullet)
+					shooter.Bullets = append(shooter.Bullets[:i], shooter.Bullets[i+1:]...)
+				}
+			}
+		}
+		if horse.FleeDuration > 0 {
+			for _, cactus := range g.cacti {
+				if horse.Hitbox.CheckCollision(cactus.Hitbox) {
+					horse.TurnAround()
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(g.horses) - 1; i >= 0; i-- {
+		if g.horses[i].Dead {
+			g.horses = append(g.horses[:i], g.horses[i+1:]...)
+		}
+	}
+}
+
+// canSpook tells whether the bullets of the shooter can hit the horse under the rider. Riders never hit
+// their own horse, and the players only hit the horses of their partners with friendly fire on.
+func (g *Game) canSpook(shooter, rider *actors.Player) bool {
+	if shooter == rider {
+		return false
+	}
+	if !shooter.IsNpc && !rider.IsNpc {
+		return g.settings.Coop.FriendlyFire
+	}
+
+	return true
+}
+
+func (g *Game) drawHorses(screen *ebiten.Image) {
+	for _, horse := range g.horses {
+		// ridden horses are drawn together with their rider
+		if horse.Rider == nil {
+			horse.Draw(screen, g.camX, g.camY)
+		}
+	}
+}
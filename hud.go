@@ -0,0 +1,174 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/hud"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	// the compass hides once the player is this close to the objective
+	objectiveReachedDistance = 200
+	// world pixels per meter shown on the compass
+	pixelsPerMeter       = 32
+	notificationDuration = 180
+)
+
+// objective is where the compass points.
+type objective struct {
+	Name string
+	X, Y float64
+}
+
+// playerHUD holds the widgets of the HUD that change with the players.
+type playerHUD struct {
+	*hud.HUD
+
+	money         *hud.Label
+	panels        []*playerPanel
+	compass       *hud.Compass
+	compassAnchor *hud.Element
+	notifications *hud.Notifications
+
+	weaponIcons map[actors.Weapon]*ebiten.Image
+}
+
+// playerPanel shows the quick item and the revolver of one player, next to their bars.
+type playerPanel struct {
+	player    *coopPlayer
+	name      *hud.Label
+	quickItem *hud.Label
+	// quickKey is the key or button that uses the quick item
+	quickKey string
+	weapon   *hud.Icon
+	cylinder *hud.Cylinder
+}
+
+// playerCorners are where the panels of the players go in co-op, in the order they joined.
+var playerCorners = [maxPlayers]hud.Anchor{hud.BottomLeft, hud.BottomRight}
+
+// newHUD lays out the HUD for the players on this machine, it is made again when someone joins or leaves.
+func (g *Game) newHUD() *playerHUD {
+	face := text.NewGoXFace(g.playerHealthBarFont)
+	shopFace := text.NewGoXFace(g.shopFont)
+	h := &playerHUD{
+		HUD:     hud.New(ScreenWidth, ScreenHeight),
+		money:   &hud.Label{Face: shopFace, Color: color.White},
+		compass: &hud.Compass{Radius: 26, Face: face},
+		notifications: &hud.Notifications{
+			Face:     shopFace,
+			Duration: notificationDuration,
+			Max:      4,
+		},
+		weaponIcons: map[actors.Weapon]*ebiten.Image{
+			actors.Fists:    g.sprites.Sheet("icon-fists").Images()[0],
+			actors.Revolver: g.sprites.Sheet("icon-revolver").Images()[0],
+			actors.Lasso:    g.sprites.Sheet("icon-lasso").Images()[0],
+		},
+	}
+	h.Zoom = g.settings.Accessibility.HUDScale
+
+	// the bottom stacks grow upwards in the order they are added. Alone the revolver sits
+	// in the other corner, in co-op every player keeps to their own corner.
+	local := g.localPlayers()
+	coop := len(local) > 1
+	for i, cp := range local {
+		panel := &playerPanel{
+			player:    cp,
+			name:      &hud.Label{Face: shopFace, Color: color.White},
+			quickItem: &hud.Label{Face: face, Color: color.White},
+			quickKey:  "[F]",
+			weapon:    &hud.Icon{Scale: 3},
+			cylinder:  &hud.Cylinder{Radius: 22, Face: shopFace},
+		}
+		// the players who joined only have a controller
+		if cp.ownsGamepad(g) {
+			panel.quickKey = "[B]"
+		}
+		h.panels = append(h.panels, panel)
+
+		barsAnchor, gunAnchor := hud.BottomLeft, hud.BottomRight
+		if coop {
+			barsAnchor, gunAnchor = playerCorners[i], playerCorners[i]
+		}
+		h.Add(barsAnchor, cp.Staminabar)
+		h.Add(barsAnchor, cp.Healthbar)
+		h.Add(barsAnchor, panel.quickItem)
+		h.Add(gunAnchor, panel.cylinder)
+		h.Add(gunAnchor, panel.weapon)
+		if coop {
+			h.Add(barsAnchor, panel.name)
+		}
+	}
+	h.Add(hud.TopLeft, h.money)
+	h.compassAnchor = h.Add(hud.TopRight, h.compass)
+	h.Add(hud.Top, h.notifications)
+
+	return h
+}
+
+// notify shows a short message at the top of the screen.
+func (g *Game) notify(format string, args ...any) {
+	g.hud.notifications.Push(strings.ToUpper(fmt.Sprintf(format, args...)))
+}
+
+// townObjective points the compass to the middle of the shops.
+func (g *Game) townObjective() *objective {
+	if len(g.shopkeepers) == 0 {
+		return nil
+	}
+	town := &objective{Name: "town"}
+	for _, shopkeeper := range g.shopkeepers {
+		town.X += shopkeeper.X / float64(len(g.shopkeepers))
+		town.Y += shopkeeper.Y / float64(len(g.shopkeepers))
+	}
+
+	return town
+}
+
+// updateHUD copies the state of the players into the widgets, it is called once per frame.
+func (g *Game) updateHUD() {
+	h := g.hud
+	h.money.Text = fmt.Sprintf("$%d", g.player.Money)
+
+	for _, panel := range h.panels {
+		p := panel.player
+		panel.name.Text = strings.ToUpper(p.name)
+		if p.Dead {
+			panel.name.Text += " - DOWN"
+		}
+
+		panel.quickItem.Text = panel.quickKey + " -"
+		if slot := p.Inventory.QuickItem(); slot != nil {
+			panel.quickItem.Text = fmt.Sprintf("%s %s x%d", panel.quickKey, slot.Item.Name, slot.Count)
+		}
+
+		panel.weapon.Image = h.weaponIcons[p.CurrentWeapon]
+		panel.cylinder.Loaded = p.Ammo
+		panel.cylinder.Chambers = p.CylinderSize
+		panel.cylinder.Reserve = p.Rounds()
+		panel.cylinder.Reload = 0
+		if p.ReloadTimer > 0 {
+			panel.cylinder.Reload = 1 - float64(p.ReloadTimer)/float64(p.ReloadDuration())
+		}
+	}
+
+	h.compassAnchor.Hidden = true
+	if g.objective != nil {
+		dist := utils.DistanceBetweenPoints(g.player.X, g.player.Y, g.objective.X, g.objective.Y)
+		if dist > objectiveReachedDistance {
+			h.compassAnchor.Hidden = false
+			h.compass.Angle = math.Atan2(g.objective.Y-g.player.Y, g.objective.X-g.player.X)
+			h.compass.Label = fmt.Sprintf("%s %dM", strings.ToUpper(g.objective.Name), int(dist/pixelsPerMeter))
+		}
+	}
+
+	h.notifications.Update()
+}
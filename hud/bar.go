@@ -0,0 +1,84 @@
+package hud
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ResourceBar shows points out of a maximum, like health or stamina. It follows an actor
+// around in the world with Draw, or sits in the HUD as a widget.
+type ResourceBar struct {
+	X, Y        float64
+	W, H        float64
+	Points      int
+	MaxPoints   int
+	FillColor   color.RGBA
+	EmptyColor  color.RGBA
+	FontColor   color.RGBA
+	FontSize    int
+	DrawOptions *text.DrawOptions
+	TextFont    *text.GoXFace
+	// FixedSize bars are W wide, the others fit around their text
+	FixedSize bool
+}
+
+const msgPadding = 4
+
+func (h *ResourceBar) SetDrawOptions() {
+	h.DrawOptions = &text.DrawOptions{}
+	h.DrawOptions.ColorScale.SetR(float32(h.FontColor.R) / 256.0)
+	h.DrawOptions.ColorScale.SetG(float32(h.FontColor.G) / 256.0)
+	h.DrawOptions.ColorScale.SetB(float32(h.FontColor.B) / 256.0)
+	h.DrawOptions.ColorScale.SetA(float32(h.FontColor.A) / 256.0)
+}
+
+func (h *ResourceBar) Update(x, y float64, points, maxPoints int) {
+	h.X, h.Y = x, y
+	h.Points, h.MaxPoints = points, maxPoints
+}
+
+func (h *ResourceBar) message() string {
+	return fmt.Sprintf("%d/%d", h.Points, h.MaxPoints)
+}
+
+func (h *ResourceBar) width() float64 {
+	if h.FixedSize {
+		return h.W
+	}
+
+	return float64(len(h.message()))*float64(h.FontSize) + float64(msgPadding)
+}
+
+func (h *ResourceBar) fill(screen *ebiten.Image, x, y, width, height float32) {
+	w1 := width * float32(h.Points) / float32(h.MaxPoints)
+	w2 := width * float32(h.MaxPoints-h.Points) / float32(h.MaxPoints)
+	vector.FillRect(screen, x, y, w1, height, h.FillColor, false)
+	vector.FillRect(screen, x+w1, y, w2, height, h.EmptyColor, false)
+}
+
+// Draw draws the bar centered above its position in the world.
+func (h *ResourceBar) Draw(screen *ebiten.Image, camX, camY float64) {
+	width := h.width()
+	x, y := h.X-width/float64(msgPadding)-camX, h.Y-camY
+	h.fill(screen, float32(x), float32(y), float32(width), float32(h.H))
+	h.DrawOptions.GeoM.Reset()
+	h.DrawOptions.GeoM.Translate(x+float64(msgPadding)/2, y)
+	text.Draw(screen, h.message(), h.TextFont, h.DrawOptions)
+}
+
+func (h *ResourceBar) Size() (float64, float64) {
+	return h.width(), h.H
+}
+
+func (h *ResourceBar) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	width, height := h.Size()
+	h.fill(screen, float32(x), float32(y), float32(width*scale), float32(height*scale))
+	h.DrawOptions.GeoM.Reset()
+	h.DrawOptions.GeoM.Scale(scale, scale)
+	h.DrawOptions.GeoM.Translate(x+float64(msgPadding)/2*scale, y)
+	text.Draw(screen, h.message(), h.TextFont, h.DrawOptions)
+}
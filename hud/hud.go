@@ -0,0 +1,142 @@
+// Package hud draws the widgets that sit on top of the game, anchored to the edges of the screen.
+package hud
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Anchor is the point of the screen a widget is attached to.
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// Widget is anything the HUD can lay out.
+type Widget interface {
+	// Size is the size of the widget at scale 1, it may change from frame to frame
+	Size() (float64, float64)
+	// DrawHUD draws the widget with its top left corner at x, y
+	DrawHUD(screen *ebiten.Image, x, y, scale float64)
+}
+
+// Element is a widget placed in the HUD.
+type Element struct {
+	Widget Widget
+	Anchor Anchor
+	// Hidden elements take no space, the others close the gap
+	Hidden bool
+}
+
+// HUD stacks the widgets of every anchor away from the edge of the screen, in the order they were added.
+// The layout is recomputed every frame, so widgets that grow, hide or a screen that is resized reflow.
+type HUD struct {
+	// Margin keeps the widgets away from the edges of the screen, Spacing away from each other
+	Margin  float64
+	Spacing float64
+	// BaseWidth and BaseHeight is the screen size the widgets are drawn at scale 1 for
+	BaseWidth  float64
+	BaseHeight float64
+	// Scale follows the screen size, it is set by Layout
+	Scale float64
+	// Zoom enlarges the HUD on top of Scale, for players who want bigger widgets
+	Zoom float64
+
+	width    float64
+	height   float64
+	elements []*Element
+}
+
+func New(width, height int) *HUD {
+	h := &HUD{
+		Margin:     30,
+		Spacing:    8,
+		BaseWidth:  float64(width),
+		BaseHeight: float64(height),
+		Zoom:       1,
+	}
+	h.Layout(width, height)
+
+	return h
+}
+
+func (h *HUD) Add(anchor Anchor, widget Widget) *Element {
+	element := &Element{
+		Widget: widget,
+		Anchor: anchor,
+	}
+	h.elements = append(h.elements, element)
+
+	return element
+}
+
+// Layout is called with the screen size whenever the game layout is, it resizes the widgets.
+func (h *HUD) Layout(width, height int) {
+	h.width = float64(width)
+	h.height = float64(height)
+	h.Scale = max(0.5, min(h.width/h.BaseWidth, h.height/h.BaseHeight)) * h.Zoom
+}
+
+func (h *HUD) Draw(screen *ebiten.Image) {
+	margin := h.Margin * h.Scale
+	spacing := h.Spacing * h.Scale
+	for anchor := TopLeft; anchor <= BottomRight; anchor++ {
+		elements := []*Element{}
+		stackHeight := 0.0
+		for _, element := range h.elements {
+			if element.Anchor != anchor || element.Hidden {
+				continue
+			}
+			_, height := element.Widget.Size()
+			if len(elements) > 0 {
+				stackHeight += spacing
+			}
+			stackHeight += height * h.Scale
+			elements = append(elements, element)
+		}
+		if len(elements) == 0 {
+			continue
+		}
+
+		// the stack grows down from the top, up from the bottom and both ways from the middle
+		var y float64
+		switch anchor / 3 {
+		case 0:
+			y = margin
+		case 1:
+			y = (h.height - stackHeight) / 2
+		case 2:
+			y = h.height - margin
+		}
+		for _, element := range elements {
+			width, height := element.Widget.Size()
+			width *= h.Scale
+			height *= h.Scale
+			var x float64
+			switch anchor % 3 {
+			case 0:
+				x = margin
+			case 1:
+				x = (h.width - width) / 2
+			case 2:
+				x = h.width - margin - width
+			}
+			if anchor/3 == 2 {
+				y -= height
+				element.Widget.DrawHUD(screen, x, y, h.Scale)
+				y -= spacing
+				continue
+			}
+			element.Widget.DrawHUD(screen, x, y, h.Scale)
+			y += height + spacing
+		}
+	}
+}
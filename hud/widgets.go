@@ -0,0 +1,198 @@
+package hud
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	brass    = color.RGBA{220, 180, 80, 255}
+	gunmetal = color.RGBA{60, 60, 70, 255}
+	steel    = color.RGBA{170, 170, 180, 255}
+)
+
+func drawText(screen *ebiten.Image, msg string, face *text.GoXFace, clr color.Color, x, y, scale float64) {
+	options := &text.DrawOptions{}
+	options.GeoM.Scale(scale, scale)
+	options.GeoM.Translate(x, y)
+	options.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, msg, face, options)
+}
+
+// Label is a line of text, like the money of the player.
+type Label struct {
+	Text  string
+	Face  *text.GoXFace
+	Color color.Color
+}
+
+func (l *Label) Size() (float64, float64) {
+	return text.Measure(l.Text, l.Face, 0)
+}
+
+func (l *Label) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	drawText(screen, l.Text, l.Face, l.Color, x, y, scale)
+}
+
+// Icon shows a sprite, like the weapon in the hands of the player.
+type Icon struct {
+	Image *ebiten.Image
+	Scale float64
+}
+
+func (i *Icon) Size() (float64, float64) {
+	if i.Image == nil {
+		return 0, 0
+	}
+
+	return float64(i.Image.Bounds().Dx()) * i.Scale, float64(i.Image.Bounds().Dy()) * i.Scale
+}
+
+func (i *Icon) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	if i.Image == nil {
+		return
+	}
+	options := &ebiten.DrawImageOptions{}
+	options.GeoM.Scale(i.Scale*scale, i.Scale*scale)
+	options.GeoM.Translate(x, y)
+	screen.DrawImage(i.Image, options)
+}
+
+// Cylinder shows the chambers of a revolver, the loaded ones in brass,
+// with the rounds left in the belt next to it.
+type Cylinder struct {
+	Loaded   int
+	Chambers int
+	Reserve  int
+	// Reload is how far the reload is, from 0 to 1, the cylinder spins while reloading
+	Reload float64
+	Radius float64
+	Face   *text.GoXFace
+}
+
+func (c *Cylinder) reserve() string {
+	return "x" + strconv.Itoa(c.Reserve)
+}
+
+func (c *Cylinder) Size() (float64, float64) {
+	width, height := text.Measure(c.reserve(), c.Face, 0)
+
+	return 2*c.Radius + c.Radius/2 + width, max(2*c.Radius, height)
+}
+
+func (c *Cylinder) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	r := c.Radius * scale
+	cx, cy := float32(x+r), float32(y+r)
+	vector.FillCircle(screen, cx, cy, float32(r), gunmetal, true)
+	vector.StrokeCircle(screen, cx, cy, float32(r), float32(scale), steel, true)
+	spin := c.Reload * 2 * math.Pi
+	for i := range c.Chambers {
+		angle := spin - math.Pi/2 + float64(i)*2*math.Pi/float64(c.Chambers)
+		px := cx + float32(math.Cos(angle)*r*0.6)
+		py := cy + float32(math.Sin(angle)*r*0.6)
+		chamber := color.Color(color.Black)
+		if i < c.Loaded && c.Reload == 0 {
+			chamber = brass
+		}
+		vector.FillCircle(screen, px, py, float32(r*0.22), chamber, true)
+	}
+	_, height := text.Measure(c.reserve(), c.Face, 0)
+	drawText(screen, c.reserve(), c.Face, color.White, x+2.5*r, y+r-height*scale/2, scale)
+}
+
+// Compass points from the player to the current objective.
+type Compass struct {
+	// Angle is the direction of the objective on the screen, in radians
+	Angle  float64
+	Label  string
+	Radius float64
+	Face   *text.GoXFace
+}
+
+func (c *Compass) Size() (float64, float64) {
+	width, height := text.Measure(c.Label, c.Face, 0)
+
+	return max(2*c.Radius, width), 2*c.Radius + c.Radius/3 + height
+}
+
+func (c *Compass) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	width, _ := c.Size()
+	r := c.Radius * scale
+	cx, cy := float32(x+width*scale/2), float32(y+r)
+	vector.FillCircle(screen, cx, cy, float32(r), color.NRGBA{40, 30, 20, 180}, true)
+	vector.StrokeCircle(screen, cx, cy, float32(r), float32(scale), brass, true)
+	tipX := cx + float32(math.Cos(c.Angle)*r*0.8)
+	tipY := cy + float32(math.Sin(c.Angle)*r*0.8)
+	tailX := cx - float32(math.Cos(c.Angle)*r*0.4)
+	tailY := cy - float32(math.Sin(c.Angle)*r*0.4)
+	vector.StrokeLine(screen, tailX, tailY, tipX, tipY, float32(2*scale), color.RGBA{200, 40, 30, 255}, true)
+	vector.FillCircle(screen, cx, cy, float32(2*scale), brass, true)
+	labelWidth, _ := text.Measure(c.Label, c.Face, 0)
+	drawText(screen, c.Label, c.Face, color.White, x+(width-labelWidth)*scale/2, y+2*r+r/3, scale)
+}
+
+type notification struct {
+	text     string
+	duration int
+}
+
+// Notifications is a short feed of messages that fade out after a while.
+type Notifications struct {
+	Face *text.GoXFace
+	// Duration is how many frames a message stays, Max how many are shown at once
+	Duration int
+	Max      int
+
+	messages []notification
+}
+
+func (n *Notifications) Push(msg string) {
+	n.messages = append(n.messages, notification{text: msg, duration: n.Duration})
+	if len(n.messages) > n.Max {
+		n.messages = n.messages[len(n.messages)-n.Max:]
+	}
+}
+
+// Update counts down the messages, it is called once per frame.
+func (n *Notifications) Update() {
+	kept := n.messages[:0]
+	for _, message := range n.messages {
+		message.duration -= 1
+		if message.duration > 0 {
+			kept = append(kept, message)
+		}
+	}
+	n.messages = kept
+}
+
+func (n *Notifications) lineHeight() float64 {
+	_, height := text.Measure("M", n.Face, 0)
+
+	return height * 1.5
+}
+
+func (n *Notifications) Size() (float64, float64) {
+	width := 0.0
+	for _, message := range n.messages {
+		w, _ := text.Measure(message.text, n.Face, 0)
+		width = max(width, w)
+	}
+
+	return width, float64(len(n.messages)) * n.lineHeight()
+}
+
+func (n *Notifications) DrawHUD(screen *ebiten.Image, x, y, scale float64) {
+	width, _ := n.Size()
+	for i, message := range n.messages {
+		w, _ := text.Measure(message.text, n.Face, 0)
+		// messages fade out during their last half second
+		alpha := min(1, float64(message.duration)/30)
+		clr := color.NRGBA{255, 255, 255, uint8(255 * alpha)}
+		drawText(screen, message.text, n.Face, clr, x+(width-w)*scale/2, y+float64(i)*n.lineHeight()*scale, scale)
+	}
+}
@@ -0,0 +1,118 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+type Kind int
+
+const (
+	Consumable Kind = iota
+	Weapon
+	Ammo
+	Mount
+	Clothing
+	Recruit
+)
+
+var kindNames = map[Kind]string{
+	Consumable: "consumable",
+	Weapon:     "weapon",
+	Ammo:       "ammo",
+	Mount:      "mount",
+	Clothing:   "clothing",
+	Recruit:    "recruit",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Carried items go into the inventory, the others are delivered by the shop.
+func (k Kind) Carried() bool {
+	return k != Mount && k != Recruit
+}
+
+// Usable items can go into the quick slot, consumables are used up and clothing is put on.
+func (k Kind) Usable() bool {
+	return k == Consumable || k == Clothing
+}
+
+func (k *Kind) UnmarshalText(data []byte) error {
+	for kind, name := range kindNames {
+		if name == string(data) {
+			*k = kind
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown item kind %q", data)
+}
+
+// Effect describes what happens when a consumable is used.
+// Durations are in frames.
+type Effect struct {
+	Heal         int     `json:"heal"`
+	Stamina      float64 `json:"stamina"`
+	Buff         string  `json:"buff"`
+	BuffAmount   float64 `json:"buffAmount"`
+	BuffDuration int     `json:"buffDuration"`
+}
+
+type Item struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Kind        Kind    `json:"kind"`
+	Price       int     `json:"price"`
+	Weight      float64 `json:"weight"`
+	MaxStack    int     `json:"maxStack"`
+	Effect      *Effect `json:"effect"`
+	// Slot, Sprite and Modifiers are only used by clothing,
+	// modifiers map a stat name to the bonus it gives while worn
+	Slot      string             `json:"slot"`
+	Sprite    string             `json:"sprite"`
+	Modifiers map[string]float64 `json:"modifiers"`
+}
+
+// Catalog holds every item definition by id.
+type Catalog map[string]*Item
+
+func LoadCatalog(fsys fs.FS, path string) (Catalog, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item catalog: %w", err)
+	}
+
+	list := []*Item{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse item catalog %s: %w", path, err)
+	}
+
+	catalog := Catalog{}
+	for i, item := range list {
+		if item.ID == "" {
+			return nil, fmt.Errorf("item %d in %s has no id", i, path)
+		}
+		if _, ok := catalog[item.ID]; ok {
+			return nil, fmt.Errorf("duplicate item id %q in %s", item.ID, path)
+		}
+		if item.Price < 0 {
+			return nil, fmt.Errorf("item %q in %s has a negative price", item.ID, path)
+		}
+		if item.Kind == Consumable && item.Effect == nil {
+			return nil, fmt.Errorf("consumable %q in %s has no effect", item.ID, path)
+		}
+		if item.Kind == Clothing && (item.Slot == "" || item.Sprite == "") {
+			return nil, fmt.Errorf("clothing %q in %s needs a slot and a sprite", item.ID, path)
+		}
+		if item.MaxStack == 0 {
+			item.MaxStack = 1
+		}
+		catalog[item.ID] = item
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,277 @@
+// Package menu draws navigable menus that work with the keyboard, the mouse and a gamepad.
+package menu
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	textColor     = color.RGBA{230, 220, 200, 255}
+	focusColor    = color.RGBA{255, 200, 60, 255}
+	disabledColor = color.RGBA{120, 110, 100, 255}
+	infoColor     = color.RGBA{180, 170, 150, 255}
+	focusBox      = color.NRGBA{0, 0, 0, 120}
+)
+
+// Item is a line of a menu. What it does depends on which fields are set,
+// an item without Action, Adjust, Submenu or Back is a line of text that can not be focused.
+type Item struct {
+	Label string
+	// Value is shown after the label, for settings
+	Value func() string
+	// Action runs when the item is picked
+	Action func()
+	// Adjust is called with -1 or 1 when left or right is pressed on the item
+	Adjust func(delta int)
+	// Submenu opens on top of the current menu when the item is picked
+	Submenu *Menu
+	// Back closes the menu the item is in
+	Back bool
+	// Disabled items are shown greyed out and can not be focused
+	Disabled func() bool
+}
+
+func (i *Item) selectable() bool {
+	if i.Disabled != nil && i.Disabled() {
+		return false
+	}
+
+	return i.Action != nil || i.Adjust != nil || i.Submenu != nil || i.Back
+}
+
+// info reports whether the item is only a line of text.
+func (i *Item) info() bool {
+	return i.Disabled == nil && !i.selectable()
+}
+
+func (i *Item) text() string {
+	if i.Value == nil {
+		return strings.ToUpper(i.Label)
+	}
+	if i.Adjust != nil {
+		return strings.ToUpper(i.Label) + "  < " + strings.ToUpper(i.Value()) + " >"
+	}
+
+	return strings.ToUpper(i.Label) + "  " + strings.ToUpper(i.Value())
+}
+
+type Menu struct {
+	Title string
+	Items []*Item
+	// OnBack is called when back is pressed on the menu at the bottom of the stack
+	OnBack func()
+
+	focus int
+}
+
+// focusNext moves the focus in the direction to the next item that can be focused.
+func (m *Menu) focusNext(dir int) {
+	for range m.Items {
+		m.focus = (m.focus + dir + len(m.Items)) % len(m.Items)
+		if m.Items[m.focus].selectable() {
+			return
+		}
+	}
+}
+
+// ensureFocus moves the focus off an item that can no longer be focused.
+func (m *Menu) ensureFocus() {
+	if len(m.Items) == 0 {
+		return
+	}
+	m.focus = min(m.focus, len(m.Items)-1)
+	if !m.Items[m.focus].selectable() {
+		m.focusNext(1)
+	}
+}
+
+func (m *Menu) Focused() *Item {
+	if len(m.Items) == 0 || !m.Items[m.focus].selectable() {
+		return nil
+	}
+
+	return m.Items[m.focus]
+}
+
+// Input is what happened on all input devices this frame, gathered by the game.
+type Input struct {
+	Up, Down, Left, Right bool
+	Confirm, Back         bool
+	// the mouse focuses the item under it when it moves and picks it when clicked
+	MouseX, MouseY float64
+	MouseMoved     bool
+	Clicked        bool
+}
+
+type rect struct {
+	x, y, w, h float64
+}
+
+func (r rect) contains(x, y float64) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// Navigator shows the menu on top of a stack of menus, submenus are pushed on the stack
+// and back pops them off again.
+type Navigator struct {
+	TitleFace *text.GoXFace
+	Face      *text.GoXFace
+	// InfoFace is used for lines of text that can not be focused, if set
+	InfoFace *text.GoXFace
+	// Center is where the middle of the menu is, as a part of the width of the screen,
+	// so the menu stays in place when the screen is resized. Top is the top of its title
+	Center float64
+	Top    float64
+	// LineHeight is the distance between items
+	LineHeight float64
+
+	stack []*Menu
+	// rects of the items of the top menu, from the last time it was drawn
+	rects []rect
+}
+
+// Open replaces the whole stack with the menu.
+func (n *Navigator) Open(m *Menu) {
+	n.stack = []*Menu{m}
+	n.rects = nil
+	m.focus = 0
+	m.ensureFocus()
+}
+
+func (n *Navigator) Push(m *Menu) {
+	n.stack = append(n.stack, m)
+	n.rects = nil
+	m.focus = 0
+	m.ensureFocus()
+}
+
+// Back closes the top menu, or calls OnBack of the last menu on the stack.
+func (n *Navigator) Back() {
+	if len(n.stack) > 1 {
+		n.stack = n.stack[:len(n.stack)-1]
+		n.rects = nil
+		return
+	}
+	if current := n.Current(); current != nil && current.OnBack != nil {
+		current.OnBack()
+	}
+}
+
+func (n *Navigator) Current() *Menu {
+	if len(n.stack) == 0 {
+		return nil
+	}
+
+	return n.stack[len(n.stack)-1]
+}
+
+func (n *Navigator) pick(item *Item) {
+	switch {
+	case item.Back:
+		n.Back()
+	case item.Submenu != nil:
+		n.Push(item.Submenu)
+	case item.Action != nil:
+		item.Action()
+	case item.Adjust != nil:
+		item.Adjust(1)
+	}
+}
+
+func (n *Navigator) Update(in Input) {
+	m := n.Current()
+	if m == nil {
+		return
+	}
+	m.ensureFocus()
+
+	if in.MouseMoved || in.Clicked {
+		for i, r := range n.rects {
+			if i < len(m.Items) && r.contains(in.MouseX, in.MouseY) && m.Items[i].selectable() {
+				m.focus = i
+				if in.Clicked {
+					n.pick(m.Items[i])
+					return
+				}
+			}
+		}
+	}
+
+	switch {
+	case in.Up:
+		m.focusNext(-1)
+	case in.Down:
+		m.focusNext(1)
+	case in.Back:
+		n.Back()
+	case in.Left || in.Right:
+		if item := m.Focused(); item != nil && item.Adjust != nil {
+			delta := 1
+			if in.Left {
+				delta = -1
+			}
+			item.Adjust(delta)
+		}
+	case in.Confirm:
+		if item := m.Focused(); item != nil {
+			n.pick(item)
+		}
+	}
+}
+
+func (n *Navigator) Draw(screen *ebiten.Image) {
+	m := n.Current()
+	if m == nil {
+		return
+	}
+	centerX := n.Center * float64(screen.Bounds().Dx())
+	y := n.Top
+	if m.Title != "" {
+		width, height := text.Measure(strings.ToUpper(m.Title), n.TitleFace, 0)
+		drawText(screen, strings.ToUpper(m.Title), n.TitleFace, textColor, centerX-width/2, y)
+		y += height + 2*n.LineHeight
+	}
+
+	n.rects = n.rects[:0]
+	for i, item := range m.Items {
+		label := item.text()
+		face, lineHeight := n.Face, n.LineHeight
+		if item.info() && n.InfoFace != nil {
+			// info lines are packed closer together in the smaller face
+			face = n.InfoFace
+			_, infoHeight := text.Measure("M", face, 0)
+			_, itemHeight := text.Measure("M", n.Face, 0)
+			lineHeight = n.LineHeight * infoHeight / itemHeight
+		}
+		width, height := text.Measure(label, face, 0)
+		x := centerX - width/2
+		clr := textColor
+		switch {
+		case i == m.focus && item.selectable():
+			clr = focusColor
+			padding := height / 2
+			vector.FillRect(screen, float32(x-2*padding), float32(y-padding), float32(width+4*padding), float32(height+2*padding), focusBox, false)
+			markerWidth, _ := text.Measure(">", face, 0)
+			drawText(screen, ">", face, clr, x-3*padding-markerWidth, y)
+		case item.Disabled != nil && item.Disabled():
+			clr = disabledColor
+		case item.info():
+			clr = infoColor
+		}
+		drawText(screen, label, face, clr, x, y)
+		n.rects = append(n.rects, rect{x: x, y: y - height/2, w: width, h: 2 * height})
+		y += lineHeight
+	}
+}
+
+func drawText(screen *ebiten.Image, msg string, face *text.GoXFace, clr color.Color, x, y float64) {
+	options := &text.DrawOptions{}
+	options.GeoM.Translate(x, y)
+	options.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, msg, face, options)
+}
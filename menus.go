@@ -0,0 +1,175 @@
+package farwest
+
+import (
+	"fmt"
+
+	"github.com/bramca/Far-West/menu"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// controls lists every binding on the controls screen, keyboard first and gamepad second.
+var controls = [][3]string{
+	{"move", "WASD / ZQSD", "left stick"},
+	{"aim", "arrow keys", "right stick"},
+	{"shoot", "space", "RB"},
+	{"reload", "R", "LT"},
+	{"dodge", "left shift", "LB"},
+	{"switch weapon", "1 / 2 / 0", "Y"},
+	{"interact", "E", "X"},
+	{"use item", "F", "B"},
+	{"next item", "X", "d-pad right"},
+	{"dynamite", "V", "L3"},
+	{"gang follow/hold/attack", "G / H / T", "d-pad up/down/left"},
+	{"tie up / lead a captive", "E", "X"},
+	{"revive a partner", "hold E", "hold X"},
+	{"pause", "P / escape", "RT"},
+}
+
+func (g *Game) newMenus() {
+	g.menus = &menu.Navigator{
+		TitleFace:  text.NewGoXFace(g.titleArcadeFont),
+		Face:       text.NewGoXFace(g.arcadeFont),
+		InfoFace:   text.NewGoXFace(g.shopFont),
+		Top:        100,
+		LineHeight: 2 * float64(g.fontSize),
+	}
+
+	g.controlsMenu = &menu.Menu{Title: "Controls"}
+	for _, binding := range controls {
+		g.controlsMenu.Items = append(g.controlsMenu.Items, &menu.Item{
+			Label: fmt.Sprintf("%-24s %-11s %s", binding[0], binding[1], binding[2]),
+		})
+	}
+	g.controlsMenu.Items = append(g.controlsMenu.Items, &menu.Item{Label: "back", Back: true})
+
+	g.titleMenu = &menu.Menu{
+		Title: "Far West",
+		Items: []*menu.Item{
+			{Label: "new game", Action: g.newRun},
+			{Label: "continue", Action: g.continueRun, Disabled: func() bool { return !g.runStarted || g.allDown() }},
+			{Label: "settings", Action: g.openSettings},
+			{Label: "controls", Submenu: g.controlsMenu},
+			{Label: "quit", Action: func() { g.quit = true }},
+		},
+	}
+	g.pauseMenu = &menu.Menu{
+		Title:  "Paused",
+		OnBack: g.resume,
+		Items: []*menu.Item{
+			{Label: "resume", Action: g.resume},
+			{Label: "settings", Action: g.openSettings},
+			{Label: "controls", Submenu: g.controlsMenu},
+			{Label: "clothing", Submenu: g.newClothingMenu()},
+			{
+				Label:    "players",
+				Value:    g.playersLabel,
+				Action:   g.togglePlayers,
+				Adjust:   func(int) { g.togglePlayers() },
+				Disabled: func() bool { return g.joinedOnController() == nil && !g.canJoin() },
+			},
+			{Label: "restart", Action: g.restart, Disabled: g.isClient},
+			{Label: "quit to title", Action: g.openTitle},
+		},
+	}
+	g.gameOverMenu = &menu.Menu{
+		Title: "Game Over!",
+		Items: []*menu.Item{
+			{Label: "restart", Action: g.restart, Disabled: g.isClient},
+			{Label: "quit to title", Action: g.openTitle},
+		},
+	}
+}
+
+// openTitle leaves the run for the title screen, it can be continued from there.
+func (g *Game) openTitle() {
+	g.stopNetwork()
+	g.scenes.Transition(wipeToTitle, func() {
+		g.scenes.Replace(&titleScene{g: g})
+	})
+}
+
+func (g *Game) openPause() {
+	g.scenes.Push(&pauseScene{g: g})
+}
+
+func (g *Game) openGameOver() {
+	g.scenes.Push(&gameOverScene{g: g})
+}
+
+// newRun starts playing, in a fresh world if a run was already started from this one.
+func (g *Game) newRun() {
+	g.scenes.Transition(fadeToBlack, func() {
+		if g.runStarted {
+			g.Initialize()
+		}
+		g.runStarted = true
+		g.scenes.Replace(&worldScene{g: g})
+	})
+}
+
+func (g *Game) continueRun() {
+	g.scenes.Transition(fadeToBlack, func() {
+		g.scenes.Replace(&worldScene{g: g})
+	})
+}
+
+func (g *Game) restart() {
+	g.scenes.Transition(fadeToBlack, func() {
+		g.newWorld()
+		g.runStarted = true
+		g.scenes.Replace(&worldScene{g: g})
+	})
+}
+
+// resume closes the pause menu.
+func (g *Game) resume() {
+	g.scenes.Pop()
+}
+
+// menuInput merges the keyboard, mouse and gamepads into one menu input.
+// The sticks only count when they cross the dead zone, so holding one moves the focus once.
+func (g *Game) menuInput(buttonsJustPressed map[string]bool) menu.Input {
+	deadZone := g.settings.Controls.DeadZone
+	stickX, stickY := 0, 0
+	if g.xLeftAxis > deadZone {
+		stickX = 1
+	} else if g.xLeftAxis < -deadZone {
+		stickX = -1
+	}
+	if g.yLeftAxis > deadZone {
+		stickY = 1
+	} else if g.yLeftAxis < -deadZone {
+		stickY = -1
+	}
+	stickMovedX := stickX != g.menuStickX
+	stickMovedY := stickY != g.menuStickY
+	g.menuStickX, g.menuStickY = stickX, stickY
+
+	keyJustPressed := func(keys ...ebiten.Key) bool {
+		for _, key := range keys {
+			if inpututil.IsKeyJustPressed(key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	cursorX, cursorY := g.viewport.Cursor()
+	mouseMoved := cursorX != g.cursorX || cursorY != g.cursorY
+	g.cursorX, g.cursorY = cursorX, cursorY
+
+	return menu.Input{
+		Up:         keyJustPressed(ebiten.KeyUp, ebiten.KeyW, ebiten.KeyZ) || buttonsJustPressed["LT"] || (stickMovedY && stickY < 0),
+		Down:       keyJustPressed(ebiten.KeyDown, ebiten.KeyS) || buttonsJustPressed["LB"] || (stickMovedY && stickY > 0),
+		Left:       keyJustPressed(ebiten.KeyLeft, ebiten.KeyA, ebiten.KeyQ) || buttonsJustPressed["LL"] || (stickMovedX && stickX < 0),
+		Right:      keyJustPressed(ebiten.KeyRight, ebiten.KeyD) || buttonsJustPressed["LR"] || (stickMovedX && stickX > 0),
+		Confirm:    keyJustPressed(ebiten.KeyEnter, ebiten.KeySpace) || buttonsJustPressed["RB"] || buttonsJustPressed["FBR"] || buttonsJustPressed["CR"],
+		Back:       keyJustPressed(ebiten.KeyEscape, ebiten.KeyBackspace) || buttonsJustPressed["RR"] || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight),
+		MouseX:     cursorX,
+		MouseY:     cursorY,
+		MouseMoved: mouseMoved,
+		Clicked:    inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft),
+	}
+}
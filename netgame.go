@@ -0,0 +1,609 @@
+package farwest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/netplay"
+	"github.com/bramca/Far-West/world"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// defaultHostAddr is where a game is hosted when no address is given.
+const defaultHostAddr = ":7777"
+
+// netButtons and netKeys give every button and key the game reads a bit in a network input.
+var (
+	netButtons = []string{"RB", "RR", "RL", "RT", "FTL", "FTR", "FBL", "FBR", "CL", "CR", "LS", "RS", "LB", "LR", "LL", "LT", "CC"}
+	netKeys    = []ebiten.Key{
+		ebiten.KeyW, ebiten.KeyA, ebiten.KeyS, ebiten.KeyD, ebiten.KeyZ, ebiten.KeyQ,
+		ebiten.KeyUp, ebiten.KeyDown, ebiten.KeyLeft, ebiten.KeyRight,
+		ebiten.KeySpace, ebiten.KeyShiftLeft, ebiten.KeyR, ebiten.KeyE, ebiten.KeyF, ebiten.KeyX, ebiten.KeyV,
+		ebiten.Key1, ebiten.Key0, ebiten.KeyG, ebiten.KeyH, ebiten.KeyT, ebiten.Key2,
+	}
+)
+
+// encodeInput packs what the devices of a player did this frame into a network input.
+func encodeInput(in *playerInput) netplay.Input {
+	input := netplay.Input{
+		MoveX: float32(in.xLeftAxis),
+		MoveY: float32(in.yLeftAxis),
+		AimX:  float32(in.xRightAxis),
+		AimY:  float32(in.yRightAxis),
+	}
+	for bit, button := range netButtons {
+		if in.buttonsPressed[button] {
+			input.Buttons |= 1 << bit
+		}
+		if in.buttonsJustPressed[button] {
+			input.ButtonsJustPressed |= 1 << bit
+		}
+	}
+	for bit, key := range netKeys {
+		if in.keyPressed(key) {
+			input.Keys |= 1 << bit
+		}
+		if in.keyJustPressed(key) {
+			input.KeysJustPressed |= 1 << bit
+		}
+	}
+
+	return input
+}
+
+// decodeInput unpacks a network input into in, as if the devices were on this machine.
+func decodeInput(input netplay.Input, in *playerInput) {
+	in.keyboard = false
+	in.gamepads = nil
+	in.xLeftAxis, in.yLeftAxis = float64(input.MoveX), float64(input.MoveY)
+	in.xRightAxis, in.yRightAxis = float64(input.AimX), float64(input.AimY)
+	in.buttonsPressed = map[string]bool{}
+	if in.buttonsJustPressed == nil {
+		in.idle()
+	}
+	for bit, button := range netButtons {
+		in.buttonsPressed[button] = input.Buttons&(1<<bit) != 0
+		if input.ButtonsJustPressed&(1<<bit) != 0 {
+			in.buttonsJustPressed[button] = true
+		}
+	}
+	in.keys = map[ebiten.Key]bool{}
+	for bit, key := range netKeys {
+		in.keys[key] = input.Keys&(1<<bit) != 0
+		if input.KeysJustPressed&(1<<bit) != 0 {
+			in.keysJustPressed[key] = true
+		}
+	}
+}
+
+// SimulateNetwork adds latency and packet loss to the games hosted or joined after it,
+// to try network play on one machine.
+func (g *Game) SimulateNetwork(link netplay.Link) {
+	g.netLink = link
+}
+
+// Host starts a fresh world others can join at addr, like ":7777".
+func (g *Game) Host(addr string) error {
+	g.stopNetwork()
+	seed := rand.Int63()
+	host, err := netplay.NewHost(addr, seed, g.netLink)
+	if err != nil {
+		return err
+	}
+	host.Join = g.joinPeer
+	g.host = host
+	g.bulletIDs = map[*actors.Bullet]uint32{}
+
+	g.reseed(seed)
+	g.Initialize()
+	g.playWorld()
+
+	return nil
+}
+
+// Connect starts joining the game hosted at addr, the world is built once the host answers.
+func (g *Game) Connect(addr string) error {
+	g.stopNetwork()
+	client, err := netplay.Connect(addr, g.netLink)
+	if err != nil {
+		return err
+	}
+	g.client = client
+
+	return nil
+}
+
+// stopNetwork leaves or closes the network game, the players from the other machines leave.
+func (g *Game) stopNetwork() {
+	if g.host != nil {
+		if err := g.host.Close(); err != nil {
+			g.notify("%v", err)
+		}
+		g.host = nil
+	}
+	if g.client != nil {
+		if err := g.client.Close(); err != nil {
+			g.notify("%v", err)
+		}
+		g.client = nil
+		g.netSeed = 0
+		// the world of the host can not be played on alone
+		g.runStarted = false
+	}
+	for i := len(g.players) - 1; i > 0; i-- {
+		if g.players[i].remote {
+			g.leave(g.players[i])
+		}
+	}
+}
+
+// playWorld goes to the world, unless the player is already in it.
+func (g *Game) playWorld() {
+	g.runStarted = true
+	if _, playing := g.scenes.Top().(*worldScene); playing {
+		return
+	}
+	g.scenes.Transition(fadeToBlack, func() {
+		g.scenes.Replace(&worldScene{g: g})
+	})
+}
+
+func (g *Game) isClient() bool {
+	return g.client != nil
+}
+
+// newWorld builds a fresh world, a host has the clients build the same one.
+func (g *Game) newWorld() {
+	if g.host != nil {
+		g.host.Seed = rand.Int63()
+		g.reseed(g.host.Seed)
+	}
+	g.Initialize()
+}
+
+// joinPeer gives a client that asked to join a player of its own.
+func (g *Game) joinPeer(peer *netplay.Peer) bool {
+	id, free := g.freePlayerID()
+	if !free {
+		return false
+	}
+	cp := g.addPlayer(id)
+	cp.remote = true
+	cp.peer = peer
+	peer.PlayerID = id
+	g.hud = g.newHUD()
+	g.notify("%s joined from %s", cp.name, peer.Addr)
+
+	return true
+}
+
+// updateNetwork talks to the other machines, it runs every frame whatever scene is showing.
+func (g *Game) updateNetwork() {
+	if g.host != nil {
+		_, left := g.host.Update()
+		for _, peer := range left {
+			for _, cp := range g.players {
+				if cp.peer == peer {
+					g.leave(cp)
+					break
+				}
+			}
+		}
+	}
+
+	if g.client != nil {
+		if err := g.client.Update(); err != nil {
+			g.notify("%v", err)
+			g.stopNetwork()
+			g.openTitle()
+			return
+		}
+		latest := g.client.Latest()
+		switch {
+		case g.client.Welcomed && g.netSeed != g.client.Seed:
+			g.buildClientWorld(g.client.Seed)
+		case latest != nil && latest.Seed != g.netSeed:
+			// the host started over
+			g.client.Seed = latest.Seed
+			g.buildClientWorld(latest.Seed)
+		}
+	}
+}
+
+// sendSnapshot is called at the end of every frame of the host.
+func (g *Game) sendSnapshot() {
+	if g.host != nil && g.host.SnapshotDue() {
+		g.host.Send(g.snapshot())
+	}
+}
+
+func netActor(kind netplay.ActorKind, id int, p *actors.Player) netplay.Actor {
+	actor := netplay.Actor{
+		Kind:      kind,
+		ID:        uint16(id),
+		X:         float32(p.X),
+		Y:         float32(p.Y),
+		Sprite:    uint16(p.CurrentState),
+		Health:    int16(p.Health),
+		MaxHealth: int16(p.MaxHealth),
+		Stamina:   int16(p.Stamina),
+		Ammo:      uint8(p.Ammo),
+		Weapon:    uint8(p.CurrentWeapon),
+		Dir:       uint8(p.VisualDir),
+	}
+	if p.Dead {
+		actor.Flags |= netplay.DeadFlag
+	}
+	if p.Running {
+		actor.Flags |= netplay.RunningFlag
+	}
+
+	return actor
+}
+
+// snapshot is everything a client draws that it can not work out for itself.
+// Horses, dynamite and the shops are not sent, a client only sees its own.
+func (g *Game) snapshot() *netplay.Snapshot {
+	s := &netplay.Snapshot{}
+	shooters := []*actors.Player{}
+	for _, cp := range g.players {
+		s.Actors = append(s.Actors, netActor(netplay.PlayerActor, int(cp.id), cp.Player))
+		shooters = append(shooters, cp.Player)
+	}
+	for _, enemy := range g.enemies {
+		actor := netActor(netplay.EnemyActor, int(enemy.ID), enemy.Player)
+		for sheet, enemySheet := range g.sprites.Enemies {
+			if len(enemySheet.Frames) > 0 && enemySheet.Frames[0].Image == enemy.Sprites[0] {
+				actor.Sheet = uint8(sheet)
+			}
+		}
+		s.Actors = append(s.Actors, actor)
+		shooters = append(shooters, enemy.Player)
+	}
+	for i, companion := range g.companions {
+		s.Actors = append(s.Actors, netActor(netplay.CompanionActor, i, companion.Player))
+		shooters = append(shooters, companion.Player)
+	}
+
+	// bullets keep their id for as long as they fly, so a client knows which ones it already has
+	bulletIDs := map[*actors.Bullet]uint32{}
+	for owner, shooter := range shooters {
+		for _, bullet := range shooter.Bullets {
+			id, ok := g.bulletIDs[bullet]
+			if !ok {
+				g.nextBulletID += 1
+				id = g.nextBulletID
+			}
+			bulletIDs[bullet] = id
+			s.Bullets = append(s.Bullets, netplay.Bullet{
+				ID:       id,
+				Owner:    uint16(owner),
+				X:        float32(bullet.X),
+				Y:        float32(bullet.Y),
+				R:        float32(bullet.R),
+				Speed:    float32(bullet.Speed),
+				Duration: uint16(max(0, bullet.Duration)),
+			})
+		}
+	}
+	g.bulletIDs = bulletIDs
+
+	for _, cactus := range g.cacti {
+		if cactus.Health < cactus.MaxHealth {
+			s.Cacti = append(s.Cacti, netplay.Cactus{X: float32(cactus.X), Y: float32(cactus.Y), Health: int16(cactus.Health)})
+		}
+	}
+	for _, stump := range g.stumps {
+		s.Cacti = append(s.Cacti, netplay.Cactus{X: float32(stump.X), Y: float32(stump.Y)})
+	}
+
+	return s
+}
+
+// buildClientWorld builds the world of the host from its seed, with the player the host gave this client.
+func (g *Game) buildClientWorld(seed int64) {
+	id := g.client.PlayerID
+	if int(id) >= maxPlayers {
+		g.notify("the host gave this client player %d of %d", id+1, maxPlayers)
+		g.stopNetwork()
+		g.openTitle()
+		return
+	}
+	g.players = []*coopPlayer{{id: id, name: playerNames[id], input: &playerInput{}}}
+	g.reseed(seed)
+	g.Initialize()
+	g.netSeed = seed
+	g.netBullets = map[uint32]*actors.Bullet{}
+	g.netCacti = map[[2]float32]*world.Cactus{}
+	for _, cactus := range g.cacti {
+		g.netCacti[[2]float32{float32(cactus.X), float32(cactus.Y)}] = cactus
+	}
+	g.playWorld()
+}
+
+// updateClient runs the world of a client. The own player moves and shoots right away and is put
+// where the host says once it answers, the rest is drawn a little in the past between two snapshots.
+func (g *Game) updateClient() {
+	if g.netSeed == 0 {
+		return
+	}
+	g.frameCount += 1
+	local := g.players[0]
+	p := local.Player
+	g.client.SendInput(encodeInput(local.input))
+
+	p.MoveDirs = map[actors.Direction]bool{}
+	p.UpdateBullets()
+	p.UpdateLasso()
+	if !p.Dead {
+		switchWeapon(p, local.input)
+	}
+	if g.client.Fresh() {
+		g.reconcile(local)
+	} else if !p.Dead {
+		local.moving = g.steer(p, local.input)
+	}
+	for _, cactus := range g.cacti {
+		if p.Hitbox.CheckCollision(cactus.Hitbox) {
+			g.pushBack(p, 0)
+			p.UpdateHitbox()
+		}
+	}
+	if !p.Dead && (local.input.keyJustPressed(ebiten.KeySpace) || local.input.buttonsJustPressed["FTR"]) {
+		p.Shoot()
+	}
+	if local.moving {
+		p.Animate()
+	} else {
+		p.StopAnimation()
+	}
+	p.UpdateAnimation()
+	g.dropPredictedBullets(p)
+
+	g.applySnapshots()
+	g.updateEnvironment()
+	g.updateParticles()
+	g.updateSounds()
+
+	if local.input.pausePressed() {
+		g.openPause()
+	}
+	if g.allDown() {
+		g.openGameOver()
+	}
+	if g.frameCount%g.maxFramCount == 0 {
+		g.frameCount = 1
+	}
+}
+
+// reconcile puts the own player where the newest snapshot has it and walks it again through
+// the inputs the host had not used yet.
+func (g *Game) reconcile(local *coopPlayer) {
+	latest := g.client.Latest()
+	for _, actor := range latest.Actors {
+		if actor.Kind != netplay.PlayerActor || actor.ID != local.id {
+			continue
+		}
+		p := local.Player
+		p.X, p.Y = float64(actor.X), float64(actor.Y)
+		p.Ammo = int(actor.Ammo)
+		p.Stamina = float64(actor.Stamina)
+		g.setHealth(p, int(actor.Health), int(actor.MaxHealth))
+		g.setDead(p, actor.Flags&netplay.DeadFlag != 0)
+		p.UpdateHitbox()
+	}
+
+	local.moving = false
+	replay := &playerInput{}
+	for _, input := range g.client.Pending() {
+		decodeInput(input, replay)
+		if !local.Dead {
+			local.moving = g.steer(local.Player, replay)
+		}
+		replay.idle()
+	}
+}
+
+// setDead plays the death of an actor the host saw die.
+func (g *Game) setDead(p *actors.Player, dead bool) {
+	if dead && !p.Dead {
+		g.die(p)
+	}
+	p.Dead = dead
+}
+
+// setHealth shows the damage the host dealt as hits over the actor.
+func (g *Game) setHealth(p *actors.Player, health, maxHealth int) {
+	if health < p.Health {
+		g.addHit(p, p.Health-health)
+	}
+	p.Health, p.MaxHealth = health, maxHealth
+}
+
+// dropPredictedBullets takes out the bullets of the own player that hit something,
+// the host decides what they did.
+func (g *Game) dropPredictedBullets(p *actors.Player) {
+	for i := len(p.Bullets) - 1; i >= 0; i-- {
+		bullet := p.Bullets[i]
+		hit := false
+		for _, cactus := range g.cacti {
+			hit = hit || bullet.Hitbox.CheckPixelCollision(cactus.Hitbox)
+		}
+		for _, enemy := range g.enemies {
+			hit = hit || (!enemy.Dead && bullet.Hitbox.CheckCollision(enemy.Hitbox))
+		}
+		if hit {
+			p.Bullets = append(p.Bullets[:i], p.Bullets[i+1:]...)
+		}
+	}
+}
+
+// netPlayer finds the actor a snapshot is about, the ones the client has not seen yet are made.
+func (g *Game) netPlayer(actor netplay.Actor) *actors.Player {
+	x, y := float64(actor.X), float64(actor.Y)
+	switch actor.Kind {
+	case netplay.PlayerActor:
+		for _, cp := range g.players {
+			if cp.id == actor.ID {
+				return cp.Player
+			}
+		}
+		if int(actor.ID) >= maxPlayers {
+			return nil
+		}
+		cp := g.addPlayer(actor.ID)
+		cp.remote = true
+		g.hud = g.newHUD()
+		return cp.Player
+	case netplay.EnemyActor:
+		for _, enemy := range g.enemies {
+			if enemy.ID == actor.ID {
+				return enemy.Player
+			}
+		}
+		sheet := g.sprites.Enemies[min(int(actor.Sheet), len(g.sprites.Enemies)-1)]
+		enemy := g.newEnemy(x, y, sheet)
+		enemy.ID = actor.ID
+		g.enemies = append(g.enemies, enemy)
+		return enemy.Player
+	case netplay.CompanionActor:
+		for len(g.companions) <= int(actor.ID) {
+			g.companions = append(g.companions, g.newCompanion(x, y))
+		}
+		return g.companions[actor.ID].Player
+	}
+
+	return nil
+}
+
+// applySnapshots moves everyone but the own player to where they were between the two snapshots
+// around the drawn tick, and takes the bullets and cacti from the newest one.
+func (g *Game) applySnapshots() {
+	a, b, t := g.client.Interpolate()
+	if a == nil {
+		return
+	}
+	local := g.players[0]
+	type actorKey struct {
+		kind netplay.ActorKind
+		id   uint16
+	}
+	next := map[actorKey]netplay.Actor{}
+	for _, actor := range b.Actors {
+		next[actorKey{actor.Kind, actor.ID}] = actor
+	}
+	// outlaws taken to jail are gone from the newest snapshot
+	enemies := map[uint16]bool{}
+	for _, actor := range g.client.Latest().Actors {
+		if actor.Kind == netplay.EnemyActor {
+			enemies[actor.ID] = true
+		}
+	}
+	seen := map[*actors.Player]bool{local.Player: true}
+	for _, from := range a.Actors {
+		if from.Kind == netplay.PlayerActor && from.ID == local.id {
+			continue
+		}
+		if from.Kind == netplay.EnemyActor && !enemies[from.ID] {
+			continue
+		}
+		to, ok := next[actorKey{from.Kind, from.ID}]
+		if !ok {
+			to = from
+		}
+		actor := netplay.Lerp(from, to, t)
+		p := g.netPlayer(actor)
+		if p == nil {
+			continue
+		}
+		seen[p] = true
+		p.X, p.Y = float64(actor.X), float64(actor.Y)
+		p.CurrentState = actors.PlayerState(actor.Sprite)
+		p.CurrentWeapon = actors.Weapon(actor.Weapon)
+		p.VisualDir = actors.Direction(actor.Dir)
+		p.Ammo = int(actor.Ammo)
+		p.Stamina = float64(actor.Stamina)
+		p.Running = actor.Flags&netplay.RunningFlag != 0
+		g.setDead(p, actor.Flags&netplay.DeadFlag != 0)
+		g.setHealth(p, int(actor.Health), int(actor.MaxHealth))
+		x, y := p.Center()
+		p.Hitbox.X, p.Hitbox.Y = float32(x), float32(y)
+		if p.IsNpc {
+			p.Healthbar.Update(p.X, p.Y-(p.H-p.H/3), p.Health, p.MaxHealth)
+		} else {
+			p.Healthbar.Update(p.Healthbar.X, p.Healthbar.Y, p.Health, p.MaxHealth)
+		}
+		p.UpdateBullets()
+	}
+	for _, cp := range g.players {
+		if cp.remote && !seen[cp.Player] {
+			g.leave(cp)
+		}
+	}
+	g.enemies = slices.DeleteFunc(g.enemies, func(enemy *actors.Enemy) bool {
+		return !enemies[enemy.ID]
+	})
+
+	if g.client.Fresh() {
+		g.applyBullets(g.client.Latest())
+		g.applyCacti(g.client.Latest())
+	}
+}
+
+// applyBullets gives everyone but the own player the bullets of the snapshot. Bullets the client
+// already has keep flying where they are, so their muzzle flash is only shown once.
+func (g *Game) applyBullets(s *netplay.Snapshot) {
+	owners := make([]*actors.Player, len(s.Actors))
+	for i, actor := range s.Actors {
+		if actor.Kind == netplay.PlayerActor && actor.ID == g.players[0].id {
+			continue
+		}
+		owners[i] = g.netPlayer(actor)
+		if owners[i] != nil {
+			owners[i].Bullets = nil
+		}
+	}
+
+	bullets := map[uint32]*actors.Bullet{}
+	for _, b := range s.Bullets {
+		if int(b.Owner) >= len(owners) || owners[b.Owner] == nil {
+			continue
+		}
+		bullet, ok := g.netBullets[b.ID]
+		if !ok {
+			bullet = actors.NewBullet(g.bulletSprite, float64(b.X), float64(b.Y), float64(b.R), float64(b.Speed), int(b.Duration), 0)
+		}
+		bullets[b.ID] = bullet
+		owners[b.Owner].Bullets = append(owners[b.Owner].Bullets, bullet)
+	}
+	g.netBullets = bullets
+}
+
+// applyCacti damages the cacti the way they were damaged on the host.
+func (g *Game) applyCacti(s *netplay.Snapshot) {
+	for _, c := range s.Cacti {
+		cactus, ok := g.netCacti[[2]float32{c.X, c.Y}]
+		if ok && cactus.Health > int(c.Health) {
+			cactus.Damage(cactus.Health - int(c.Health))
+		}
+	}
+}
+
+// networkStatus describes the network game for the console.
+func (g *Game) networkStatus() string {
+	switch {
+	case g.host != nil:
+		return fmt.Sprintf("hosting on %s, %d players", g.host.Addr(), len(g.players))
+	case g.client != nil && g.client.Welcomed:
+		return fmt.Sprintf("playing as %s", playerNames[g.client.PlayerID])
+	case g.client != nil:
+		return "waiting for the host"
+	}
+
+	return "not in a network game"
+}
+
+var errNotHost = errors.New("only the host can do that in a network game")
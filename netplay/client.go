@@ -0,0 +1,218 @@
+package netplay
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// helloInterval is how often a client asks to join until the host answers
+	helloInterval = 500 * time.Millisecond
+	// redundantInputs is how many of the last inputs go into every packet, so a lost packet loses nothing
+	redundantInputs = 4
+	// maxPendingInputs is how many unacknowledged inputs are kept while the host does not answer,
+	// the oldest ones are dropped after that
+	maxPendingInputs = 120
+	// InterpolationDelay is how many ticks behind the host the other actors are shown,
+	// enough to always have a snapshot on both sides of the tick that is drawn
+	InterpolationDelay = 2*SnapshotInterval + 2
+	// snapshots older than this many ticks behind the newest one are thrown away
+	snapshotHistory = 60
+	// the drawn tick jumps to where it should be when it drifted further off than this
+	maxDrift = 3 * SnapshotInterval
+)
+
+var (
+	ErrFull     = errors.New("the game is full")
+	ErrTimeout  = errors.New("lost the connection to the host")
+	ErrHostLeft = errors.New("the host closed the game")
+)
+
+// Client joins a host, sends the inputs of its player and keeps the snapshots that come back.
+type Client struct {
+	// PlayerID is the player of this client, known once Welcomed is set
+	PlayerID uint16
+	Seed     int64
+	Welcomed bool
+
+	conn      *Conn
+	lastHello time.Time
+	lastHeard time.Time
+	lastSent  time.Time
+
+	seq     uint32
+	pending []Input
+	// snapshots are kept in tick order, fresh holds the ones that came in since the last update
+	snapshots []*Snapshot
+	fresh     bool
+	// renderTick is the tick of the host the other actors are drawn at
+	renderTick float64
+}
+
+// Connect starts joining the host at addr, Update keeps asking until it answers.
+func Connect(addr string, link Link) (*Client, error) {
+	conn, err := Dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.Link = link
+
+	return &Client{conn: conn, lastHeard: time.Now()}, nil
+}
+
+// Update reads what the host sent and moves the drawn tick forward, once per frame.
+func (c *Client) Update() error {
+	now := time.Now()
+	if !c.Welcomed && now.Sub(c.lastHello) > helloInterval {
+		c.lastHello = now
+		c.conn.Send(nil, encode(helloMessage))
+	}
+
+	c.fresh = false
+	for _, packet := range c.conn.Receive() {
+		kind, body, err := decode(packet.Data)
+		if err != nil {
+			log.Printf("netplay: %s: %v", packet.From, err)
+			continue
+		}
+		c.lastHeard = now
+		switch kind {
+		case welcomeMessage:
+			var w welcome
+			if err := decodeBody(body, &w); err != nil {
+				continue
+			}
+			c.PlayerID, c.Seed, c.Welcomed = w.PlayerID, w.Seed, true
+		case fullMessage:
+			return ErrFull
+		case byeMessage:
+			return ErrHostLeft
+		case snapshotMessage:
+			snapshot, err := decodeSnapshot(body)
+			if err != nil {
+				log.Printf("netplay: %s: %v", packet.From, err)
+				continue
+			}
+			c.add(snapshot)
+		}
+	}
+	if now.Sub(c.lastHeard) > Timeout {
+		return ErrTimeout
+	}
+	// a client in a menu sends no inputs, the host still has to know it is there
+	if c.Welcomed && now.Sub(c.lastSent) > helloInterval {
+		c.send()
+	}
+
+	if latest := c.Latest(); latest != nil {
+		c.renderTick += 1
+		target := float64(latest.Tick) - InterpolationDelay
+		if c.renderTick < target-maxDrift || c.renderTick > target+maxDrift {
+			c.renderTick = target
+		}
+	}
+
+	return nil
+}
+
+// add puts a snapshot in tick order, packets can arrive out of order or twice.
+func (c *Client) add(snapshot *Snapshot) {
+	i := len(c.snapshots)
+	for i > 0 && c.snapshots[i-1].Tick >= snapshot.Tick {
+		if c.snapshots[i-1].Tick == snapshot.Tick {
+			return
+		}
+		i -= 1
+	}
+	c.snapshots = append(c.snapshots, nil)
+	copy(c.snapshots[i+1:], c.snapshots[i:])
+	c.snapshots[i] = snapshot
+	c.fresh = c.fresh || i == len(c.snapshots)-1
+
+	latest := c.snapshots[len(c.snapshots)-1].Tick
+	for len(c.snapshots) > 2 && c.snapshots[0].Tick+snapshotHistory < latest {
+		c.snapshots = c.snapshots[1:]
+	}
+
+	// inputs the host has used are not needed for replaying anymore
+	acked := 0
+	for acked < len(c.pending) && c.pending[acked].Seq <= c.Latest().Ack {
+		acked += 1
+	}
+	c.pending = c.pending[acked:]
+}
+
+// Latest is the newest snapshot, nil before the first one came in.
+func (c *Client) Latest() *Snapshot {
+	if len(c.snapshots) == 0 {
+		return nil
+	}
+
+	return c.snapshots[len(c.snapshots)-1]
+}
+
+// Fresh is true when a newer snapshot than before came in during the last update.
+func (c *Client) Fresh() bool {
+	return c.fresh
+}
+
+// SendInput numbers the input of this frame and sends it with the ones before it.
+func (c *Client) SendInput(input Input) Input {
+	c.seq += 1
+	input.Seq = c.seq
+	c.pending = append(c.pending, input)
+	if len(c.pending) > maxPendingInputs {
+		c.pending = c.pending[len(c.pending)-maxPendingInputs:]
+	}
+	c.send()
+
+	return input
+}
+
+func (c *Client) send() {
+	c.lastSent = time.Now()
+	first := max(0, len(c.pending)-redundantInputs)
+	c.conn.Send(nil, encodeInputs(c.pending[first:]))
+}
+
+// Pending are the inputs the latest snapshot does not include yet, to be played again on top of it.
+func (c *Client) Pending() []Input {
+	return c.pending
+}
+
+// Interpolate finds the snapshots on both sides of the drawn tick and how far it is from a to b, from 0 to 1.
+// Before the drawn tick has two snapshots around it, both are the oldest one.
+func (c *Client) Interpolate() (a, b *Snapshot, t float64) {
+	if len(c.snapshots) == 0 {
+		return nil, nil, 0
+	}
+	for i := len(c.snapshots) - 1; i > 0; i-- {
+		a, b = c.snapshots[i-1], c.snapshots[i]
+		if float64(a.Tick) <= c.renderTick {
+			t = (c.renderTick - float64(a.Tick)) / float64(b.Tick-a.Tick)
+			return a, b, min(1, t)
+		}
+	}
+
+	return c.snapshots[0], c.snapshots[0], 0
+}
+
+// Lerp is the actor between a and b, the things that can not be in between come from the closer one.
+func Lerp(a, b Actor, t float64) Actor {
+	actor := a
+	if t >= 0.5 {
+		actor = b
+	}
+	actor.X = a.X + (b.X-a.X)*float32(t)
+	actor.Y = a.Y + (b.Y-a.Y)*float32(t)
+
+	return actor
+}
+
+// Close tells the host the client is leaving.
+func (c *Client) Close() error {
+	c.conn.write(c.conn.remote, encode(byeMessage))
+
+	return c.conn.Close()
+}
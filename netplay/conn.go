@@ -0,0 +1,169 @@
+// Package netplay runs a game over UDP: an authoritative host simulates the world,
+// clients send their inputs and draw the snapshots the host sends back.
+package netplay
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// maxPacketSize is the largest datagram that is read, snapshots stay well below it
+	maxPacketSize = 64 * 1024
+	// receiveQueue is how many packets wait for the game to pick them up before new ones are dropped
+	receiveQueue = 256
+)
+
+// Link makes a connection worse on purpose, to try the game on localhost the way it plays over the internet.
+// The zero Link sends everything right away.
+type Link struct {
+	// Latency delays every packet that is sent, Jitter adds up to that much on top at random
+	Latency time.Duration
+	Jitter  time.Duration
+	// Loss is the chance a packet is dropped, from 0 to 1
+	Loss float64
+}
+
+// Packet is a datagram and where it came from.
+type Packet struct {
+	From *net.UDPAddr
+	Data []byte
+}
+
+// Conn is a UDP socket that is read in the background, so the game can poll it every frame without blocking.
+type Conn struct {
+	Link Link
+
+	udp *net.UDPConn
+	// remote is where a dialed connection sends to
+	remote  *net.UDPAddr
+	packets chan Packet
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Listen opens a socket on addr for a host, like ":7777".
+func Listen(addr string) (*Conn, error) {
+	local, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	udp, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConn(udp, nil), nil
+}
+
+// Dial opens a socket that talks to the host at addr.
+func Dial(addr string) (*Conn, error) {
+	remote, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	udp, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConn(udp, remote), nil
+}
+
+func newConn(udp *net.UDPConn, remote *net.UDPAddr) *Conn {
+	c := &Conn{
+		udp:     udp,
+		remote:  remote,
+		packets: make(chan Packet, receiveQueue),
+	}
+	go c.read()
+
+	return c
+}
+
+func (c *Conn) read() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, from, err := c.udp.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			close(c.packets)
+			return
+		}
+		if err != nil {
+			// an unreachable peer shows up as a read error on some systems, the timeouts deal with it
+			continue
+		}
+		if c.remote != nil && !from.IP.Equal(c.remote.IP) {
+			continue
+		}
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		select {
+		case c.packets <- Packet{From: from, Data: data}:
+		default:
+		}
+	}
+}
+
+// Receive returns the packets that came in since the last call.
+func (c *Conn) Receive() []Packet {
+	packets := []Packet{}
+	for {
+		select {
+		case packet, ok := <-c.packets:
+			if !ok {
+				return packets
+			}
+			packets = append(packets, packet)
+		default:
+			return packets
+		}
+	}
+}
+
+// Send writes data to to, or to the host for a dialed connection.
+// The Link may drop the packet or send it later.
+func (c *Conn) Send(to *net.UDPAddr, data []byte) {
+	if to == nil {
+		to = c.remote
+	}
+	link := c.Link
+	if link.Loss > 0 && rand.Float64() < link.Loss {
+		return
+	}
+	delay := link.Latency
+	if link.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(link.Jitter)))
+	}
+	if delay <= 0 {
+		c.write(to, data)
+		return
+	}
+	time.AfterFunc(delay, func() { c.write(to, data) })
+}
+
+func (c *Conn) write(to *net.UDPAddr, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	_, _ = c.udp.WriteToUDP(data, to)
+}
+
+// LocalAddr is the address the socket is bound to.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.udp.LocalAddr()
+}
+
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	return c.udp.Close()
+}
@@ -0,0 +1,178 @@
+package netplay
+
+import (
+	"log"
+	"net"
+	"time"
+)
+
+const (
+	// Timeout is how long a peer can go quiet before it counts as gone
+	Timeout = 5 * time.Second
+	// SnapshotInterval is the number of ticks between two snapshots
+	SnapshotInterval = 3
+	// a client that gets ahead has its oldest inputs merged, so the host does not fall behind on it
+	maxQueuedInputs = 6
+	// idleTicks is how long the last input of a quiet peer is kept up before the player stands still
+	idleTicks = 10
+)
+
+// Peer is a client connected to the host.
+type Peer struct {
+	Addr *net.UDPAddr
+	// PlayerID is the player the peer controls
+	PlayerID uint16
+
+	lastHeard time.Time
+	inputs    []Input
+	// lastSeq is the newest input that was queued, older ones that arrive again are dropped
+	lastSeq uint32
+	// used is the last input handed to the game
+	used Input
+	idle int
+}
+
+// Host accepts clients and passes their inputs to the game, the game simulates and sends back snapshots.
+type Host struct {
+	// Seed is the seed of the world, new peers build their world from it
+	Seed int64
+	// Join is asked to give a new peer a player, it sets PlayerID and returns false when there is no room
+	Join func(peer *Peer) bool
+
+	conn  *Conn
+	peers map[string]*Peer
+	tick  uint32
+}
+
+// NewHost starts listening on addr.
+func NewHost(addr string, seed int64, link Link) (*Host, error) {
+	conn, err := Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.Link = link
+
+	return &Host{
+		Seed:  seed,
+		conn:  conn,
+		peers: map[string]*Peer{},
+	}, nil
+}
+
+// Addr is where the clients connect to.
+func (h *Host) Addr() net.Addr {
+	return h.conn.LocalAddr()
+}
+
+// Update reads what the peers sent this tick and returns the ones that joined or left.
+func (h *Host) Update() (joined, left []*Peer) {
+	h.tick += 1
+	now := time.Now()
+	for _, packet := range h.conn.Receive() {
+		kind, body, err := decode(packet.Data)
+		if err != nil {
+			log.Printf("netplay: %s: %v", packet.From, err)
+			continue
+		}
+		key := packet.From.String()
+		peer := h.peers[key]
+		switch kind {
+		case helloMessage:
+			if peer == nil {
+				peer = &Peer{Addr: packet.From}
+				if !h.Join(peer) {
+					h.conn.Send(packet.From, encode(fullMessage))
+					continue
+				}
+				h.peers[key] = peer
+				joined = append(joined, peer)
+			}
+			// a lost welcome is answered again when the hello is repeated
+			peer.lastHeard = now
+			h.conn.Send(peer.Addr, encode(welcomeMessage, welcome{PlayerID: peer.PlayerID, Seed: h.Seed}))
+		case inputMessage:
+			if peer == nil {
+				continue
+			}
+			inputs, err := decodeInputs(body)
+			if err != nil {
+				log.Printf("netplay: %s: %v", packet.From, err)
+				continue
+			}
+			peer.lastHeard = now
+			peer.queue(inputs)
+		case byeMessage:
+			if peer != nil {
+				delete(h.peers, key)
+				left = append(left, peer)
+			}
+		}
+	}
+
+	for key, peer := range h.peers {
+		if now.Sub(peer.lastHeard) > Timeout {
+			delete(h.peers, key)
+			left = append(left, peer)
+		}
+	}
+
+	return joined, left
+}
+
+// queue adds the inputs the peer had not sent before, every packet repeats the last few in case one got lost.
+func (p *Peer) queue(inputs []Input) {
+	for _, input := range inputs {
+		if input.Seq <= p.lastSeq {
+			continue
+		}
+		p.lastSeq = input.Seq
+		p.inputs = append(p.inputs, input)
+	}
+	for len(p.inputs) > maxQueuedInputs {
+		// the presses of the skipped input are kept, so no shot gets lost
+		p.inputs[1].ButtonsJustPressed |= p.inputs[0].ButtonsJustPressed
+		p.inputs[1].KeysJustPressed |= p.inputs[0].KeysJustPressed
+		p.inputs = p.inputs[1:]
+	}
+}
+
+// NextInput is the input of the peer for this tick. When none came in the last one is held for
+// a moment without its presses, after that the player lets go of everything.
+func (p *Peer) NextInput() Input {
+	if len(p.inputs) > 0 {
+		p.used = p.inputs[0]
+		p.inputs = p.inputs[1:]
+		p.idle = 0
+		return p.used
+	}
+	p.idle += 1
+	if p.idle > idleTicks {
+		return Input{Seq: p.used.Seq}
+	}
+
+	return p.used.Idle()
+}
+
+// SnapshotDue is true on the ticks a snapshot should be sent.
+func (h *Host) SnapshotDue() bool {
+	return h.tick%SnapshotInterval == 0
+}
+
+// Send sends the snapshot to every peer, with the last input of that peer the host used.
+func (h *Host) Send(snapshot *Snapshot) {
+	snapshot.Tick = h.tick
+	snapshot.Seed = h.Seed
+	for _, peer := range h.peers {
+		snapshot.Ack = peer.used.Seq
+		h.conn.Send(peer.Addr, encodeSnapshot(snapshot))
+	}
+}
+
+// Close says goodbye to the peers and stops listening.
+func (h *Host) Close() error {
+	for _, peer := range h.peers {
+		h.conn.write(peer.Addr, encode(byeMessage))
+	}
+
+	return h.conn.Close()
+}
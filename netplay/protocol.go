@@ -0,0 +1,207 @@
+package netplay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// protocolVersion changes whenever a message changes, hosts and clients of different versions can not talk
+const protocolVersion = 1
+
+var magic = [2]byte{'F', 'W'}
+
+type messageKind uint8
+
+const (
+	helloMessage messageKind = iota
+	welcomeMessage
+	fullMessage
+	inputMessage
+	snapshotMessage
+	byeMessage
+)
+
+var errBadPacket = errors.New("not a far west packet")
+
+type header struct {
+	Magic   [2]byte
+	Version uint8
+	Kind    messageKind
+}
+
+// Input is what the devices of a player on a client did in one frame. The buttons and keys are bit sets,
+// which bit is which is up to the game.
+type Input struct {
+	Seq uint32
+	// the sticks from -1 to 1
+	MoveX, MoveY float32
+	AimX, AimY   float32
+
+	Buttons            uint32
+	ButtonsJustPressed uint32
+	Keys               uint32
+	KeysJustPressed    uint32
+}
+
+// Idle is the input without the presses, what a player keeps doing when their inputs stop coming.
+func (in Input) Idle() Input {
+	in.ButtonsJustPressed = 0
+	in.KeysJustPressed = 0
+
+	return in
+}
+
+type ActorKind uint8
+
+const (
+	PlayerActor ActorKind = iota
+	EnemyActor
+	CompanionActor
+)
+
+const (
+	DeadFlag uint8 = 1 << iota
+	RunningFlag
+)
+
+// Actor is the state of a player, enemy or gang member in a snapshot.
+type Actor struct {
+	Kind ActorKind
+	// ID is the player id for players, the id an enemy got when it spawned and the index in the gang for its members
+	ID                uint16
+	X, Y              float32
+	Sprite            uint16
+	Health, MaxHealth int16
+	Stamina           int16
+	Ammo              uint8
+	Weapon            uint8
+	Dir               uint8
+	// Sheet is the sprite sheet of an enemy
+	Sheet uint8
+	Flags uint8
+}
+
+// Bullet is a bullet in flight, the client keeps flying it between snapshots.
+type Bullet struct {
+	ID uint32
+	// Owner is the index of the shooter in the actors of the snapshot
+	Owner    uint16
+	X, Y     float32
+	R, Speed float32
+	Duration uint16
+}
+
+// Cactus is a cactus that was shot at, the ones without a scratch are left out.
+type Cactus struct {
+	X, Y   float32
+	Health int16
+}
+
+type snapshotHeader struct {
+	Tick uint32
+	Seed int64
+	Ack  uint32
+
+	Actors  uint16
+	Bullets uint16
+	Cacti   uint16
+}
+
+// Snapshot is the state of the world on the host at a tick.
+type Snapshot struct {
+	Tick uint32
+	// Seed is the seed of the world, a client builds the world again when it changes
+	Seed int64
+	// Ack is the last input of the receiving client the host used
+	Ack     uint32
+	Actors  []Actor
+	Bullets []Bullet
+	Cacti   []Cactus
+}
+
+type welcome struct {
+	PlayerID uint16
+	Seed     int64
+}
+
+func encode(kind messageKind, body ...any) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, header{Magic: magic, Version: protocolVersion, Kind: kind})
+	for _, part := range body {
+		// writing to a buffer only fails for types that are not fixed size
+		if err := binary.Write(buf, binary.LittleEndian, part); err != nil {
+			panic(fmt.Sprintf("netplay: %v", err))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func decode(data []byte) (messageKind, *bytes.Reader, error) {
+	r := bytes.NewReader(data)
+	var h header
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil || h.Magic != magic {
+		return 0, nil, errBadPacket
+	}
+	if h.Version != protocolVersion {
+		return 0, nil, fmt.Errorf("protocol version %d, this game speaks %d", h.Version, protocolVersion)
+	}
+
+	return h.Kind, r, nil
+}
+
+func encodeInputs(inputs []Input) []byte {
+	return encode(inputMessage, uint8(len(inputs)), inputs)
+}
+
+func decodeInputs(r *bytes.Reader) ([]Input, error) {
+	var count uint8
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	inputs := make([]Input, count)
+	if err := binary.Read(r, binary.LittleEndian, inputs); err != nil {
+		return nil, err
+	}
+
+	return inputs, nil
+}
+
+func encodeSnapshot(s *Snapshot) []byte {
+	return encode(snapshotMessage, snapshotHeader{
+		Tick:    s.Tick,
+		Seed:    s.Seed,
+		Ack:     s.Ack,
+		Actors:  uint16(len(s.Actors)),
+		Bullets: uint16(len(s.Bullets)),
+		Cacti:   uint16(len(s.Cacti)),
+	}, s.Actors, s.Bullets, s.Cacti)
+}
+
+func decodeSnapshot(r *bytes.Reader) (*Snapshot, error) {
+	var h snapshotHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		Tick:    h.Tick,
+		Seed:    h.Seed,
+		Ack:     h.Ack,
+		Actors:  make([]Actor, h.Actors),
+		Bullets: make([]Bullet, h.Bullets),
+		Cacti:   make([]Cactus, h.Cacti),
+	}
+	for _, part := range []any{s.Actors, s.Bullets, s.Cacti} {
+		if err := binary.Read(r, binary.LittleEndian, part); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func decodeBody(r *bytes.Reader, body any) error {
+	return binary.Read(r, binary.LittleEndian, body)
+}
@@ -0,0 +1,124 @@
+package netplay
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot *Snapshot
+	}{
+		{"empty", &Snapshot{Tick: 1, Seed: -42, Ack: 0, Actors: []Actor{}, Bullets: []Bullet{}, Cacti: []Cactus{}}},
+		{"world", &Snapshot{
+			Tick: 90,
+			Seed: 1234567890123,
+			Ack:  88,
+			Actors: []Actor{
+				{Kind: PlayerActor, ID: 0, X: 10.5, Y: -3, Sprite: 4, Health: 20, MaxHealth: 20, Stamina: 100, Ammo: 6, Weapon: 1, Dir: 2, Flags: RunningFlag},
+				{Kind: EnemyActor, ID: 513, X: 400, Y: 300, Health: 0, MaxHealth: 30, Sheet: 2, Flags: DeadFlag},
+				{Kind: CompanionActor, ID: 1, X: -20, Y: 15, Health: 12, MaxHealth: 15},
+			},
+			Bullets: []Bullet{{ID: 77, Owner: 1, X: 401, Y: 299, R: 3.14, Speed: 4, Duration: 500}},
+			Cacti:   []Cactus{{X: 64, Y: 128, Health: 3}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, r, err := decode(encodeSnapshot(tt.snapshot))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if kind != snapshotMessage {
+				t.Fatalf("kind %d, want %d", kind, snapshotMessage)
+			}
+			got, err := decodeSnapshot(r)
+			if err != nil {
+				t.Fatalf("decodeSnapshot: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.snapshot) {
+				t.Errorf("got %+v, want %+v", got, tt.snapshot)
+			}
+			if r.Len() != 0 {
+				t.Errorf("%d bytes left over", r.Len())
+			}
+		})
+	}
+}
+
+func TestInputsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []Input
+	}{
+		{"none", []Input{}},
+		{"one", []Input{{Seq: 1, MoveX: -1, MoveY: 0.5, Buttons: 1 << 3, KeysJustPressed: 1 << 5}}},
+		{"several", []Input{
+			{Seq: 7, AimX: 0.25, AimY: -0.75, Keys: 3},
+			{Seq: 8, Buttons: 0xffffffff, ButtonsJustPressed: 1},
+			{Seq: 9},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, r, err := decode(encodeInputs(tt.inputs))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if kind != inputMessage {
+				t.Fatalf("kind %d, want %d", kind, inputMessage)
+			}
+			got, err := decodeInputs(r)
+			if err != nil {
+				t.Fatalf("decodeInputs: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.inputs) {
+				t.Errorf("got %+v, want %+v", got, tt.inputs)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	snapshot := encodeSnapshot(&Snapshot{Actors: []Actor{{ID: 1}}})
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"wrong magic", append([]byte{'X', 'X'}, snapshot[2:]...)},
+		{"other version", append([]byte{'F', 'W', protocolVersion + 1}, snapshot[3:]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decode(tt.data); err == nil {
+				t.Errorf("decode accepted %v", tt.data)
+			}
+		})
+	}
+
+	t.Run("cut off snapshot", func(t *testing.T) {
+		_, r, err := decode(snapshot[:len(snapshot)-4])
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if _, err := decodeSnapshot(r); err == nil {
+			t.Errorf("decodeSnapshot accepted a snapshot without its last bytes")
+		}
+	})
+	t.Run("bad packet", func(t *testing.T) {
+		if _, _, err := decode([]byte{'X'}); !errors.Is(err, errBadPacket) {
+			t.Errorf("decode: %v, want %v", err, errBadPacket)
+		}
+	})
+}
+
+func TestInputIdle(t *testing.T) {
+	in := Input{Seq: 3, MoveX: 1, Buttons: 5, ButtonsJustPressed: 4, Keys: 9, KeysJustPressed: 8}
+	want := Input{Seq: 3, MoveX: 1, Buttons: 5, Keys: 9}
+	if got := in.Idle(); got != want {
+		t.Errorf("Idle() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package particles
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Config describes how the particles of one kind of effect look and move.
+type Config struct {
+	// Speed is the minimum speed, a random amount up to SpeedSpread is added
+	Speed       float64
+	SpeedSpread float64
+	// Angle is the direction in radians, particles stray up to Spread from it
+	Angle  float64
+	Spread float64
+	// Gravity pulls particles down the screen every frame
+	Gravity float64
+	// Drag slows particles down, 0 keeps the speed and 1 stops them at once
+	Drag float64
+	// Life in frames, a random amount up to LifeSpread is added
+	Life       int
+	LifeSpread int
+	StartColor color.NRGBA
+	EndColor   color.NRGBA
+	StartSize  float32
+	EndSize    float32
+}
+
+// Particle is kept by value in the pool, so no allocations happen while emitting.
+type Particle struct {
+	X, Y    float64
+	VX, VY  float64
+	Life    int
+	MaxLife int
+	config  *Config
+}
+
+// Emitter keeps spawning particles until its duration runs out.
+type Emitter struct {
+	Config *Config
+	X, Y   float64
+	// Rate is the number of particles per frame, fractions carry over to the next frame
+	Rate float64
+	// Duration in frames, a negative duration emits until the emitter is stopped
+	Duration int
+	Stopped  bool
+
+	accumulated float64
+}
+
+// System is a fixed size pool of particles, when it is full new particles are dropped.
+// Living particles are always packed at the start of the pool.
+type System struct {
+	particles []Particle
+	alive     int
+	emitters  []*Emitter
+
+	pixel    *ebiten.Image
+	vertices []ebiten.Vertex
+	indices  []uint32
+}
+
+func NewSystem(capacity int) *System {
+	whiteImage := ebiten.NewImage(3, 3)
+	whiteImage.Fill(color.White)
+
+	return &System{
+		particles: make([]Particle, capacity),
+		// sampling the middle pixel avoids bleeding at the edges
+		pixel:    whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image),
+		vertices: make([]ebiten.Vertex, 0, 4*capacity),
+		indices:  make([]uint32, 0, 6*capacity),
+	}
+}
+
+// Len is the number of living particles.
+func (s *System) Len() int {
+	return s.alive
+}
+
+// Burst emits amount particles at once.
+func (s *System) Burst(config *Config, x, y float64, amount int) {
+	s.BurstTowards(config, x, y, config.Angle, amount)
+}
+
+// BurstTowards is a burst that ignores the angle of the config,
+// for effects that follow the direction of a shot or an impact.
+func (s *System) BurstTowards(config *Config, x, y, angle float64, amount int) {
+	for range amount {
+		if s.alive >= len(s.particles) {
+			return
+		}
+		dir := angle + (rand.Float64()*2-1)*config.Spread
+		speed := config.Speed + rand.Float64()*config.SpeedSpread
+		life := config.Life
+		if config.LifeSpread > 0 {
+			life += rand.Intn(config.LifeSpread)
+		}
+		s.particles[s.alive] = Particle{
+			X:       x,
+			Y:       y,
+			VX:      speed * math.Cos(dir),
+			VY:      speed * math.Sin(dir),
+			Life:    life,
+			MaxLife: life,
+			config:  config,
+		}
+		s.alive += 1
+	}
+}
+
+// Emit starts a continuous emitter, stop it by setting Stopped.
+func (s *System) Emit(emitter *Emitter) *Emitter {
+	s.emitters = append(s.emitters, emitter)
+
+	return emitter
+}
+
+func (s *System) Update() {
+	for i := len(s.emitters) - 1; i >= 0; i-- {
+		emitter := s.emitters[i]
+		if emitter.Stopped || emitter.Duration == 0 {
+			s.emitters = append(s.emitters[:i], s.emitters[i+1:]...)
+			continue
+		}
+		emitter.accumulated += emitter.Rate
+		amount := int(emitter.accumulated)
+		emitter.accumulated -= float64(amount)
+		s.Burst(emitter.Config, emitter.X, emitter.Y, amount)
+		if emitter.Duration > 0 {
+			emitter.Duration -= 1
+		}
+	}
+
+	for i := 0; i < s.alive; {
+		p := &s.particles[i]
+		p.Life -= 1
+		if p.Life <= 0 {
+			// move the last living particle into the free spot
+			s.alive -= 1
+			s.particles[i] = s.particles[s.alive]
+			continue
+		}
+		p.VY += p.config.Gravity
+		p.VX *= 1 - p.config.Drag
+		p.VY *= 1 - p.config.Drag
+		p.X += p.VX
+		p.Y += p.VY
+		i += 1
+	}
+}
+
+func lerp(from, to uint8, t float32) float32 {
+	return (float32(from) + (float32(to)-float32(from))*t) / 255
+}
+
+// Draw renders all particles as squares with a single draw call.
+func (s *System) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	if s.alive == 0 {
+		return
+	}
+	s.vertices = s.vertices[:0]
+	s.indices = s.indices[:0]
+	for i := range s.alive {
+		p := &s.particles[i]
+		c := p.config
+		t := 1 - float32(p.Life)/float32(p.MaxLife)
+		size := c.StartSize + (c.EndSize-c.StartSize)*t
+		x := float32(p.X-camX) - size/2
+		y := float32(p.Y-camY) - size/2
+		r := lerp(c.StartColor.R, c.EndColor.R, t)
+		g := lerp(c.StartColor.G, c.EndColor.G, t)
+		b := lerp(c.StartColor.B, c.EndColor.B, t)
+		a := lerp(c.StartColor.A, c.EndColor.A, t)
+
+		base := uint32(len(s.vertices))
+		for _, corner := range [4][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+			s.vertices = append(s.vertices, ebiten.Vertex{
+				DstX:   x + corner[0]*size,
+				DstY:   y + corner[1]*size,
+				SrcX:   1 + corner[0],
+				SrcY:   1 + corner[1],
+				ColorR: r,
+				ColorG: g,
+				ColorB: b,
+				ColorA: a,
+			})
+		}
+		s.indices = append(s.indices, base, base+1, base+2, base+1, base+3, base+2)
+	}
+	screen.DrawTriangles32(s.vertices, s.indices, s.pixel, &ebiten.DrawTrianglesOptions{})
+}
@@ -0,0 +1,183 @@
+// Package piskel reads the .piskel files the art of the game is drawn in.
+package piskel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// File is a decoded piskel with all layers flattened into one image per frame.
+type File struct {
+	Name   string
+	Width  int
+	Height int
+	FPS    int
+	Frames []image.Image
+}
+
+type document struct {
+	ModelVersion int `json:"modelVersion"`
+	Piskel       struct {
+		Name   string `json:"name"`
+		FPS    int    `json:"fps"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+		// every layer is a json document of its own
+		Layers []string `json:"layers"`
+	} `json:"piskel"`
+}
+
+type layer struct {
+	Name       string   `json:"name"`
+	Opacity    *float64 `json:"opacity"`
+	FrameCount int      `json:"frameCount"`
+	Chunks     []chunk  `json:"chunks"`
+	// older piskels keep the whole layer in a single strip
+	Base64PNG string `json:"base64PNG"`
+}
+
+// chunk is one png with part of the frames of a layer,
+// Layout[x][y] is the frame drawn at column x and row y of the png.
+type chunk struct {
+	Layout    [][]int `json:"layout"`
+	Base64PNG string  `json:"base64PNG"`
+}
+
+func Load(fsys fs.FS, path string) (*File, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open piskel %s: %w", path, err)
+	}
+	defer f.Close()
+
+	file, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read piskel %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+func Decode(r io.Reader) (*File, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Piskel.Width <= 0 || doc.Piskel.Height <= 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", doc.Piskel.Width, doc.Piskel.Height)
+	}
+	if len(doc.Piskel.Layers) == 0 {
+		return nil, fmt.Errorf("piskel has no layers")
+	}
+
+	file := &File{
+		Name:   doc.Piskel.Name,
+		Width:  doc.Piskel.Width,
+		Height: doc.Piskel.Height,
+		FPS:    doc.Piskel.FPS,
+	}
+	frames := []*image.NRGBA{}
+	for i, rawLayer := range doc.Piskel.Layers {
+		var l layer
+		if err := json.Unmarshal([]byte(rawLayer), &l); err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i, err)
+		}
+		for len(frames) < l.FrameCount {
+			frames = append(frames, image.NewNRGBA(image.Rect(0, 0, file.Width, file.Height)))
+		}
+		if err := file.drawLayer(l, frames); err != nil {
+			return nil, fmt.Errorf("layer %q: %w", l.Name, err)
+		}
+	}
+	for _, frame := range frames {
+		file.Frames = append(file.Frames, frame)
+	}
+
+	return file, nil
+}
+
+// drawLayer draws every frame of the layer on top of the frames below it.
+func (f *File) drawLayer(l layer, frames []*image.NRGBA) error {
+	chunks := l.Chunks
+	if len(chunks) == 0 && l.Base64PNG != "" {
+		strip := chunk{Base64PNG: l.Base64PNG}
+		for i := range l.FrameCount {
+			strip.Layout = append(strip.Layout, []int{i})
+		}
+		chunks = []chunk{strip}
+	}
+	// layers saved without an opacity are fully opaque
+	opacity := 1.0
+	if l.Opacity != nil {
+		opacity = *l.Opacity
+	}
+	mask := image.NewUniform(color.Alpha{A: uint8(255 * opacity)})
+
+	for _, c := range chunks {
+		img, err := decodePNG(c.Base64PNG)
+		if err != nil {
+			return err
+		}
+		for x, column := range c.Layout {
+			for y, frame := range column {
+				if frame < 0 || frame >= len(frames) {
+					return fmt.Errorf("chunk refers to frame %d of %d", frame, len(frames))
+				}
+				src := image.Pt(x*f.Width, y*f.Height)
+				if !src.In(img.Bounds()) {
+					return fmt.Errorf("frame %d is outside of its chunk", frame)
+				}
+				draw.DrawMask(frames[frame], frames[frame].Bounds(), img, src, mask, image.Point{}, draw.Over)
+			}
+		}
+	}
+
+	return nil
+}
+
+func decodePNG(dataURL string) (image.Image, error) {
+	_, data, found := strings.Cut(dataURL, "base64,")
+	if !found {
+		return nil, fmt.Errorf("chunk is not a base64 png")
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return png.Decode(bytes.NewReader(raw))
+}
+
+// FrameDuration is how many game ticks a frame stays on screen at the fps of the piskel.
+func (f *File) FrameDuration(ticksPerSecond int) int {
+	if f.FPS <= 0 {
+		return ticksPerSecond
+	}
+
+	return max(1, ticksPerSecond/f.FPS)
+}
+
+// SpriteSheet puts all frames next to each other, wrapping after the given number of columns.
+// Zero columns puts every frame on a single row, the way the game loads its sprites.
+func (f *File) SpriteSheet(columns int) *image.NRGBA {
+	if columns <= 0 || columns > len(f.Frames) {
+		columns = max(1, len(f.Frames))
+	}
+	rows := (len(f.Frames) + columns - 1) / columns
+	sheet := image.NewNRGBA(image.Rect(0, 0, columns*f.Width, max(1, rows)*f.Height))
+	for i, frame := range f.Frames {
+		at := image.Pt(i%columns*f.Width, i/columns*f.Height)
+		draw.Draw(sheet, image.Rectangle{Min: at, Max: at.Add(image.Pt(f.Width, f.Height))}, frame, image.Point{}, draw.Src)
+	}
+
+	return sheet
+}
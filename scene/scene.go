@@ -0,0 +1,126 @@
+// Package scene keeps a stack of screens, like the title screen or the game itself,
+// and changes between them with transitions.
+package scene
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Scene is a screen of the game. Only the scene on top of the stack is updated,
+// the scenes below it are frozen.
+type Scene interface {
+	// Enter is called when the scene is put on the stack
+	Enter()
+	// Exit is called when the scene is taken off the stack
+	Exit()
+	Update() error
+	Draw(screen *ebiten.Image)
+}
+
+// Overlay is a scene that is drawn on top of the scene below it, like a pause menu,
+// other scenes hide everything below them.
+type Overlay interface {
+	Scene
+	Overlay() bool
+}
+
+func isOverlay(s Scene) bool {
+	overlay, ok := s.(Overlay)
+
+	return ok && overlay.Overlay()
+}
+
+// Manager updates the scene on top of the stack and draws the visible scenes.
+type Manager struct {
+	stack []Scene
+
+	transition Transition
+	change     func()
+	frame      int
+}
+
+// Push puts the scene on top of the stack.
+func (m *Manager) Push(s Scene) {
+	m.stack = append(m.stack, s)
+	s.Enter()
+}
+
+// Pop takes the scene on top off the stack.
+func (m *Manager) Pop() {
+	if len(m.stack) == 0 {
+		return
+	}
+	top := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	top.Exit()
+}
+
+// Replace empties the stack and puts the scenes on it, the last one on top.
+func (m *Manager) Replace(scenes ...Scene) {
+	for len(m.stack) > 0 {
+		m.Pop()
+	}
+	for _, s := range scenes {
+		m.Push(s)
+	}
+}
+
+func (m *Manager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1]
+}
+
+// Transition covers the screen, calls change when it is fully covered and uncovers it again.
+// Nothing is updated while a transition runs, a new transition replaces a running one.
+func (m *Manager) Transition(t Transition, change func()) {
+	if m.change != nil {
+		m.change()
+	}
+	m.transition = t
+	m.change = change
+	m.frame = 0
+}
+
+// Transitioning reports whether a transition is running.
+func (m *Manager) Transitioning() bool {
+	return m.transition != nil
+}
+
+func (m *Manager) Update() error {
+	if m.transition != nil {
+		m.frame += 1
+		if m.frame >= m.transition.Frames()/2 && m.change != nil {
+			change := m.change
+			m.change = nil
+			change()
+		}
+		if m.frame >= m.transition.Frames() {
+			m.transition = nil
+		}
+		return nil
+	}
+
+	if top := m.Top(); top != nil {
+		return top.Update()
+	}
+
+	return nil
+}
+
+// Draw draws the scene on top and every overlay between it and the first scene that hides the rest.
+func (m *Manager) Draw(screen *ebiten.Image) {
+	bottom := len(m.stack) - 1
+	for bottom > 0 && isOverlay(m.stack[bottom]) {
+		bottom -= 1
+	}
+	for i := max(bottom, 0); i < len(m.stack); i++ {
+		m.stack[i].Draw(screen)
+	}
+
+	if m.transition != nil {
+		m.transition.Draw(screen, float64(m.frame)/float64(m.transition.Frames()))
+	}
+}
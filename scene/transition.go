@@ -0,0 +1,54 @@
+package scene
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Transition draws the change between two scenes over the screen.
+type Transition interface {
+	// Frames is how long the whole transition takes
+	Frames() int
+	// Draw covers the screen, progress goes from 0 to 1 and the screen is fully covered at 0.5
+	Draw(screen *ebiten.Image, progress float64)
+}
+
+// Fade fades the screen to a color and back.
+type Fade struct {
+	Duration int
+	Color    color.RGBA
+}
+
+func (f Fade) Frames() int {
+	return f.Duration
+}
+
+func (f Fade) Draw(screen *ebiten.Image, progress float64) {
+	alpha := 1 - math.Abs(2*progress-1)
+	bounds := screen.Bounds()
+	clr := color.NRGBA{f.Color.R, f.Color.G, f.Color.B, uint8(255 * alpha)}
+	vector.FillRect(screen, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()), clr, false)
+}
+
+// Wipe slides a curtain of a color over the screen from the left and off again to the right.
+type Wipe struct {
+	Duration int
+	Color    color.RGBA
+}
+
+func (w Wipe) Frames() int {
+	return w.Duration
+}
+
+func (w Wipe) Draw(screen *ebiten.Image, progress float64) {
+	bounds := screen.Bounds()
+	width := float64(bounds.Dx())
+	left, right := 0.0, 2*progress*width
+	if progress > 0.5 {
+		left, right = (2*progress-1)*width, width
+	}
+	vector.FillRect(screen, float32(left), 0, float32(right-left), float32(bounds.Dy()), w.Color, false)
+}
@@ -0,0 +1,154 @@
+package farwest
+
+import (
+	"image/color"
+
+	"github.com/bramca/Far-West/scene"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	// fadeToBlack starts and restarts a run
+	fadeToBlack = scene.Fade{Duration: 40, Color: color.RGBA{0, 0, 0, 255}}
+	// wipeToTitle leaves a run for the title screen
+	wipeToTitle = scene.Wipe{Duration: 50, Color: color.RGBA{40, 30, 20, 255}}
+)
+
+// worldScene is the game itself.
+type worldScene struct {
+	g *Game
+}
+
+func (s *worldScene) Enter() {}
+
+func (s *worldScene) Exit() {}
+
+// Update runs the world as many times as the time scale asks for, a scene opened
+// by the world, like the pause menu, stops it. The inputs are read once a frame,
+// only the first run sees what was pressed and a frame without a run keeps it for the next one.
+func (s *worldScene) Update() error {
+	s.g.timeBank += s.g.timeScale
+	for s.g.timeBank >= 1 && s.g.scenes.Top() == scene.Scene(s) {
+		s.g.timeBank -= 1
+		// a client only predicts its own player, the host runs the world
+		if s.g.client != nil {
+			s.g.updateClient()
+		} else {
+			s.g.updateWorld()
+		}
+		for _, cp := range s.g.players {
+			cp.input.idle()
+		}
+	}
+
+	return nil
+}
+
+func (s *worldScene) Draw(screen *ebiten.Image) {
+	s.g.drawWorld(screen)
+	s.g.hud.Draw(screen)
+}
+
+// titleScene shows the title menu over the world, without the HUD.
+type titleScene struct {
+	g *Game
+}
+
+func (s *titleScene) Enter() {
+	s.g.menus.Center = 0.5
+	s.g.menus.Open(s.g.titleMenu)
+}
+
+func (s *titleScene) Exit() {}
+
+func (s *titleScene) Update() error {
+	s.g.menus.Update(s.g.menuInput(s.g.buttonsJustPressed))
+
+	return nil
+}
+
+func (s *titleScene) Draw(screen *ebiten.Image) {
+	s.g.drawWorld(screen)
+	s.g.menus.Draw(screen)
+}
+
+// pauseScene shows the pause menu and the stats of the player over the frozen world.
+type pauseScene struct {
+	g *Game
+}
+
+func (s *pauseScene) Enter() {
+	// leave room on the right of the menu for the stats
+	s.g.menus.Center = 1.0 / 3
+	s.g.menus.Open(s.g.pauseMenu)
+}
+
+func (s *pauseScene) Exit() {}
+
+func (s *pauseScene) Overlay() bool {
+	return true
+}
+
+func (s *pauseScene) Update() error {
+	s.g.menus.Update(s.g.menuInput(s.g.buttonsJustPressed))
+
+	return nil
+}
+
+func (s *pauseScene) Draw(screen *ebiten.Image) {
+	s.g.menus.Draw(screen)
+	s.g.drawStats(screen)
+}
+
+// gameOverScene darkens the world the player died in and shows the game over menu.
+type gameOverScene struct {
+	g *Game
+}
+
+func (s *gameOverScene) Enter() {
+	s.g.menus.Center = 0.5
+	s.g.menus.Open(s.g.gameOverMenu)
+}
+
+func (s *gameOverScene) Exit() {}
+
+func (s *gameOverScene) Overlay() bool {
+	return true
+}
+
+func (s *gameOverScene) Update() error {
+	s.g.menus.Update(s.g.menuInput(s.g.buttonsJustPressed))
+
+	return nil
+}
+
+func (s *gameOverScene) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.NRGBA{0, 0, 0, 160}, false)
+	s.g.menus.Draw(screen)
+}
+
+// shopScene trades with a shopkeeper over the frozen world.
+type shopScene struct {
+	g *Game
+}
+
+func (s *shopScene) Enter() {}
+
+func (s *shopScene) Exit() {
+	s.g.shopScreen = nil
+}
+
+func (s *shopScene) Overlay() bool {
+	return true
+}
+
+func (s *shopScene) Update() error {
+	s.g.updateShop(s.g.buttonsJustPressed)
+
+	return nil
+}
+
+func (s *shopScene) Draw(screen *ebiten.Image) {
+	s.g.drawShop(screen)
+}
@@ -0,0 +1,194 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/bramca/Far-West/menu"
+	"github.com/bramca/Far-West/settings"
+	"github.com/bramca/Far-West/viewport"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// loadSettings reads the saved settings, a broken settings file is reported and replaced by the defaults,
+// and so are the settings in it that are out of range.
+func (g *Game) loadSettings() {
+	var err error
+	g.settings, err = settings.Load()
+	if err != nil {
+		log.Printf("settings: %v", err)
+	}
+}
+
+func (g *Game) saveSettings() {
+	if err := g.settings.Save(); err != nil {
+		log.Printf("settings: %v", err)
+	}
+}
+
+// applySettings puts the settings into effect, the dead zone and the sticks are read where they are used.
+func (g *Game) applySettings() {
+	display := g.settings.Display
+	ebiten.SetFullscreen(display.Fullscreen)
+	if !display.Fullscreen {
+		ebiten.SetWindowSize(int(ScreenWidth*display.WindowScale), int(ScreenHeight*display.WindowScale))
+	}
+	ebiten.SetVsyncEnabled(display.VSync)
+	g.viewport.Mode = display.Scaling
+
+	g.sounds.Volumes.Master = g.settings.Audio.Master
+	g.sounds.Volumes.Music = g.settings.Audio.Music
+	g.sounds.Volumes.SFX = g.settings.Audio.SFX
+
+	g.hud.Zoom = g.settings.Accessibility.HUDScale
+}
+
+// toggleItem flips a setting on and off, both when it is picked and with left and right.
+func (g *Game) toggleItem(label string, setting *bool) *menu.Item {
+	toggle := func() {
+		*setting = !*setting
+		g.applySettings()
+	}
+
+	return &menu.Item{
+		Label: label,
+		Value: func() string {
+			if *setting {
+				return "on"
+			}
+			return "off"
+		},
+		Action: toggle,
+		Adjust: func(int) { toggle() },
+	}
+}
+
+// scalingItem cycles through the ways to fit the screen into the window.
+func (g *Game) scalingItem(setting *viewport.Mode) *menu.Item {
+	next := func(dir int) {
+		*setting = setting.Next(dir)
+		g.applySettings()
+	}
+
+	return &menu.Item{
+		Label:  "scaling",
+		Value:  func() string { return setting.String() },
+		Action: func() { next(1) },
+		Adjust: next,
+	}
+}
+
+// stepItem changes a setting in steps between low and high, shown as a percentage.
+func (g *Game) stepItem(label string, setting *float64, low, high, step float64) *menu.Item {
+	return &menu.Item{
+		Label: label,
+		Value: func() string { return fmt.Sprintf("%3.0f%%", *setting*100) },
+		Adjust: func(delta int) {
+			// round away the drift of adding up steps
+			*setting = math.Round(math.Max(low, math.Min(high, *setting+float64(delta)*step))*100) / 100
+			g.applySettings()
+		},
+	}
+}
+
+// settingsScene changes the settings, they are saved when the scene is left.
+type settingsScene struct {
+	g     *Game
+	menus *menu.Navigator
+}
+
+func (g *Game) openSettings() {
+	g.scenes.Push(&settingsScene{g: g})
+}
+
+func (s *settingsScene) Enter() {
+	g := s.g
+	s.menus = &menu.Navigator{
+		TitleFace:  text.NewGoXFace(g.titleArcadeFont),
+		Face:       text.NewGoXFace(g.arcadeFont),
+		InfoFace:   text.NewGoXFace(g.shopFont),
+		Center:     0.5,
+		Top:        100,
+		LineHeight: 2 * float64(g.fontSize),
+	}
+
+	display := &g.settings.Display
+	audio := &g.settings.Audio
+	controls := &g.settings.Controls
+	accessibility := &g.settings.Accessibility
+	coop := &g.settings.Coop
+	s.menus.Open(&menu.Menu{
+		Title:  "Settings",
+		OnBack: g.scenes.Pop,
+		Items: []*menu.Item{
+			{Label: "display", Submenu: &menu.Menu{
+				Title: "Display",
+				Items: []*menu.Item{
+					g.stepItem("window size", &display.WindowScale, 0.5, 2, 0.25),
+					g.toggleItem("fullscreen", &display.Fullscreen),
+					g.toggleItem("vsync", &display.VSync),
+					g.scalingItem(&display.Scaling),
+					{Label: "back", Back: true},
+				},
+			}},
+			{Label: "audio", Submenu: &menu.Menu{
+				Title: "Audio",
+				Items: []*menu.Item{
+					g.stepItem("master volume", &audio.Master, 0, 1, 0.1),
+					g.stepItem("music volume", &audio.Music, 0, 1, 0.1),
+					g.stepItem("effects volume", &audio.SFX, 0, 1, 0.1),
+					{Label: "back", Back: true},
+				},
+			}},
+			{Label: "controls", Submenu: &menu.Menu{
+				Title: "Controls",
+				Items: []*menu.Item{
+					g.stepItem("stick dead zone", &controls.DeadZone, 0.1, 0.9, 0.1),
+					g.toggleItem("swap sticks", &controls.SwapSticks),
+					{Label: "bindings", Submenu: g.controlsMenu},
+					{Label: "back", Back: true},
+				},
+			}},
+			{Label: "accessibility", Submenu: &menu.Menu{
+				Title: "Accessibility",
+				Items: []*menu.Item{
+					g.stepItem("hud size", &accessibility.HUDScale, 0.75, 1.5, 0.25),
+					g.toggleItem("reduce flashes", &accessibility.ReduceFlashes),
+					{Label: "back", Back: true},
+				},
+			}},
+			{Label: "co-op", Submenu: &menu.Menu{
+				Title: "Co-op",
+				Items: []*menu.Item{
+					g.toggleItem("friendly fire", &coop.FriendlyFire),
+					{Label: "back", Back: true},
+				},
+			}},
+			{Label: "reset to defaults", Action: func() {
+				g.settings = settings.Default()
+				g.applySettings()
+			}},
+			{Label: "back", Back: true},
+		},
+	})
+}
+
+func (s *settingsScene) Exit() {
+	s.g.saveSettings()
+}
+
+func (s *settingsScene) Update() error {
+	s.menus.Update(s.g.menuInput(s.g.buttonsJustPressed))
+
+	return nil
+}
+
+func (s *settingsScene) Draw(screen *ebiten.Image) {
+	s.g.drawWorld(screen)
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.NRGBA{0, 0, 0, 160}, false)
+	s.menus.Draw(screen)
+}
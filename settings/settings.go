@@ -0,0 +1,167 @@
+// Package settings keeps the preferences of the player in a file in their config directory.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/viewport"
+)
+
+const (
+	dirName  = "far-west"
+	fileName = "settings.json"
+)
+
+type Display struct {
+	// WindowScale is the size of the window relative to the size of the screen of the game
+	WindowScale float64 `json:"windowScale"`
+	Fullscreen  bool    `json:"fullscreen"`
+	VSync       bool    `json:"vsync"`
+	// Scaling is how the screen of the game is fitted into the window
+	Scaling viewport.Mode `json:"scaling"`
+}
+
+type Audio struct {
+	Master float64 `json:"master"`
+	Music  float64 `json:"music"`
+	SFX    float64 `json:"sfx"`
+}
+
+type Controls struct {
+	// DeadZone is how far a stick has to be pushed before it counts, from 0 to 1
+	DeadZone float64 `json:"deadZone"`
+	// SwapSticks moves with the right stick and aims with the left one
+	SwapSticks bool `json:"swapSticks"`
+}
+
+type Accessibility struct {
+	// HUDScale enlarges the HUD on top of the scaling to the screen
+	HUDScale float64 `json:"hudScale"`
+	// ReduceFlashes leaves out muzzle flashes and the sparks of explosions
+	ReduceFlashes bool `json:"reduceFlashes"`
+}
+
+type Coop struct {
+	// FriendlyFire lets the bullets of a player hurt the other players
+	FriendlyFire bool `json:"friendlyFire"`
+}
+
+type Settings struct {
+	Display       Display       `json:"display"`
+	Audio         Audio         `json:"audio"`
+	Controls      Controls      `json:"controls"`
+	Accessibility Accessibility `json:"accessibility"`
+	Coop          Coop          `json:"coop"`
+}
+
+func Default() Settings {
+	volumes := sound.DefaultVolumes()
+
+	return Settings{
+		Display: Display{
+			WindowScale: 1,
+			VSync:       true,
+		},
+		Audio: Audio{
+			Master: volumes.Master,
+			Music:  volumes.Music,
+			SFX:    volumes.SFX,
+		},
+		Controls: Controls{
+			DeadZone: 0.5,
+		},
+		Accessibility: Accessibility{
+			HUDScale: 1,
+		},
+	}
+}
+
+// Dir is the directory of the game in the config directory of the player,
+// under the XDG config directory on Linux.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dirName), nil
+}
+
+// Path is where the settings are saved.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the settings of the player, the defaults are used when nothing was saved yet
+// and for every setting missing from the file or out of range.
+func Load() (Settings, error) {
+	s := Default()
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Validate puts the settings outside of what the settings menu offers back to their default,
+// the error tells which ones.
+func (s *Settings) Validate() error {
+	defaults := Default()
+	errs := []error{}
+	check := func(name string, value *float64, lowest, highest, def float64) {
+		if *value >= lowest && *value <= highest {
+			return
+		}
+		errs = append(errs, fmt.Errorf("%s %v is not between %v and %v, using %v", name, *value, lowest, highest, def))
+		*value = def
+	}
+	check("window scale", &s.Display.WindowScale, 0.5, 2, defaults.Display.WindowScale)
+	check("master volume", &s.Audio.Master, 0, 1, defaults.Audio.Master)
+	check("music volume", &s.Audio.Music, 0, 1, defaults.Audio.Music)
+	check("sfx volume", &s.Audio.SFX, 0, 1, defaults.Audio.SFX)
+	check("dead zone", &s.Controls.DeadZone, 0.1, 0.9, defaults.Controls.DeadZone)
+	check("hud scale", &s.Accessibility.HUDScale, 0.75, 1.5, defaults.Accessibility.HUDScale)
+
+	return errors.Join(errs...)
+}
+
+func (s Settings) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,171 @@
+package farwest
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/bramca/Far-West/economy"
+	"github.com/bramca/Far-West/items"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type shopScreen struct {
+	shop     *economy.Shop
+	selected int
+	selling  bool
+	message  string
+}
+
+// entries lists what can be traded in the current mode,
+// when selling only the items the player owns are shown
+func (s *shopScreen) entries(g *Game) []*economy.StockEntry {
+	if !s.selling {
+		return s.shop.Stock
+	}
+	result := []*economy.StockEntry{}
+	for _, entry := range s.shop.Stock {
+		if g.player.Inventory.Count(entry.Item.ID) > 0 {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+func (g *Game) openShop(shop *economy.Shop) {
+	g.shopScreen = &shopScreen{
+		shop: shop,
+	}
+	g.scenes.Push(&shopScene{g: g})
+}
+
+func (g *Game) updateShop(buttonsJustPressed map[string]bool) {
+	s := g.shopScreen
+	entries := s.entries(g)
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || buttonsJustPressed["RR"] {
+		g.scenes.Pop()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || buttonsJustPressed["RL"] {
+		s.selling = !s.selling
+		s.selected = 0
+		s.message = ""
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) || inpututil.IsKeyJustPressed(ebiten.KeyZ) || buttonsJustPressed["LT"] {
+		s.selected -= 1
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) || buttonsJustPressed["LB"] {
+		s.selected += 1
+	}
+	if len(entries) == 0 {
+		s.selected = 0
+		return
+	}
+	s.selected = (s.selected + len(entries)) % len(entries)
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) || buttonsJustPressed["RB"] {
+		entry := entries[s.selected]
+		var err error
+		if s.selling {
+			err = s.shop.Sell(g.player, entry.Item.ID, 1)
+		} else {
+			err = s.shop.Buy(g.player, entry.Item.ID, 1)
+		}
+		if err != nil {
+			s.message = err.Error()
+			return
+		}
+		if s.selling {
+			s.message = "sold " + entry.Item.Name
+		} else {
+			s.message = "bought " + entry.Item.Name
+			g.deliverPurchase(entry.Item)
+		}
+	}
+}
+
+// deliverPurchase hands over bought items that do not go into the inventory.
+func (g *Game) deliverPurchase(item *items.Item) {
+	switch item.Kind {
+	case items.Mount:
+		horse := g.newHorse(g.player.X+g.player.W, g.player.Y)
+		horse.Owner = g.player
+		g.horses = append(g.horses, horse)
+		g.shopScreen.message = item.Name + " is waiting outside"
+	case items.Recruit:
+		g.shopScreen.message = g.recruit(item, g.shopScreen.shop)
+	case items.Clothing:
+		g.shopScreen.message = "wearing " + item.Name
+		if err := g.wear(g.player, item); err != nil {
+			g.shopScreen.message = err.Error()
+		}
+	}
+}
+
+func (g *Game) drawShop(screen *ebiten.Image) {
+	s := g.shopScreen
+	face := text.NewGoXFace(g.shopFont)
+	lineHeight := float64(g.shopFontSize + g.newlinePadding/2)
+
+	width, height := g.viewport.Width, g.viewport.Height
+	panelX, panelY := float32(width/6), float32(height/8)
+	panelW, panelH := float32(2*width/3), float32(3*height/4)
+	vector.FillRect(screen, panelX, panelY, panelW, panelH, color.RGBA{20, 14, 8, 220}, false)
+	vector.StrokeRect(screen, panelX, panelY, panelW, panelH, 3.0, color.RGBA{200, 160, 90, 255}, false)
+
+	x := float64(panelX) + 30
+	y := float64(panelY) + 30
+
+	title := s.shop.Name + " - BUY"
+	if s.selling {
+		title = s.shop.Name + " - SELL"
+	}
+	titleOptions := &text.DrawOptions{}
+	titleOptions.GeoM.Translate(x, y)
+	titleOptions.ColorScale = g.titleFontColorScale
+	text.Draw(screen, title, text.NewGoXFace(g.arcadeFont), titleOptions)
+	y += 2 * lineHeight
+
+	entries := s.entries(g)
+	if len(entries) == 0 {
+		emptyOptions := &text.DrawOptions{}
+		emptyOptions.GeoM.Translate(x, y)
+		text.Draw(screen, "nothing to trade", face, emptyOptions)
+	}
+	for i, entry := range entries {
+		price := g.shopScreen.shop.BuyPrice(g.player, entry)
+		amount := entry.Quantity
+		if s.selling {
+			price = s.shop.SellPrice(g.player, entry)
+			amount = g.player.Inventory.Count(entry.Item.ID)
+		}
+		cursor := "  "
+		lineOptions := &text.DrawOptions{}
+		lineOptions.ColorScale.ScaleWithColor(color.RGBA{180, 170, 150, 255})
+		if i == s.selected {
+			cursor = "> "
+			lineOptions.ColorScale.Reset()
+			lineOptions.ColorScale.ScaleWithColor(color.RGBA{255, 220, 120, 255})
+		}
+		lineOptions.GeoM.Translate(x, y)
+		text.Draw(screen, fmt.Sprintf("%s%-20s x%-3d $%d", cursor, entry.Item.Name, amount, price), face, lineOptions)
+		y += lineHeight
+	}
+
+	footerY := float64(panelY+panelH) - 3*lineHeight
+	footerOptions := &text.DrawOptions{}
+	footerOptions.GeoM.Translate(x, footerY)
+	text.Draw(screen, fmt.Sprintf("MONEY: $%d   %s", g.player.Money, s.message), face, footerOptions)
+
+	helpOptions := &text.DrawOptions{}
+	helpOptions.GeoM.Translate(x, footerY+lineHeight)
+	helpOptions.ColorScale.ScaleWithColor(color.RGBA{180, 170, 150, 255})
+	text.Draw(screen, "ENTER: TRADE  TAB: BUY/SELL  ESC: LEAVE", face, helpOptions)
+}
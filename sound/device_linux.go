@@ -0,0 +1,30 @@
+//go:build !android
+
+package sound
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findDevice looks for a sound card or a sound server to play on, the way the ALSA default device finds one.
+func findDevice() error {
+	cards, err := os.ReadFile("/proc/asound/cards")
+	if err == nil && !strings.Contains(string(cards), "no soundcards") && strings.TrimSpace(string(cards)) != "" {
+		return nil
+	}
+	if os.Getenv("PULSE_SERVER") != "" {
+		return nil
+	}
+	if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
+		for _, socket := range []string{"pulse/native", "pipewire-0"} {
+			if _, err := os.Stat(filepath.Join(runtime, socket)); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("no sound card or sound server found")
+}
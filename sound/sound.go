@@ -0,0 +1,283 @@
+// Package sound plays the sound effects and music of the game on top of ebiten's audio package.
+// Every sound is synthesized when the manager is created, the game ships no audio files.
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	SampleRate = 44100
+
+	// bytes of one stereo frame of 32 bit float samples
+	bytesPerFrame = 8
+	// maxVoices limits how many sound effects play at once,
+	// maxVoicesPerEffect keeps a gunfight from drowning out everything else
+	maxVoices          = 32
+	maxVoicesPerEffect = 6
+	// crossfadeTicks is how long switching between music tracks takes
+	crossfadeTicks = 120
+	// quiet sounds are not worth a player
+	minGain = 0.02
+)
+
+type Effect int
+
+const (
+	Gunshot Effect = iota
+	Reload
+	Hit
+	Footstep
+	Death
+	Explosion
+	// Blip is a letter of dialogue being typed out
+	Blip
+)
+
+type Track int
+
+const (
+	ExploreMusic Track = iota
+	CombatMusic
+)
+
+// Volumes are between 0 and 1, the music and sound effect volumes are scaled by the master volume.
+type Volumes struct {
+	Master float64
+	Music  float64
+	SFX    float64
+}
+
+func DefaultVolumes() Volumes {
+	return Volumes{
+		Master: 1,
+		Music:  0.5,
+		SFX:    0.8,
+	}
+}
+
+type effect struct {
+	samples []float32
+	// gain balances the effects against each other
+	gain float64
+}
+
+type voice struct {
+	effect Effect
+	player *audio.Player
+}
+
+type track struct {
+	player *audio.Player
+	// level is how far the track is faded in, from 0 to 1
+	level float64
+}
+
+// Manager plays positional sound effects around a listener and fades between music tracks.
+// A disabled manager does nothing, so the game never has to check if there is sound.
+type Manager struct {
+	Volumes Volumes
+	// HearingDistance is how far from the listener a sound fades out completely
+	HearingDistance float64
+	// PanWidth is the horizontal distance at which a sound is heard in one ear only
+	PanWidth float64
+
+	context   *audio.Context
+	effects   map[Effect]*effect
+	tracks    map[Track]*track
+	music     Track
+	playing   bool
+	voices    []*voice
+	listenerX float64
+	listenerY float64
+}
+
+// NewManager synthesizes all sounds and opens the audio context. When there is no audio device,
+// or the context or a player can not be created, the manager stays silent.
+func NewManager() *Manager {
+	m := &Manager{
+		Volumes:         DefaultVolumes(),
+		HearingDistance: 900,
+		PanWidth:        640,
+	}
+	context := audio.CurrentContext()
+	if context == nil {
+		// Ebitengine stops the game when the device of a context fails to open,
+		// so the device is looked for before the context is made
+		if err := findDevice(); err != nil {
+			log.Printf("no sound: %v", err)
+			return m
+		}
+		context = audio.NewContext(SampleRate)
+	}
+	if context.SampleRate() != SampleRate {
+		log.Printf("no sound: the audio context runs at %d Hz instead of %d Hz", context.SampleRate(), SampleRate)
+		return m
+	}
+
+	m.effects = map[Effect]*effect{
+		Gunshot:   {samples: gunshot(), gain: 0.8},
+		Reload:    {samples: reload(), gain: 0.6},
+		Hit:       {samples: hit(), gain: 0.7},
+		Footstep:  {samples: footstep(), gain: 0.25},
+		Death:     {samples: death(), gain: 0.6},
+		Explosion: {samples: explosion(), gain: 1},
+		Blip:      {samples: blip(), gain: 0.2},
+	}
+	m.tracks = map[Track]*track{}
+	for t, samples := range map[Track][]float32{
+		ExploreMusic: exploreMusic(),
+		CombatMusic:  combatMusic(),
+	} {
+		pcm := stereo(samples, 1, 1)
+		player, err := context.NewPlayerF32(audio.NewInfiniteLoopF32(bytes.NewReader(pcm), int64(len(pcm))))
+		if err != nil {
+			// without a context the manager stays silent
+			log.Printf("no sound: %v", err)
+			return m
+		}
+		m.tracks[t] = &track{player: player}
+	}
+	m.context = context
+
+	return m
+}
+
+func (m *Manager) Enabled() bool {
+	return m.context != nil
+}
+
+// SetListener moves the ears of the game, usually to the center of the camera.
+func (m *Manager) SetListener(x, y float64) {
+	m.listenerX = x
+	m.listenerY = y
+}
+
+// Play plays an effect at full volume in both ears, for sounds that are not in the world.
+func (m *Manager) Play(e Effect) {
+	m.play(e, 1, 0)
+}
+
+// PlayAt plays an effect at a world position, it gets quieter with the distance
+// to the listener and pans to the side it comes from.
+func (m *Manager) PlayAt(e Effect, x, y float64) {
+	dist := math.Hypot(x-m.listenerX, y-m.listenerY)
+	gain := math.Max(0, 1-dist/m.HearingDistance)
+	pan := math.Max(-1, math.Min(1, (x-m.listenerX)/m.PanWidth))
+	m.play(e, gain*gain, pan)
+}
+
+func (m *Manager) play(e Effect, gain, pan float64) {
+	if m.context == nil || gain < minGain || len(m.voices) >= maxVoices {
+		return
+	}
+	sfx, ok := m.effects[e]
+	if !ok {
+		return
+	}
+	count := 0
+	for _, v := range m.voices {
+		if v.effect == e {
+			count += 1
+		}
+	}
+	if count >= maxVoicesPerEffect {
+		return
+	}
+
+	// equal power panning, so a sound in the middle is as loud as one on the side
+	angle := (pan + 1) * math.Pi / 4
+	player, err := m.context.NewPlayerF32(&panReader{
+		samples: sfx.samples,
+		left:    float32(math.Cos(angle)),
+		right:   float32(math.Sin(angle)),
+	})
+	if err != nil {
+		return
+	}
+	player.SetVolume(m.Volumes.Master * m.Volumes.SFX * sfx.gain * gain)
+	player.Play()
+	m.voices = append(m.voices, &voice{effect: e, player: player})
+}
+
+// PlayMusic fades over to the track, it is fine to call it every frame.
+func (m *Manager) PlayMusic(t Track) {
+	m.music = t
+	m.playing = true
+}
+
+// StopMusic fades out the music.
+func (m *Manager) StopMusic() {
+	m.playing = false
+}
+
+// Update fades the music and cleans up finished sound effects, it is called every tick.
+func (m *Manager) Update() {
+	if m.context == nil {
+		return
+	}
+	for i := len(m.voices) - 1; i >= 0; i-- {
+		if m.voices[i].player.IsPlaying() {
+			continue
+		}
+		_ = m.voices[i].player.Close()
+		m.voices[i] = m.voices[len(m.voices)-1]
+		m.voices = m.voices[:len(m.voices)-1]
+	}
+
+	for t, tr := range m.tracks {
+		target := 0.0
+		if m.playing && t == m.music {
+			target = 1
+		}
+		if tr.level < target {
+			tr.level = math.Min(target, tr.level+1.0/crossfadeTicks)
+		} else {
+			tr.level = math.Max(target, tr.level-1.0/crossfadeTicks)
+		}
+		tr.player.SetVolume(tr.level * m.Volumes.Master * m.Volumes.Music)
+		switch {
+		case tr.level > 0 && !tr.player.IsPlaying():
+			tr.player.Play()
+		case tr.level == 0 && tr.player.IsPlaying():
+			tr.player.Pause()
+		}
+	}
+}
+
+// panReader streams a mono effect as stereo pcm with a fixed gain per ear.
+type panReader struct {
+	samples     []float32
+	left, right float32
+	pos         int
+}
+
+func (r *panReader) Read(b []byte) (int, error) {
+	if r.pos >= len(r.samples) {
+		return 0, io.EOF
+	}
+	n := 0
+	for ; n+bytesPerFrame <= len(b) && r.pos < len(r.samples); n += bytesPerFrame {
+		sample := r.samples[r.pos]
+		binary.LittleEndian.PutUint32(b[n:], math.Float32bits(sample*r.left))
+		binary.LittleEndian.PutUint32(b[n+4:], math.Float32bits(sample*r.right))
+		r.pos += 1
+	}
+
+	return n, nil
+}
+
+// stereo turns mono samples into the stereo pcm the audio context plays.
+func stereo(samples []float32, left, right float32) []byte {
+	pcm := make([]byte, len(samples)*bytesPerFrame)
+	r := &panReader{samples: samples, left: left, right: right}
+	_, _ = r.Read(pcm)
+
+	return pcm
+}
@@ -0,0 +1,299 @@
+package sound
+
+import (
+	"math"
+	"math/rand"
+)
+
+// the synthesizers use a fixed seed, so the game sounds the same every run
+const seed = 1849
+
+func seconds(d float64) int {
+	return int(d * SampleRate)
+}
+
+// envelope rises linearly during the attack and then decays exponentially.
+func envelope(t, attack, decay float64) float64 {
+	if t < attack {
+		return t / attack
+	}
+
+	return math.Exp(-(t - attack) / decay)
+}
+
+// lowpass is a one pole filter, a lower cutoff between 0 and 1 makes the sound duller.
+type lowpass struct {
+	cutoff float64
+	value  float64
+}
+
+func (l *lowpass) next(x float64) float64 {
+	l.value += l.cutoff * (x - l.value)
+	return l.value
+}
+
+// sweep is an oscillator phase that glides exponentially from one frequency to another.
+type sweep struct {
+	from, to float64
+	// glide is the time constant of the glide in seconds
+	glide float64
+	phase float64
+}
+
+func (s *sweep) next(t float64) float64 {
+	freq := s.to + (s.from-s.to)*math.Exp(-t/s.glide)
+	s.phase += 2 * math.Pi * freq / SampleRate
+	return s.phase
+}
+
+// normalize scales the loudest sample to peak.
+func normalize(samples []float32, peak float64) []float32 {
+	loudest := 0.0
+	for _, s := range samples {
+		loudest = math.Max(loudest, math.Abs(float64(s)))
+	}
+	if loudest == 0 {
+		return samples
+	}
+	for i := range samples {
+		samples[i] = float32(float64(samples[i]) * peak / loudest)
+	}
+
+	return samples
+}
+
+func gunshot() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float32, seconds(0.6))
+	crack := &lowpass{cutoff: 0.6}
+	tail := &lowpass{cutoff: 0.04}
+	thump := &sweep{from: 160, to: 50, glide: 0.04}
+	for i := range samples {
+		t := float64(i) / SampleRate
+		noise := r.Float64()*2 - 1
+		s := crack.next(noise) * envelope(t, 0.0005, 0.03)
+		s += tail.next(noise) * envelope(t, 0.002, 0.2) * 2.5
+		s += math.Sin(thump.next(t)) * envelope(t, 0.001, 0.07) * 0.9
+		samples[i] = float32(s)
+	}
+
+	return normalize(samples, 0.9)
+}
+
+// reload is the cylinder of a revolver clicking open, turning and snapping shut.
+func reload() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float32, seconds(0.55))
+	clicks := []struct{ at, volume, pitch float64 }{
+		{0, 0.7, 1800},
+		{0.14, 0.5, 2600},
+		{0.22, 0.5, 2600},
+		{0.38, 1, 1400},
+	}
+	for _, click := range clicks {
+		dull := &lowpass{cutoff: 0.3}
+		start := seconds(click.at)
+		for i := range seconds(0.05) {
+			if start+i >= len(samples) {
+				break
+			}
+			t := float64(i) / SampleRate
+			noise := r.Float64()*2 - 1
+			// the noise minus its lowpassed self keeps only the sharp part of the click
+			s := (noise - dull.next(noise)) * envelope(t, 0.0002, 0.003)
+			s += math.Sin(2*math.Pi*click.pitch*t) * envelope(t, 0.0005, 0.012) * 0.5
+			samples[start+i] += float32(s * click.volume)
+		}
+	}
+
+	return normalize(samples, 0.8)
+}
+
+func hit() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float32, seconds(0.2))
+	body := &sweep{from: 180, to: 60, glide: 0.05}
+	slap := &lowpass{cutoff: 0.2}
+	for i := range samples {
+		t := float64(i) / SampleRate
+		s := math.Sin(body.next(t)) * envelope(t, 0.001, 0.05)
+		s += slap.next(r.Float64()*2-1) * envelope(t, 0.0005, 0.015) * 1.5
+		samples[i] = float32(s)
+	}
+
+	return normalize(samples, 0.8)
+}
+
+// footstep is a soft scuff of a boot in the sand.
+func footstep() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float32, seconds(0.1))
+	sand := &lowpass{cutoff: 0.08}
+	for i := range samples {
+		t := float64(i) / SampleRate
+		samples[i] = float32(sand.next(r.Float64()*2-1) * envelope(t, 0.004, 0.02))
+	}
+
+	return normalize(samples, 0.7)
+}
+
+// death is a falling groan.
+func death() []float32 {
+	samples := make([]float32, seconds(0.9))
+	voice := &sweep{from: 260, to: 70, glide: 0.35}
+	throat := &lowpass{cutoff: 0.15}
+	for i := range samples {
+		t := float64(i) / SampleRate
+		phase := voice.next(t) + 0.3*math.Sin(2*math.Pi*6*t)
+		// a sawtooth, rough like a voice
+		saw := 2 * (phase/(2*math.Pi) - math.Floor(phase/(2*math.Pi)+0.5))
+		samples[i] = float32(throat.next(saw) * envelope(t, 0.02, 0.3))
+	}
+
+	return normalize(samples, 0.8)
+}
+
+func explosion() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float32, seconds(1.6))
+	rumble := &lowpass{cutoff: 0.02}
+	blast := &lowpass{cutoff: 0.3}
+	boom := &sweep{from: 90, to: 30, glide: 0.3}
+	for i := range samples {
+		t := float64(i) / SampleRate
+		noise := r.Float64()*2 - 1
+		s := rumble.next(noise) * envelope(t, 0.005, 0.45) * 6
+		s += blast.next(noise) * envelope(t, 0.001, 0.06)
+		s += math.Sin(boom.next(t)) * envelope(t, 0.003, 0.3)
+		samples[i] = float32(s)
+	}
+
+	return normalize(samples, 0.95)
+}
+
+// blip is a short square wave beep, one per typed letter.
+func blip() []float32 {
+	samples := make([]float32, seconds(0.04))
+	for i := range samples {
+		t := float64(i) / SampleRate
+		square := math.Copysign(1, math.Sin(2*math.Pi*880*t))
+		samples[i] = float32(square * envelope(t, 0.002, 0.012))
+	}
+
+	return normalize(samples, 0.5)
+}
+
+// note is the frequency of a midi note number, 69 is the A above middle C.
+func note(n int) float64 {
+	return 440 * math.Pow(2, float64(n-69)/12)
+}
+
+// pluck is a Karplus-Strong string, noise in a delay line that is averaged down to a tone.
+func pluck(r *rand.Rand, freq, duration, brightness float64) []float32 {
+	line := make([]float64, int(SampleRate/freq))
+	for i := range line {
+		line[i] = r.Float64()*2 - 1
+	}
+	samples := make([]float32, seconds(duration))
+	for i := range samples {
+		j := i % len(line)
+		next := line[(j+1)%len(line)]
+		samples[i] = float32(line[j])
+		line[j] = (line[j]*brightness + next*(1-brightness)) * 0.996
+	}
+
+	return samples
+}
+
+// mix adds a sound into a loop, wrapping around the end so the loop has no seam.
+func mix(loop []float32, sound []float32, at int, volume float64) {
+	for i, s := range sound {
+		loop[(at+i)%len(loop)] += float32(float64(s) * volume)
+	}
+}
+
+// exploreMusic is a lazy guitar picking through a minor progression.
+func exploreMusic() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	const beat = 60.0 / 84
+	chords := [][]int{
+		{45, 52, 57, 60}, // Am
+		{43, 50, 55, 59}, // G
+		{41, 48, 53, 57}, // F
+		{40, 47, 52, 56}, // E
+	}
+	picking := []int{0, 1, 2, 3, 2, 1, 2, 1}
+	loop := make([]float32, seconds(16*beat))
+	for bar, chord := range chords {
+		for step, pick := range picking {
+			at := seconds((float64(bar*4) + float64(step)/2) * beat)
+			// every other note is played a bit softer, like a picking hand would
+			volume := 0.5
+			if step%2 == 1 {
+				volume = 0.35
+			}
+			mix(loop, pluck(r, note(chord[pick]), 2, 0.5), at, volume)
+		}
+		// a low bass note on the first and third beat
+		for _, b := range []int{0, 2} {
+			mix(loop, pluck(r, note(chord[0]-12), 3, 0.6), seconds(float64(bar*4+b)*beat), 0.6)
+		}
+	}
+
+	return normalize(loop, 0.6)
+}
+
+// combatMusic drives on with drums and a galloping bass.
+func combatMusic() []float32 {
+	r := rand.New(rand.NewSource(seed))
+	const beat = 60.0 / 140
+	roots := []int{33, 33, 29, 28} // A, A, F, E
+	loop := make([]float32, seconds(16*beat))
+
+	kick := make([]float32, seconds(0.25))
+	kickTone := &sweep{from: 150, to: 45, glide: 0.03}
+	for i := range kick {
+		t := float64(i) / SampleRate
+		kick[i] = float32(math.Sin(kickTone.next(t)) * envelope(t, 0.001, 0.08))
+	}
+	snare := make([]float32, seconds(0.2))
+	snareBody := &lowpass{cutoff: 0.4}
+	for i := range snare {
+		t := float64(i) / SampleRate
+		snare[i] = float32(snareBody.next(r.Float64()*2-1) * envelope(t, 0.001, 0.05))
+	}
+	hat := make([]float32, seconds(0.05))
+	hatBody := &lowpass{cutoff: 0.5}
+	for i := range hat {
+		t := float64(i) / SampleRate
+		noise := r.Float64()*2 - 1
+		hat[i] = float32((noise - hatBody.next(noise)) * envelope(t, 0.0005, 0.01))
+	}
+
+	for bar, root := range roots {
+		for b := range 4 {
+			at := float64(bar*4+b) * beat
+			mix(loop, kick, seconds(at), 1)
+			if b%2 == 1 {
+				mix(loop, snare, seconds(at), 0.6)
+			}
+			for _, offset := range []float64{0, 0.5} {
+				mix(loop, hat, seconds(at+offset*beat), 0.25)
+			}
+			// the gallop: a long note and two short ones on every beat
+			for i, offset := range []float64{0, 0.5, 0.75} {
+				pitch := root
+				if i > 0 && b == 3 {
+					pitch = root + 7
+				}
+				mix(loop, pluck(r, note(pitch), 0.4, 0.7), seconds(at+offset*beat), 0.7)
+			}
+		}
+		// a stab of the chord at the start of every bar
+		for _, interval := range []int{12, 19, 24} {
+			mix(loop, pluck(r, note(root+interval), 0.8, 0.4), seconds(float64(bar*4)*beat), 0.3)
+		}
+	}
+
+	return normalize(loop, 0.6)
+}
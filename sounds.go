@@ -0,0 +1,58 @@
+package farwest
+
+import (
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/sound"
+	"github.com/bramca/Far-West/utils"
+)
+
+const (
+	// an enemy that is after someone this close to the player starts the combat music
+	combatDistance = 700
+	// combatLinger keeps the combat music going for a while after the last fight
+	combatLinger = 300
+)
+
+// inCombat is true when an enemy near the player is hunting someone.
+func (g *Game) inCombat() bool {
+	for _, enemy := range g.enemies {
+		if enemy.Dead || enemy.CurrentAction.Type != actors.MoveAndShoot || enemy.CurrentAction.Duration <= 0 {
+			continue
+		}
+		if utils.DistanceBetweenPoints(g.player.X, g.player.Y, enemy.X, enemy.Y) <= combatDistance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateSounds plays the sounds that are not tied to a single event and picks the music.
+func (g *Game) updateSounds() {
+	g.sounds.SetListener(g.camX+float64(g.viewport.Width)/2, g.camY+float64(g.viewport.Height)/2)
+
+	for _, cp := range g.players {
+		reloading := cp.ReloadTimer > 0
+		if reloading && !cp.reloading {
+			g.sounds.PlayAt(sound.Reload, cp.X, cp.Y)
+		}
+		cp.reloading = reloading
+	}
+
+	if g.inCombat() {
+		g.combatTimer = combatLinger
+	} else if g.combatTimer > 0 {
+		g.combatTimer -= 1
+	}
+	if g.combatTimer > 0 {
+		g.sounds.PlayMusic(sound.CombatMusic)
+	} else {
+		g.sounds.PlayMusic(sound.ExploreMusic)
+	}
+}
+
+// die plays the death sound of an actor where it fell.
+func (g *Game) die(p *actors.Player) {
+	x, y := g.feet(p)
+	g.sounds.PlayAt(sound.Death, x, y)
+}
@@ -9,3 +9,18 @@ func AngleBetweenPoints(x1, y1, x2, y2 float64) float64 {
 func DistanceBetweenPoints(x1, y1, x2, y2 float64) float64 {
 	return math.Sqrt((x2-x1)*(x2-x1) + (y2-y1)*(y2-y1))
 }
+
+// DistanceToSegment returns the distance from point p to the segment between a and b.
+func DistanceToSegment(px, py, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return DistanceBetweenPoints(px, py, ax, ay)
+	}
+	t := math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSquared))
+	return DistanceBetweenPoints(px, py, ax+t*dx, ay+t*dy)
+}
+
+type Point struct {
+	X, Y float64
+}
@@ -0,0 +1,132 @@
+// Package viewport fits the screen of the game into a window of any size.
+package viewport
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Mode is how the screen of the game is fitted into the window.
+type Mode int
+
+const (
+	// Expand shows more of the world when the window is larger than the virtual resolution,
+	// nothing is cut off and there are no black bars
+	Expand Mode = iota
+	// Fit scales the virtual resolution to the window and fills the rest with black bars
+	Fit
+	// Pixel scales the virtual resolution by whole numbers only, without smoothing
+	Pixel
+)
+
+var modeNames = map[Mode]string{
+	Expand: "expand",
+	Fit:    "fit",
+	Pixel:  "pixel",
+}
+
+func (m Mode) String() string {
+	return modeNames[m]
+}
+
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Mode) UnmarshalText(data []byte) error {
+	for mode, name := range modeNames {
+		if name == string(data) {
+			*m = mode
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown scaling mode %q", data)
+}
+
+// Next cycles through the modes in the direction.
+func (m Mode) Next(dir int) Mode {
+	return Mode((int(m) + dir + len(modeNames)) % len(modeNames))
+}
+
+// Viewport keeps the size of the screen of the game, it changes with the window in Expand mode.
+type Viewport struct {
+	// VirtualWidth and VirtualHeight is the resolution the game is made for
+	VirtualWidth  int
+	VirtualHeight int
+	Mode          Mode
+	// Width and Height is the size of the screen of the game, set by Layout
+	Width  int
+	Height int
+
+	// the transform ebiten asked for and the one used, from the last final screen,
+	// to map the cursor from the one to the other
+	given ebiten.GeoM
+	used  ebiten.GeoM
+}
+
+func New(width, height int) *Viewport {
+	return &Viewport{
+		VirtualWidth:  width,
+		VirtualHeight: height,
+		Width:         width,
+		Height:        height,
+	}
+}
+
+// Layout is called with the size of the window and returns the size of the screen of the game.
+func (v *Viewport) Layout(outsideWidth, outsideHeight int) (int, int) {
+	v.Width, v.Height = v.VirtualWidth, v.VirtualHeight
+	if v.Mode == Expand && outsideWidth > 0 && outsideHeight > 0 {
+		// the virtual resolution always fits, the window decides how much more is shown
+		scale := min(float64(outsideWidth)/float64(v.VirtualWidth), float64(outsideHeight)/float64(v.VirtualHeight))
+		v.Width = int(math.Round(float64(outsideWidth) / scale))
+		v.Height = int(math.Round(float64(outsideHeight) / scale))
+	}
+
+	return v.Width, v.Height
+}
+
+// DrawFinalScreen draws the screen of the game into the window, with whole number scaling in Pixel mode.
+func (v *Viewport) DrawFinalScreen(screen ebiten.FinalScreen, offscreen *ebiten.Image, geoM ebiten.GeoM) {
+	v.given = geoM
+	v.used = geoM
+	if v.Mode != Pixel {
+		ebiten.DefaultDrawFinalScreen(screen, offscreen, geoM)
+		return
+	}
+
+	bounds := screen.Bounds()
+	width, height := offscreen.Bounds().Dx(), offscreen.Bounds().Dy()
+	scale := min(float64(bounds.Dx())/float64(width), float64(bounds.Dy())/float64(height))
+	// a window smaller than the virtual resolution can only be scaled down smoothly
+	if scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	v.used = ebiten.GeoM{}
+	v.used.Scale(scale, scale)
+	v.used.Translate(math.Floor((float64(bounds.Dx())-float64(width)*scale)/2), math.Floor((float64(bounds.Dy())-float64(height)*scale)/2))
+
+	screen.Fill(color.Black)
+	options := &ebiten.DrawImageOptions{}
+	options.GeoM = v.used
+	options.Filter = ebiten.FilterNearest
+	screen.DrawImage(offscreen, options)
+}
+
+// Cursor is the position of the mouse on the screen of the game. ebiten places the cursor
+// as if the screen was drawn the default way, which is not true in Pixel mode.
+func (v *Viewport) Cursor() (float64, float64) {
+	x, y := ebiten.CursorPosition()
+	if !v.used.IsInvertible() {
+		return float64(x), float64(y)
+	}
+	screenX, screenY := v.given.Apply(float64(x), float64(y))
+	inverse := v.used
+	inverse.Invert()
+
+	return inverse.Apply(screenX, screenY)
+}
@@ -0,0 +1,77 @@
+package world
+
+import (
+	"image/color"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	boardWood   = color.RGBA{110, 70, 35, 255}
+	boardPost   = color.RGBA{80, 50, 25, 255}
+	posterPaper = color.RGBA{225, 205, 160, 255}
+	posterInk   = color.RGBA{60, 40, 25, 255}
+)
+
+// BountyBoard is the wooden board in town the wanted posters hang on.
+type BountyBoard struct {
+	X, Y           float64
+	W, H           float64
+	Hitbox         *actors.HitBox
+	InteractRadius float64
+	TextFont       *text.GoXFace
+	// Posters is the number of posters on the board
+	Posters int
+}
+
+func NewBountyBoard(x, y float64, font *text.GoXFace) *BountyBoard {
+	w, h := 64.0, 40.0
+	return &BountyBoard{
+		X:              x,
+		Y:              y,
+		W:              w,
+		H:              h,
+		InteractRadius: 80,
+		TextFont:       font,
+		Hitbox: &actors.HitBox{
+			X: float32(x),
+			Y: float32(y),
+			W: float32(w),
+			H: float32(h),
+		},
+	}
+}
+
+func (b *BountyBoard) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	x, y := float32(b.X-camX), float32(b.Y-camY)
+	w, h := float32(b.W), float32(b.H)
+	vector.FillRect(screen, x+6, y+h-8, 5, 20, boardPost, false)
+	vector.FillRect(screen, x+w-11, y+h-8, 5, 20, boardPost, false)
+	vector.FillRect(screen, x, y, w, h, boardWood, false)
+
+	posterW, posterH := float32(14), float32(18)
+	for i := range min(b.Posters, 3) {
+		px := x + 5 + float32(i)*(posterW+6)
+		py := y + 6 + float32(i%2)*4
+		vector.FillRect(screen, px, py, posterW, posterH, posterPaper, false)
+		vector.FillRect(screen, px+3, py+3, posterW-6, 2, posterInk, false)
+		vector.FillRect(screen, px+4, py+7, posterW-8, 6, posterInk, false)
+	}
+
+	labelOptions := &text.DrawOptions{}
+	labelOptions.GeoM.Translate(b.X-camX, b.Y-camY-12)
+	text.Draw(screen, "Bounties", b.TextFont, labelOptions)
+}
+
+func (b *BountyBoard) DrawHitbox(screen *ebiten.Image, camX float64, camY float64) {
+	b.Hitbox.Draw(screen, camX, camY)
+}
+
+// InRange reports whether the player stands close enough to read the board.
+func (b *BountyBoard) InRange(p *actors.Player) bool {
+	return utils.DistanceBetweenPoints(p.X, p.Y, b.X+b.W/2, b.Y+b.H/2) <= b.InteractRadius
+}
@@ -6,19 +6,63 @@ import (
 )
 
 type Cactus struct {
-	X, Y        float64
-	W, H        float64
-	Sprite      *ebiten.Image
-	DrawOptions *ebiten.DrawImageOptions
-	Scale       float64
-	Hitbox      *actors.HitBox
+	X, Y            float64
+	W, H            float64
+	Sprite          *ebiten.Image
+	DamagedSprite   *ebiten.Image
+	DestroyedSprite *ebiten.Image
+	DrawOptions     *ebiten.DrawImageOptions
+	Scale           float64
+	Hitbox          *actors.HitBox
+	Health          int
+	MaxHealth       int
+	Destroyed       bool
+	// Shake is the number of frames the cactus still wobbles after a hit
+	Shake int
 }
 
 func (c *Cactus) Draw(screen *ebiten.Image, camX float64, camY float64) {
 	c.DrawOptions.GeoM.Reset()
 	c.DrawOptions.GeoM.Scale(c.Scale, c.Scale)
-	c.DrawOptions.GeoM.Translate(float64(c.X-camX), float64(c.Y-camY))
-	screen.DrawImage(c.Sprite, c.DrawOptions)
+	offset := 0.0
+	if c.Shake > 0 {
+		c.Shake -= 1
+		offset = float64(c.Shake%2*2 - 1)
+	}
+	c.DrawOptions.GeoM.Translate(float64(c.X-camX)+offset, float64(c.Y-camY))
+	screen.DrawImage(c.CurrentSprite(), c.DrawOptions)
+}
+
+// CurrentSprite shows the damage stage of the cactus.
+func (c *Cactus) CurrentSprite() *ebiten.Image {
+	switch {
+	case c.Destroyed:
+		return c.DestroyedSprite
+	case c.Health <= c.MaxHealth/2:
+		return c.DamagedSprite
+	}
+
+	return c.Sprite
+}
+
+// Damage returns true when the hit destroyed the cactus.
+func (c *Cactus) Damage(amount int) bool {
+	if c.Destroyed {
+		return false
+	}
+	c.Health -= amount
+	c.Shake = 8
+	if c.Health <= 0 {
+		c.Health = 0
+		c.Destroyed = true
+		return true
+	}
+
+	return false
+}
+
+func (c *Cactus) Center() (float64, float64) {
+	return float64(c.Hitbox.X + c.Hitbox.W/2), float64(c.Hitbox.Y + c.Hitbox.H/2)
 }
 
 func (c *Cactus) DrawHitbox(screen *ebiten.Image, camX float64, camY float64) {
@@ -0,0 +1,76 @@
+package world
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const debrisGravity = 0.25
+
+// Debris is a chunk of a broken object that flies off and settles on the ground.
+// Z is the height above the ground.
+type Debris struct {
+	X, Y        float64
+	Z           float64
+	VX, VY, VZ  float64
+	Sprite      *ebiten.Image
+	Scale       float64
+	Duration    int
+	DrawOptions *ebiten.DrawImageOptions
+}
+
+// BreakApart cuts pieces out of the sprite of a destroyed cactus.
+func BreakApart(c *Cactus, amount int, duration int) []*Debris {
+	debris := []*Debris{}
+	bounds := c.Sprite.Bounds()
+	pieceSize := 3
+	centerX, centerY := c.Center()
+	for range amount {
+		// pieces come from the middle of the frame where the cactus is
+		px := bounds.Min.X + bounds.Dx()/2 - pieceSize + rand.Intn(2*pieceSize)
+		py := bounds.Min.Y + bounds.Dy()/3 + rand.Intn(bounds.Dy()/2)
+		piece := c.Sprite.SubImage(image.Rect(px, py, px+pieceSize, py+pieceSize)).(*ebiten.Image)
+		angle := rand.Float64() * 2 * math.Pi
+		speed := 0.5 + rand.Float64()*1.5
+		debris = append(debris, &Debris{
+			X:           centerX,
+			Y:           centerY,
+			Z:           10 + rand.Float64()*20,
+			VX:          speed * math.Cos(angle),
+			VY:          speed * math.Sin(angle),
+			VZ:          1 + rand.Float64()*2,
+			Sprite:      piece,
+			Scale:       c.Scale,
+			Duration:    duration,
+			DrawOptions: &ebiten.DrawImageOptions{},
+		})
+	}
+
+	return debris
+}
+
+func (d *Debris) Update() {
+	d.Duration -= 1
+	if d.Z <= 0 && d.VZ <= 0 {
+		d.Z = 0
+		return
+	}
+	d.X += d.VX
+	d.Y += d.VY
+	d.Z += d.VZ
+	d.VZ -= debrisGravity
+}
+
+func (d *Debris) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	d.DrawOptions.GeoM.Reset()
+	d.DrawOptions.GeoM.Scale(d.Scale, d.Scale)
+	d.DrawOptions.GeoM.Translate(d.X-camX, d.Y-d.Z-camY)
+	d.DrawOptions.ColorScale.Reset()
+	if d.Duration < 60 {
+		d.DrawOptions.ColorScale.ScaleAlpha(float32(d.Duration) / 60)
+	}
+	screen.DrawImage(d.Sprite, d.DrawOptions)
+}
@@ -0,0 +1,66 @@
+package world
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const dynamiteGravity = 0.3
+
+// Dynamite is a lit stick flying through the air, Z is its height above the ground.
+type Dynamite struct {
+	X, Y       float64
+	Z          float64
+	VX, VY, VZ float64
+	Fuse       int
+	Radius     float64
+	Damage     int
+}
+
+// Update returns true when the fuse burned down.
+func (d *Dynamite) Update() bool {
+	d.Fuse -= 1
+	if d.Z > 0 || d.VZ > 0 {
+		d.X += d.VX
+		d.Y += d.VY
+		d.Z += d.VZ
+		d.VZ -= dynamiteGravity
+	}
+	if d.Z < 0 {
+		d.Z = 0
+		d.VZ = 0
+	}
+
+	return d.Fuse <= 0
+}
+
+func (d *Dynamite) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	x := float32(d.X - camX)
+	y := float32(d.Y - d.Z - camY)
+	vector.FillRect(screen, x, y, 10, 4, color.RGBA{190, 30, 30, 255}, false)
+	if d.Fuse%6 < 3 {
+		vector.FillRect(screen, x+10, y-2, 3, 3, color.RGBA{255, 220, 80, 255}, false)
+	}
+}
+
+type Explosion struct {
+	X, Y        float64
+	Radius      float64
+	Duration    int
+	MaxDuration int
+}
+
+func (e *Explosion) Update() {
+	e.Duration -= 1
+}
+
+func (e *Explosion) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	progress := 1 - float32(e.Duration)/float32(e.MaxDuration)
+	radius := float32(e.Radius) * (0.4 + 0.6*progress)
+	alpha := uint8(220 * (1 - progress))
+	x, y := float32(e.X-camX), float32(e.Y-camY)
+	vector.FillCircle(screen, x, y, radius, color.NRGBA{255, 150, 40, alpha}, true)
+	vector.FillCircle(screen, x, y, radius/2, color.NRGBA{255, 240, 180, alpha}, true)
+}
@@ -0,0 +1,151 @@
+package world
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/utils"
+)
+
+type cell struct {
+	X, Y int
+}
+
+// NavGrid marks the cells of the world that can not be walked through.
+// A cell can be blocked by several obstacles, so it keeps a count.
+type NavGrid struct {
+	CellSize float64
+	blocked  map[cell]int
+}
+
+func NewNavGrid(cellSize float64) *NavGrid {
+	return &NavGrid{
+		CellSize: cellSize,
+		blocked:  map[cell]int{},
+	}
+}
+
+func (n *NavGrid) cellAt(x, y float64) cell {
+	return cell{int(math.Floor(x / n.CellSize)), int(math.Floor(y / n.CellSize))}
+}
+
+func (n *NavGrid) cellsOf(h *actors.HitBox) []cell {
+	topLeft := n.cellAt(float64(h.X), float64(h.Y))
+	bottomRight := n.cellAt(float64(h.X+h.W), float64(h.Y+h.H))
+	cells := []cell{}
+	for x := topLeft.X; x <= bottomRight.X; x++ {
+		for y := topLeft.Y; y <= bottomRight.Y; y++ {
+			cells = append(cells, cell{x, y})
+		}
+	}
+
+	return cells
+}
+
+func (n *NavGrid) Block(h *actors.HitBox) {
+	for _, c := range n.cellsOf(h) {
+		n.blocked[c] += 1
+	}
+}
+
+func (n *NavGrid) Unblock(h *actors.HitBox) {
+	for _, c := range n.cellsOf(h) {
+		n.blocked[c] -= 1
+		if n.blocked[c] <= 0 {
+			delete(n.blocked, c)
+		}
+	}
+}
+
+func (n *NavGrid) Blocked(x, y float64) bool {
+	return n.blocked[n.cellAt(x, y)] > 0
+}
+
+func (n *NavGrid) center(c cell) utils.Point {
+	return utils.Point{
+		X: (float64(c.X) + 0.5) * n.CellSize,
+		Y: (float64(c.Y) + 0.5) * n.CellSize,
+	}
+}
+
+type pathNode struct {
+	cell  cell
+	cost  float64
+	score float64
+	index int
+}
+
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].score < q[j].score }
+func (q pathQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *pathQueue) Push(x any) {
+	node := x.(*pathNode)
+	node.index = len(*q)
+	*q = append(*q, node)
+}
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// FindPath runs A* over the grid and returns the centers of the cells to walk through,
+// the start cell excluded. It gives up and returns nil after visiting maxNodes cells.
+func (n *NavGrid) FindPath(fromX, fromY, toX, toY float64, maxNodes int) []utils.Point {
+	start := n.cellAt(fromX, fromY)
+	goal := n.cellAt(toX, toY)
+	if start == goal {
+		return nil
+	}
+
+	estimate := func(c cell) float64 {
+		return math.Abs(float64(c.X-goal.X)) + math.Abs(float64(c.Y-goal.Y))
+	}
+	cameFrom := map[cell]cell{}
+	costs := map[cell]float64{start: 0}
+	queue := &pathQueue{}
+	heap.Push(queue, &pathNode{cell: start, score: estimate(start)})
+	neighbours := []cell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+	visited := 0
+	for queue.Len() > 0 && visited < maxNodes {
+		current := heap.Pop(queue).(*pathNode)
+		visited += 1
+		if current.cell == goal {
+			path := []utils.Point{}
+			for c := goal; c != start; c = cameFrom[c] {
+				path = append([]utils.Point{n.center(c)}, path...)
+			}
+			return path
+		}
+		if current.cost > costs[current.cell] {
+			continue
+		}
+		for _, d := range neighbours {
+			next := cell{current.cell.X + d.X, current.cell.Y + d.Y}
+			// the goal may be blocked itself, for example a target next to a cactus
+			if n.blocked[next] > 0 && next != goal {
+				continue
+			}
+			cost := current.cost + 1
+			if known, ok := costs[next]; ok && known <= cost {
+				continue
+			}
+			costs[next] = cost
+			cameFrom[next] = current.cell
+			heap.Push(queue, &pathNode{cell: next, cost: cost, score: cost + estimate(next)})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package world
+
+import (
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// NPC is a townsperson standing in one place, with their name over their head.
+type NPC struct {
+	Name           string
+	X, Y           float64
+	W, H           float64
+	Sprite         *ebiten.Image
+	DrawOptions    *ebiten.DrawImageOptions
+	Scale          float64
+	InteractRadius float64
+	TextFont       *text.GoXFace
+	// Dialogue is the id of the dialogue tree, empty when there is nothing to talk about
+	Dialogue string
+}
+
+func NewNPC(name string, x, y float64, sprite *ebiten.Image, font *text.GoXFace) *NPC {
+	return &NPC{
+		Name:           name,
+		X:              x,
+		Y:              y,
+		W:              float64(sprite.Bounds().Dx()),
+		H:              float64(sprite.Bounds().Dy()),
+		Sprite:         sprite,
+		DrawOptions:    &ebiten.DrawImageOptions{},
+		Scale:          2,
+		InteractRadius: 80,
+		TextFont:       font,
+	}
+}
+
+func (n *NPC) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	n.DrawOptions.GeoM.Reset()
+	n.DrawOptions.GeoM.Scale(n.Scale, n.Scale)
+	n.DrawOptions.GeoM.Translate(n.X-camX, n.Y-camY)
+	screen.DrawImage(n.Sprite, n.DrawOptions)
+
+	labelOptions := &text.DrawOptions{}
+	labelOptions.GeoM.Translate(n.X-camX, n.Y-camY)
+	text.Draw(screen, n.Name, n.TextFont, labelOptions)
+}
+
+// InRange reports whether the player stands close enough to talk to the npc.
+func (n *NPC) InRange(p *actors.Player) bool {
+	centerX := n.X + n.W*n.Scale/2
+	centerY := n.Y + n.H*n.Scale/2
+	return utils.DistanceBetweenPoints(p.X, p.Y, centerX, centerY) <= n.InteractRadius
+}
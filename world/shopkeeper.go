@@ -0,0 +1,45 @@
+package world
+
+import (
+	"github.com/bramca/Far-West/actors"
+	"github.com/bramca/Far-West/economy"
+	"github.com/bramca/Far-West/utils"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+type Shopkeeper struct {
+	X, Y           float64
+	W, H           float64
+	Sprite         *ebiten.Image
+	DrawOptions    *ebiten.DrawImageOptions
+	Scale          float64
+	Hitbox         *actors.HitBox
+	Shop           *economy.Shop
+	InteractRadius float64
+	TextFont       *text.GoXFace
+	// Dialogue is the id of the dialogue tree, empty when there is nothing to talk about
+	Dialogue string
+}
+
+func (s *Shopkeeper) Draw(screen *ebiten.Image, camX float64, camY float64) {
+	s.DrawOptions.GeoM.Reset()
+	s.DrawOptions.GeoM.Scale(s.Scale, s.Scale)
+	s.DrawOptions.GeoM.Translate(float64(s.X-camX), float64(s.Y-camY))
+	screen.DrawImage(s.Sprite, s.DrawOptions)
+
+	labelOptions := &text.DrawOptions{}
+	labelOptions.GeoM.Translate(s.X-camX, s.Y-camY)
+	text.Draw(screen, s.Shop.Name, s.TextFont, labelOptions)
+}
+
+func (s *Shopkeeper) DrawHitbox(screen *ebiten.Image, camX float64, camY float64) {
+	s.Hitbox.Draw(screen, camX, camY)
+}
+
+// InRange reports whether the player stands close enough to talk to the shopkeeper.
+func (s *Shopkeeper) InRange(p *actors.Player) bool {
+	centerX := s.X + s.W*s.Scale/2
+	centerY := s.Y + s.H*s.Scale/2
+	return utils.DistanceBetweenPoints(p.X, p.Y, centerX, centerY) <= s.InteractRadius
+}